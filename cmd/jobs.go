@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"merch-shop/internal/usecase"
+	"time"
+)
+
+// runPeriodically вызывает job с заданным интервалом, пока не отменён контекст
+func runPeriodically(ctx context.Context, interval time.Duration, job func(ctx context.Context)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			job(ctx)
+		}
+	}
+}
+
+func expirePendingTransfers(ctx context.Context, useCase *usecase.UseCase) {
+	expired, err := useCase.ExpirePendingTransfers(ctx)
+	if err != nil {
+		slog.Error("useCase.ExpirePendingTransfers", "error", err)
+	}
+
+	if expired > 0 {
+		slog.Info("Pending transfers expired", "count", expired)
+	}
+}
+
+func runScheduledTransfers(ctx context.Context, useCase *usecase.UseCase) {
+	executed, failed, err := useCase.RunScheduledTransfers(ctx)
+	if err != nil {
+		slog.Error("useCase.RunScheduledTransfers", "error", err)
+	}
+
+	if executed > 0 || failed > 0 {
+		slog.Info("Scheduled transfers run", "executed", executed, "failed", failed)
+	}
+}
+
+func runEconomyPolicy(ctx context.Context, useCase *usecase.UseCase) {
+	report, err := useCase.RunEconomyPolicy(ctx)
+	if err != nil {
+		slog.Error("useCase.RunEconomyPolicy", "error", err)
+	}
+
+	if report.AllowancesCredited > 0 || report.UsersExpired > 0 {
+		slog.Info("Economy policy applied",
+			"allowances", report.AllowancesCredited,
+			"allowanceTotal", report.AllowanceTotal,
+			"usersExpired", report.UsersExpired,
+			"coinsExpired", report.CoinsExpired,
+		)
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"merch-shop/internal/api"
 	"merch-shop/internal/auth"
 	"merch-shop/internal/config"
+	"merch-shop/internal/metrics"
+	"merch-shop/internal/password"
 	"merch-shop/internal/repository"
 	"merch-shop/internal/repository/db"
+	"merch-shop/internal/repository/memory"
 	"merch-shop/internal/usecase"
 	"net/http"
 	"os"
@@ -17,8 +20,6 @@ import (
 )
 
 func main() {
-	slog.Info("Start server")
-
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
@@ -40,6 +41,17 @@ func main() {
 		return
 	}
 
+	hasher, err := password.New(cfg.PasswordHasher, password.Params{
+		Argon2Memory:      cfg.Argon2Memory,
+		Argon2Iterations:  cfg.Argon2Iterations,
+		Argon2Parallelism: cfg.Argon2Parallelism,
+		BcryptCost:        cfg.BcryptCost,
+	})
+	if err != nil {
+		slog.Error("password.New", "error", err)
+		return
+	}
+
 	db, err := db.Connect(cfg.DatabaseURL)
 	if err != nil {
 		slog.Error("db.Connect", "error", err)
@@ -48,12 +60,75 @@ func main() {
 	defer db.Close()
 
 	repo := repository.New(db)
-	auth := authorization.New(privateKey, publicKey)
+	auth := authorization.New(privateKey, publicKey, cfg.AccessTokenTTL)
+
+	var attempts usecase.LoginAttempts
+	switch cfg.LoginAttemptStore {
+	case "memory":
+		attempts = memory.NewLoginAttempts()
+	case "postgres":
+		attempts = repository.NewLoginAttempts(db)
+	default:
+		slog.Error("Unknown login attempt store", "store", cfg.LoginAttemptStore)
+		return
+	}
+
+	useCase := usecase.New(auth, hasher, repo, attempts, usecase.Config{
+		RefreshTokenTTL: cfg.RefreshTokenTTL,
+		SessionCacheTTL: cfg.SessionCacheTTL,
 
-	useCase := usecase.New(auth, repo)
+		LoginMaxFailures:   cfg.LoginMaxFailures,
+		LoginFailureWindow: cfg.LoginFailureWindow,
+		LoginLockoutBase:   cfg.LoginLockoutBase,
+		LoginLockoutMax:    cfg.LoginLockoutMax,
+
+		AutoRegistration: cfg.AutoRegistration,
+		InviteRequired:   cfg.InviteRequired,
+		InviteTTL:        cfg.InviteTTL,
+		StartingBalance:  cfg.StartingBalance,
+
+		ReturnWindow: cfg.ReturnWindow,
+
+		ReconcileAnomalies: cfg.ReconcileAnomalies,
+
+		TransferLimits: usecase.TransferLimits{
+			MaxAmount:      cfg.TransferMaxAmount,
+			MaxDailyVolume: cfg.TransferMaxDailyVolume,
+			MaxPerHour:     cfg.TransferMaxPerHour,
+			MinAccountAge:  cfg.TransferMinAccountAge,
+		},
+
+		ApprovalThreshold: cfg.ApprovalThreshold,
+		ApprovalTTL:       cfg.ApprovalTTL,
+
+		CoinRequestTTL: cfg.CoinRequestTTL,
+
+		ScheduleLease:        cfg.ScheduleLease,
+		ScheduleRetryBackoff: cfg.ScheduleRetryBackoff,
+		ScheduleMaxAttempts:  cfg.ScheduleMaxAttempts,
+
+		Economy: usecase.EconomyPolicy{
+			AllowanceAmount:       cfg.AllowanceAmount,
+			AllowanceActiveWithin: cfg.AllowanceActiveWithin,
+			ExpiryMonths:          cfg.CoinExpiryMonths,
+		},
+	})
+
+	if len(os.Args) > 1 {
+		if os.Args[1] != "reconcile" {
+			slog.Error("Unknown command", "command", os.Args[1])
+			return
+		}
+
+		code := runReconcileCommand(ctx, useCase)
+		db.Close()
+		os.Exit(code)
+	}
+
+	slog.Info("Start server")
 
 	handler := api.NewHTTPHandler(useCase)
-	router, err := api.NewRouter(handler, publicKey)
+	router, err := api.NewRouter(handler, publicKey, useCase, repo, cfg.IdempotencyTTL)
 	if err != nil {
 		slog.Error("api.NewRouter", "error", err)
 		return
@@ -61,9 +136,44 @@ func main() {
 
 	srv := api.NewServer(cfg.ServerPort, router)
 
-	quit := make(chan os.Signal)
+	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
+	if cfg.ReconcileInterval > 0 {
+		go runPeriodically(ctx, cfg.ReconcileInterval, func(ctx context.Context) {
+			reconcile(ctx, useCase)
+		})
+	}
+
+	if cfg.ApprovalThreshold > 0 && cfg.ApprovalExpiryInterval > 0 {
+		go runPeriodically(ctx, cfg.ApprovalExpiryInterval, func(ctx context.Context) {
+			expirePendingTransfers(ctx, useCase)
+		})
+	}
+
+	if cfg.SchedulerInterval > 0 {
+		go runPeriodically(ctx, cfg.SchedulerInterval, func(ctx context.Context) {
+			runScheduledTransfers(ctx, useCase)
+		})
+	}
+
+	if cfg.EconomyInterval > 0 && (cfg.AllowanceAmount > 0 || cfg.CoinExpiryMonths > 0) {
+		go runPeriodically(ctx, cfg.EconomyInterval, func(ctx context.Context) {
+			runEconomyPolicy(ctx, useCase)
+		})
+	}
+
+	if cfg.MetricsPort != "" {
+		metricsSrv := api.NewServer(cfg.MetricsPort, metrics.Handler())
+		defer metricsSrv.Close()
+
+		go func() {
+			if err := metricsSrv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("Metrics server", "error", err)
+			}
+		}()
+	}
+
 	//Запускаем сервер
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"merch-shop/internal/metrics"
+	"merch-shop/internal/usecase"
+	"os"
+)
+
+// runReconcileCommand выполняет одну сверку и печатает отчёт в stdout.
+// Код возврата 1 — сверка не выполнена или найдены расхождения.
+func runReconcileCommand(ctx context.Context, useCase *usecase.UseCase) int {
+	report, err := useCase.Reconcile(ctx)
+	if err != nil {
+		slog.Error("useCase.Reconcile", "error", err)
+		return 1
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err = encoder.Encode(report); err != nil {
+		slog.Error("Encode reconciliation report", "error", err)
+		return 1
+	}
+
+	if !report.Consistent() {
+		return 1
+	}
+
+	return 0
+}
+
+func reconcile(ctx context.Context, useCase *usecase.UseCase) {
+	report, err := useCase.Reconcile(ctx)
+	if err != nil {
+		metrics.ObserveReconciliationFailure()
+		slog.Error("useCase.Reconcile", "error", err)
+		return
+	}
+
+	metrics.ObserveReconciliation(report)
+
+	for _, m := range report.Mismatches {
+		slog.Warn("Balance mismatch",
+			"user_id", m.UserID,
+			"username", m.Username,
+			"cached", m.Cached,
+			"ledger", m.Ledger,
+			"history", m.History,
+		)
+	}
+
+	for _, entryID := range report.UnbalancedEntries {
+		slog.Warn("Unbalanced ledger entry", "entry_id", entryID)
+	}
+
+	slog.Info("Reconciliation finished",
+		"wallets", report.Wallets,
+		"mismatches", len(report.Mismatches),
+		"unbalanced_entries", len(report.UnbalancedEntries),
+		"anomalies_opened", report.AnomaliesOpened,
+	)
+}
@@ -3,7 +3,10 @@ CREATE TABLE IF NOT EXISTS public.users (
                             username VARCHAR(100) UNIQUE NOT NULL,
                             password TEXT NOT NULL,
                             created_at TIMESTAMP DEFAULT NOW(),
-                            coins INT NOT NULL DEFAULT 1000 CHECK (coins >= 0)
+                            coins INT NOT NULL DEFAULT 0 CHECK (coins >= 0),
+                            role TEXT NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'admin', 'auditor')),
+                            token_generation BIGINT NOT NULL DEFAULT 0,
+                            manager_id BIGINT REFERENCES public.users(id) ON DELETE SET NULL
 );
 
 CREATE TABLE IF NOT EXISTS public.transactions (
@@ -11,15 +14,41 @@ CREATE TABLE IF NOT EXISTS public.transactions (
                             from_user_id BIGINT REFERENCES public.users(id) ON DELETE SET NULL,
                             to_user_id BIGINT REFERENCES public.users(id) ON DELETE SET NULL,
                             quantity INT NOT NULL CHECK (quantity > 0),
+                            kind TEXT NOT NULL DEFAULT 'transfer' CHECK (kind IN ('transfer', 'refund', 'grant', 'allowance', 'expiry')),
+                            memo VARCHAR(200),
                             created_at TIMESTAMP DEFAULT NOW()
 );
 
+CREATE INDEX IF NOT EXISTS transactions_from_user_id_idx ON public.transactions (from_user_id, id DESC);
+CREATE INDEX IF NOT EXISTS transactions_to_user_id_idx ON public.transactions (to_user_id, id DESC);
+
 CREATE TABLE IF NOT EXISTS public.merch (
                             id BIGSERIAL PRIMARY KEY,
                             name VARCHAR(100) UNIQUE NOT NULL,
-                            price INT NOT NULL CHECK (price > 0)
+                            category VARCHAR(50) NOT NULL DEFAULT 'other',
+                            stock INT CHECK (stock >= 0),
+                            return_window INTERVAL,
+                            retired_at TIMESTAMP,
+                            created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS public.merch_prices (
+                            id BIGSERIAL PRIMARY KEY,
+                            merch_id BIGINT NOT NULL REFERENCES public.merch(id) ON DELETE CASCADE,
+                            price INT NOT NULL CHECK (price > 0),
+                            effective_from TIMESTAMP NOT NULL DEFAULT NOW(),
+                            created_at TIMESTAMP DEFAULT NOW()
 );
 
+CREATE INDEX IF NOT EXISTS merch_prices_merch_id_idx ON public.merch_prices (merch_id, effective_from DESC);
+
+-- Действующая цена: последняя версия, вступившая в силу
+CREATE OR REPLACE VIEW public.merch_current_prices AS
+SELECT DISTINCT ON (merch_id) merch_id, price
+FROM public.merch_prices
+WHERE effective_from <= NOW()
+ORDER BY merch_id, effective_from DESC, id DESC;
+
 CREATE TABLE IF NOT EXISTS public.inventory (
                             id SERIAL PRIMARY KEY,
                             user_id INT REFERENCES users(id) ON DELETE SET NULL,
@@ -28,17 +57,262 @@ CREATE TABLE IF NOT EXISTS public.inventory (
                             bought_on TIMESTAMP DEFAULT NOW()
 );
 
+CREATE TABLE IF NOT EXISTS public.orders (
+                            id BIGSERIAL PRIMARY KEY,
+                            user_id BIGINT REFERENCES public.users(id) ON DELETE SET NULL,
+                            total INT NOT NULL CHECK (total >= 0),
+                            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS orders_user_id_idx ON public.orders (user_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS public.purchases (
+                            id BIGSERIAL PRIMARY KEY,
+                            order_id BIGINT NOT NULL REFERENCES public.orders(id) ON DELETE CASCADE,
+                            user_id BIGINT REFERENCES public.users(id) ON DELETE SET NULL,
+                            merch_id BIGINT NOT NULL REFERENCES public.merch(id),
+                            quantity INT NOT NULL CHECK (quantity > 0),
+                            unit_price INT NOT NULL CHECK (unit_price > 0),
+                            returned_at TIMESTAMP,
+                            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS purchases_order_id_idx ON public.purchases (order_id);
+
+CREATE TABLE IF NOT EXISTS public.refresh_tokens (
+                            id BIGSERIAL PRIMARY KEY,
+                            user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+                            token_hash TEXT UNIQUE NOT NULL,
+                            family_id TEXT NOT NULL,
+                            expires_at TIMESTAMP NOT NULL,
+                            revoked_at TIMESTAMP,
+                            created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS refresh_tokens_family_id_idx ON public.refresh_tokens (family_id);
+
+CREATE TABLE IF NOT EXISTS public.revoked_tokens (
+                            jti TEXT PRIMARY KEY,
+                            user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+                            expires_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS public.invite_codes (
+                            code TEXT PRIMARY KEY,
+                            created_by BIGINT REFERENCES public.users(id) ON DELETE SET NULL,
+                            expires_at TIMESTAMP NOT NULL,
+                            used_by BIGINT REFERENCES public.users(id) ON DELETE SET NULL,
+                            used_at TIMESTAMP,
+                            created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS public.login_attempts (
+                            key TEXT PRIMARY KEY,
+                            failures INT NOT NULL DEFAULT 0,
+                            window_start TIMESTAMP NOT NULL DEFAULT NOW(),
+                            locked_until TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS public.auth_audit (
+                            id BIGSERIAL PRIMARY KEY,
+                            username VARCHAR(100) NOT NULL,
+                            ip TEXT NOT NULL,
+                            reason TEXT NOT NULL,
+                            created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS public.idempotency_keys (
+                            user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+                            key VARCHAR(255) NOT NULL,
+                            request_hash TEXT NOT NULL,
+                            response_status INT,
+                            response_body BYTEA,
+                            expires_at TIMESTAMP NOT NULL,
+                            created_at TIMESTAMP DEFAULT NOW(),
+                            PRIMARY KEY (user_id, key)
+);
+
+-- Крупные переводы ждут решения; сумма удерживается с баланса отправителя до решения или истечения
+CREATE TABLE IF NOT EXISTS public.pending_transfers (
+                            id BIGSERIAL PRIMARY KEY,
+                            from_user_id BIGINT NOT NULL REFERENCES public.users(id),
+                            to_user_id BIGINT NOT NULL REFERENCES public.users(id),
+                            amount INT NOT NULL CHECK (amount > 0),
+                            memo VARCHAR(200),
+                            status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'expired')),
+                            decided_by BIGINT REFERENCES public.users(id) ON DELETE SET NULL,
+                            transaction_id BIGINT REFERENCES public.transactions(id),
+                            expires_at TIMESTAMP NOT NULL,
+                            decided_at TIMESTAMP,
+                            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS pending_transfers_expires_at_idx ON public.pending_transfers (expires_at) WHERE status = 'pending';
+
+-- Запросы монет. Истёкший запрос остаётся в статусе pending, срок проверяется по expires_at
+CREATE TABLE IF NOT EXISTS public.coin_requests (
+                            id BIGSERIAL PRIMARY KEY,
+                            requester_id BIGINT NOT NULL REFERENCES public.users(id),
+                            payer_id BIGINT NOT NULL REFERENCES public.users(id),
+                            amount INT NOT NULL CHECK (amount > 0),
+                            memo VARCHAR(200),
+                            status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'declined')),
+                            transaction_id BIGINT REFERENCES public.transactions(id),
+                            expires_at TIMESTAMP NOT NULL,
+                            decided_at TIMESTAMP,
+                            created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                            CHECK (requester_id <> payer_id)
+);
+
+CREATE INDEX IF NOT EXISTS coin_requests_requester_idx ON public.coin_requests (requester_id, id);
+CREATE INDEX IF NOT EXISTS coin_requests_payer_idx ON public.coin_requests (payer_id, id);
+
+-- Запланированные переводы. next_run_at — очередной срок по расписанию, retry_at — время
+-- повтора после неудачи. Планировщик занимает срочные строки, сдвигая retry_at на время
+-- аренды, поэтому несколько реплик не возьмут один срок одновременно
+CREATE TABLE IF NOT EXISTS public.scheduled_transfers (
+                            id BIGSERIAL PRIMARY KEY,
+                            owner_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+                            to_user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+                            amount INT NOT NULL CHECK (amount > 0),
+                            memo VARCHAR(200),
+                            repeat TEXT NOT NULL CHECK (repeat IN ('once', 'daily', 'weekly', 'monthly')),
+                            next_run_at TIMESTAMP NOT NULL,
+                            retry_at TIMESTAMP,
+                            active BOOLEAN NOT NULL DEFAULT TRUE,
+                            failures INT NOT NULL DEFAULT 0,
+                            last_error TEXT,
+                            last_run_at TIMESTAMP,
+                            created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                            CHECK (owner_id <> to_user_id)
+);
+
+CREATE INDEX IF NOT EXISTS scheduled_transfers_owner_idx ON public.scheduled_transfers (owner_id, id);
+CREATE INDEX IF NOT EXISTS scheduled_transfers_due_idx ON public.scheduled_transfers ((COALESCE(retry_at, next_run_at))) WHERE active;
+
+-- Партии начисленных монет. Траты списываются с партий по порядку начисления,
+-- неизрасходованный остаток старых партий сгорает (COIN_EXPIRY_MONTHS). Перевод переносит
+-- партии получателю с прежней датой; пока перевод ждёт одобрения, его партии удерживаются
+-- (pending_transfer_id) и не тратятся и не сгорают
+CREATE TABLE IF NOT EXISTS public.coin_lots (
+                            id BIGSERIAL PRIMARY KEY,
+                            user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+                            source TEXT NOT NULL CHECK (source IN ('opening', 'grant', 'allowance')),
+                            amount INT NOT NULL CHECK (amount > 0),
+                            remaining INT NOT NULL CHECK (remaining >= 0 AND remaining <= amount),
+                            expired INT NOT NULL DEFAULT 0 CHECK (expired >= 0),
+                            pending_transfer_id BIGINT REFERENCES public.pending_transfers(id),
+                            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS coin_lots_unspent_idx ON public.coin_lots (user_id, created_at, id)
+    WHERE remaining > 0 AND pending_transfer_id IS NULL;
+CREATE INDEX IF NOT EXISTS coin_lots_pending_transfer_idx ON public.coin_lots (pending_transfer_id)
+    WHERE pending_transfer_id IS NOT NULL;
+
+-- Ежемесячное пособие: не больше одного начисления на пользователя за месяц
+CREATE TABLE IF NOT EXISTS public.allowances (
+                            user_id BIGINT NOT NULL REFERENCES public.users(id) ON DELETE CASCADE,
+                            period DATE NOT NULL,
+                            amount INT NOT NULL CHECK (amount > 0),
+                            created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+                            PRIMARY KEY (user_id, period)
+);
+
+-- Главная книга. users.coins — кэш баланса кошелька, который меняется в той же
+-- транзакции, что и проводки; источник истины — сумма проводок по счёту
+CREATE TABLE IF NOT EXISTS public.ledger_accounts (
+                            id BIGSERIAL PRIMARY KEY,
+                            kind TEXT NOT NULL CHECK (kind IN ('wallet', 'revenue', 'mint', 'escrow')),
+                            user_id BIGINT UNIQUE REFERENCES public.users(id),
+                            created_at TIMESTAMP DEFAULT NOW(),
+                            CHECK ((kind = 'wallet') = (user_id IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS ledger_accounts_system_kind_idx ON public.ledger_accounts (kind) WHERE kind <> 'wallet';
+
+CREATE TABLE IF NOT EXISTS public.ledger_entries (
+                            id BIGSERIAL PRIMARY KEY,
+                            kind TEXT NOT NULL CHECK (kind IN ('opening', 'transfer', 'purchase', 'refund', 'grant', 'hold', 'release', 'allowance', 'expiry')),
+                            transaction_id BIGINT REFERENCES public.transactions(id),
+                            order_id BIGINT REFERENCES public.orders(id),
+                            created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE IF NOT EXISTS public.ledger_postings (
+                            id BIGSERIAL PRIMARY KEY,
+                            entry_id BIGINT NOT NULL REFERENCES public.ledger_entries(id),
+                            account_id BIGINT NOT NULL REFERENCES public.ledger_accounts(id),
+                            amount BIGINT NOT NULL CHECK (amount <> 0)
+);
+
+CREATE INDEX IF NOT EXISTS ledger_postings_entry_id_idx ON public.ledger_postings (entry_id);
+CREATE INDEX IF NOT EXISTS ledger_postings_account_id_idx ON public.ledger_postings (account_id);
+
+-- Сумма проводок записи проверяется при фиксации транзакции, когда записаны все её проводки
+CREATE OR REPLACE FUNCTION public.ledger_check_balanced() RETURNS trigger AS $$
+BEGIN
+    IF (SELECT SUM(amount) FROM public.ledger_postings WHERE entry_id = NEW.entry_id) <> 0 THEN
+        RAISE EXCEPTION 'ledger entry % is not balanced', NEW.entry_id;
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE CONSTRAINT TRIGGER ledger_postings_balanced
+    AFTER INSERT OR UPDATE ON public.ledger_postings
+    DEFERRABLE INITIALLY DEFERRED
+    FOR EACH ROW EXECUTE FUNCTION public.ledger_check_balanced();
+
+CREATE OR REPLACE VIEW public.ledger_balances AS
+SELECT a.id AS account_id, a.kind, a.user_id, COALESCE(SUM(p.amount), 0) AS balance
+FROM public.ledger_accounts a
+LEFT JOIN public.ledger_postings p ON p.account_id = a.id
+GROUP BY a.id;
+
+-- Расхождения, найденные сверкой; открытой по одному объекту может быть только одна
+CREATE TABLE IF NOT EXISTS public.anomalies (
+                            id BIGSERIAL PRIMARY KEY,
+                            kind TEXT NOT NULL,
+                            ref TEXT NOT NULL,
+                            details JSONB NOT NULL,
+                            created_at TIMESTAMP DEFAULT NOW(),
+                            resolved_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS anomalies_open_idx ON public.anomalies (kind, ref) WHERE resolved_at IS NULL;
+
+INSERT INTO public.ledger_accounts (kind)
+VALUES ('revenue'), ('mint'), ('escrow')
+ON CONFLICT (kind) WHERE kind <> 'wallet' DO NOTHING;
+
 ALTER TABLE public.inventory ADD CONSTRAINT inventory_unique_user_merch UNIQUE (user_id, merch_id);
 
-INSERT INTO public.merch (name, price) VALUES
-                            ('t-shirt', 80),
-                            ('cup', 20),
-                            ('book', 50),
-                            ('pen', 10),
-                            ('powerbank', 200),
-                            ('hoody', 300),
-                            ('umbrella', 200),
-                            ('socks', 10),
-                            ('wallet', 50),
-                            ('pink-hoody', 500)
-ON CONFLICT (name) DO NOTHING;
\ No newline at end of file
+-- Отправитель начислений. Пароль не является хешем ни в одном формате, вход под ним запрещён
+INSERT INTO public.users (username, password, coins)
+VALUES ('system', '*', 0)
+ON CONFLICT (username) DO NOTHING;
+
+WITH seed (name, category, price) AS (
+    VALUES
+                            ('t-shirt', 'clothing', 80),
+                            ('cup', 'accessories', 20),
+                            ('book', 'stationery', 50),
+                            ('pen', 'stationery', 10),
+                            ('powerbank', 'accessories', 200),
+                            ('hoody', 'clothing', 300),
+                            ('umbrella', 'accessories', 200),
+                            ('socks', 'clothing', 10),
+                            ('wallet', 'accessories', 50),
+                            ('pink-hoody', 'clothing', 500)
+),
+inserted AS (
+    INSERT INTO public.merch (name, category)
+    SELECT name, category FROM seed
+    ON CONFLICT (name) DO NOTHING
+    RETURNING id, name
+)
+INSERT INTO public.merch_prices (merch_id, price, effective_from)
+SELECT i.id, s.price, '-infinity'
+FROM inserted i
+JOIN seed s ON s.name = i.name;
\ No newline at end of file
@@ -0,0 +1,325 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"github.com/go-chi/chi/v5"
+	"io"
+	"log/slog"
+	"merch-shop/internal/api/apierror"
+	"merch-shop/internal/domain"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+func (h *HTTPHandler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.SetRoleRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	if err = h.useCase.SetUserRole(ctx, username, body.Role); err != nil {
+		slog.Error("useCase.SetUserRole", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, apierror.JSON{}, http.StatusOK)
+}
+
+func (h *HTTPHandler) SetUserManager(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.SetManagerRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	if err = h.useCase.SetUserManager(ctx, username, body.Manager); err != nil {
+		slog.Error("useCase.SetUserManager", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, apierror.JSON{}, http.StatusOK)
+}
+
+func (h *HTTPHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	username := chi.URLParam(r, "username")
+	if username == "" {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.useCase.RevokeSessionsByUsername(ctx, username); err != nil {
+		slog.Error("useCase.RevokeSessionsByUsername", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, apierror.JSON{}, http.StatusOK)
+}
+
+func (h *HTTPHandler) CreateMerch(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.CreateMerchRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	item, err := h.useCase.CreateMerch(ctx, body)
+	if err != nil {
+		slog.Error("useCase.CreateMerch", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, item, http.StatusCreated)
+}
+
+func (h *HTTPHandler) UpdateMerch(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.UpdateMerchRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	if err = h.useCase.UpdateMerch(ctx, name, body); err != nil {
+		slog.Error("useCase.UpdateMerch", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, apierror.JSON{}, http.StatusOK)
+}
+
+func (h *HTTPHandler) RetireMerch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	if err := h.useCase.RetireMerch(ctx, name); err != nil {
+		slog.Error("useCase.RetireMerch", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, apierror.JSON{}, http.StatusOK)
+}
+
+type stockResp struct {
+	Stock *uint64 `json:"stock"`
+}
+
+func (h *HTTPHandler) RestockMerch(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.RestockRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	stock, err := h.useCase.RestockMerch(ctx, name, body.Quantity)
+	if err != nil {
+		slog.Error("useCase.RestockMerch", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, stockResp{Stock: stock}, http.StatusOK)
+}
+
+func (h *HTTPHandler) SetMerchStock(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.SetStockRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	name := chi.URLParam(r, "name")
+	if name == "" {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	if err = h.useCase.SetMerchStock(ctx, name, body.Stock); err != nil {
+		slog.Error("useCase.SetMerchStock", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, stockResp{Stock: body.Stock}, http.StatusOK)
+}
+
+// GrantCoins принимает JSON или CSV (Content-Type: text/csv, строки "username,amount").
+// Для CSV причина передаётся параметром reason; dryRun=true в запросе включает пробный прогон.
+func (h *HTTPHandler) GrantCoins(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.GrantRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		body.Reason = r.URL.Query().Get("reason")
+		body.Grants, err = parseGrantCSV(r.Body)
+	} else {
+		err = json.NewDecoder(r.Body).Decode(&body)
+	}
+	if err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if v := r.URL.Query().Get("dryRun"); v != "" {
+		if body.DryRun, err = strconv.ParseBool(v); err != nil {
+			apierror.WriteError(w, apierror.ErrInvalidRequest)
+			return
+		}
+	}
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	result, err := h.useCase.GrantCoins(ctx, body)
+	if err != nil {
+		slog.Error("useCase.GrantCoins", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, result, http.StatusOK)
+}
+
+// parseGrantCSV читает строки "username,amount"; строка заголовка пропускается
+func parseGrantCSV(r io.Reader) ([]domain.GrantLine, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	var lines []domain.GrantLine
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if len(lines) == 0 && strings.EqualFold(record[0], "username") {
+			continue
+		}
+
+		amount, err := strconv.ParseUint(record[1], 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, domain.GrantLine{Username: record[0], Amount: amount})
+	}
+
+	return lines, nil
+}
@@ -2,34 +2,51 @@ package apierror
 
 import (
 	"errors"
+	"math"
 	"merch-shop/internal/usecase"
 	"net/http"
+	"strconv"
 )
 
 var (
 	ErrParsingBody           = errors.New("failed to parse the request body")
 	ErrValidatingBody        = errors.New("failed to validate the structure of request body")
 	ErrInvalidToken          = errors.New("invalid token")
+	ErrTokenExpired          = errors.New("token expired")
 	ErrParsingToken          = errors.New("failed to parse the JWT token")
 	ErrInvalidAuthHeader     = errors.New("the Authorization header is empty or does not contain Bearer token")
 	ErrAuthorizationRequired = errors.New("authorization required")
 	ErrInvalidRequest        = errors.New("invalid request")
+	ErrForbidden             = errors.New("insufficient permissions")
+	ErrBodyTooLarge          = errors.New("request body is too large")
 )
 
 type Err struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code       int         `json:"code"`
+	Message    string      `json:"message"`
+	Details    interface{} `json:"details,omitempty"`
+	RetryAfter int         `json:"-"`
 }
 
 func WriteError(w http.ResponseWriter, err error) {
 	e := FromError(err)
-	RenderJSONWithStatus(w, JSON{"error": e.Message}, e.Code)
+	if e.RetryAfter > 0 {
+		w.Header().Set("Retry-After", strconv.Itoa(e.RetryAfter))
+	}
+
+	body := JSON{"error": e.Message}
+	if e.Details != nil {
+		body["details"] = e.Details
+	}
+	RenderJSONWithStatus(w, body, e.Code)
 }
 
 func FromError(err error) *Err {
 	var (
-		code    int
-		message string
+		code       int
+		message    string
+		details    interface{}
+		retryAfter int
 	)
 
 	switch {
@@ -49,6 +66,18 @@ func FromError(err error) *Err {
 	case errors.Is(err, ErrInvalidToken):
 		code = http.StatusUnauthorized
 		message = err.Error()
+	case errors.Is(err, ErrTokenExpired):
+		code = http.StatusUnauthorized
+		message = err.Error()
+	case errors.Is(err, usecase.ErrInvalidRefreshToken):
+		code = http.StatusUnauthorized
+		message = err.Error()
+	case errors.Is(err, usecase.ErrRefreshTokenReused):
+		code = http.StatusUnauthorized
+		message = err.Error()
+	case errors.Is(err, usecase.ErrSessionRevoked):
+		code = http.StatusUnauthorized
+		message = err.Error()
 	case errors.Is(err, ErrInvalidAuthHeader):
 		code = http.StatusUnauthorized
 		message = err.Error()
@@ -58,6 +87,114 @@ func FromError(err error) *Err {
 	case errors.Is(err, ErrAuthorizationRequired):
 		code = http.StatusUnauthorized
 		message = err.Error()
+	case errors.Is(err, ErrForbidden):
+		code = http.StatusForbidden
+		message = err.Error()
+	case errors.Is(err, ErrBodyTooLarge):
+		code = http.StatusRequestEntityTooLarge
+		message = err.Error()
+	case errors.Is(err, usecase.ErrInvalidRole):
+		code = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, usecase.ErrUserNotFound):
+		code = http.StatusNotFound
+		message = err.Error()
+	case errors.Is(err, usecase.ErrMerchNotFound):
+		code = http.StatusNotFound
+		message = err.Error()
+	case errors.Is(err, usecase.ErrMerchExists):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, usecase.ErrInvalidCursor):
+		code = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, usecase.ErrOutOfStock):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, usecase.ErrOrderRejected):
+		code = http.StatusUnprocessableEntity
+		message = usecase.ErrOrderRejected.Error()
+
+		var orderErr *usecase.OrderError
+		if errors.As(err, &orderErr) {
+			details = orderErr.Lines
+		}
+	case errors.Is(err, usecase.ErrGrantRejected):
+		code = http.StatusUnprocessableEntity
+		message = usecase.ErrGrantRejected.Error()
+
+		var grantErr *usecase.GrantError
+		if errors.As(err, &grantErr) {
+			details = grantErr.Lines
+		}
+	case errors.Is(err, usecase.ErrBatchRejected):
+		code = http.StatusUnprocessableEntity
+		message = usecase.ErrBatchRejected.Error()
+
+		var batchErr *usecase.BatchError
+		if errors.As(err, &batchErr) {
+			details = batchErr.Lines
+		}
+	case errors.Is(err, usecase.ErrTransferLimit):
+		code = http.StatusUnprocessableEntity
+		message = usecase.ErrTransferLimit.Error()
+
+		var limitErr *usecase.LimitError
+		if errors.As(err, &limitErr) {
+			details = limitErr
+			retryAfter = int(math.Ceil(limitErr.RetryAfter.Seconds()))
+		}
+	case errors.Is(err, usecase.ErrTransferNotFound):
+		code = http.StatusNotFound
+		message = err.Error()
+	case errors.Is(err, usecase.ErrNotApprover):
+		code = http.StatusForbidden
+		message = err.Error()
+	case errors.Is(err, usecase.ErrTransferDecided):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, usecase.ErrTransferExpired):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, usecase.ErrInvalidManager):
+		code = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, usecase.ErrCoinRequestSelf):
+		code = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, usecase.ErrCoinRequestNotFound):
+		code = http.StatusNotFound
+		message = err.Error()
+	case errors.Is(err, usecase.ErrCoinRequestDecided):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, usecase.ErrCoinRequestExpired):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, usecase.ErrScheduleNotFound):
+		code = http.StatusNotFound
+		message = err.Error()
+	case errors.Is(err, usecase.ErrSystemAccount):
+		code = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, usecase.ErrOrderNotFound):
+		code = http.StatusNotFound
+		message = err.Error()
+	case errors.Is(err, usecase.ErrNothingToReturn):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, usecase.ErrReturnWindowExpired):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, usecase.ErrItemNotOwned):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, usecase.ErrIdempotencyConflict):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, usecase.ErrIdempotencyMismatch):
+		code = http.StatusUnprocessableEntity
+		message = err.Error()
 	case errors.Is(err, usecase.ErrNoCoins):
 		code = http.StatusBadRequest
 		message = err.Error()
@@ -73,10 +210,29 @@ func FromError(err error) *Err {
 	case errors.Is(err, usecase.UsernameNotValid):
 		code = http.StatusBadRequest
 		message = err.Error()
+	case errors.Is(err, usecase.ErrUserExists):
+		code = http.StatusConflict
+		message = err.Error()
+	case errors.Is(err, usecase.ErrInviteRequired):
+		code = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, usecase.ErrInvalidInvite):
+		code = http.StatusBadRequest
+		message = err.Error()
+	case errors.Is(err, usecase.ErrTooManyAttempts):
+		code = http.StatusTooManyRequests
+		message = err.Error()
+
+		var lockErr *usecase.LockedError
+		if errors.As(err, &lockErr) {
+			retryAfter = int(math.Ceil(lockErr.RetryAfter.Seconds()))
+		}
 	}
 
 	return &Err{
-		Code:    code,
-		Message: message,
+		Code:       code,
+		Message:    message,
+		Details:    details,
+		RetryAfter: retryAfter,
 	}
 }
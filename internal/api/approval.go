@@ -0,0 +1,86 @@
+package api
+
+import (
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"merch-shop/internal/api/apierror"
+	shopcontext "merch-shop/internal/api/context"
+	"merch-shop/internal/domain"
+	"net/http"
+	"strconv"
+)
+
+type approvalsResp struct {
+	Transfers []domain.PendingTransfer `json:"transfers"`
+}
+
+func (h *HTTPHandler) ListApprovals(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	role, ok := shopcontext.Role(ctx)
+	if !ok {
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	transfers, err := h.useCase.ListApprovals(ctx, userID, role)
+	if err != nil {
+		slog.Error("useCase.ListApprovals", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, approvalsResp{Transfers: transfers}, http.StatusOK)
+}
+
+func (h *HTTPHandler) ApproveTransfer(w http.ResponseWriter, r *http.Request) {
+	h.decideTransfer(w, r, true)
+}
+
+func (h *HTTPHandler) RejectTransfer(w http.ResponseWriter, r *http.Request) {
+	h.decideTransfer(w, r, false)
+}
+
+func (h *HTTPHandler) decideTransfer(w http.ResponseWriter, r *http.Request, approve bool) {
+	ctx := r.Context()
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	role, ok := shopcontext.Role(ctx)
+	if !ok {
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	transferID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	transfer, err := h.useCase.DecideTransfer(ctx, domain.TransferDecision{
+		TransferID: transferID,
+		ApproverID: userID,
+		Role:       role,
+		Approve:    approve,
+	})
+	if err != nil {
+		slog.Error("useCase.DecideTransfer", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, transfer, http.StatusOK)
+}
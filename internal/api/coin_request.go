@@ -0,0 +1,129 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"merch-shop/internal/api/apierror"
+	shopcontext "merch-shop/internal/api/context"
+	"merch-shop/internal/domain"
+	"net/http"
+	"strconv"
+)
+
+func (h *HTTPHandler) CreateCoinRequest(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.CreateCoinRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	request, err := h.useCase.CreateCoinRequest(ctx, userID, body)
+	if err != nil {
+		slog.Error("useCase.CreateCoinRequest", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, request, http.StatusCreated)
+}
+
+func (h *HTTPHandler) ListCoinRequests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	query := domain.CoinRequestQuery{
+		Box:    r.URL.Query().Get("box"),
+		Status: r.URL.Query().Get("status"),
+		Cursor: r.URL.Query().Get("cursor"),
+	}
+	if query.Box == "" {
+		query.Box = domain.CoinRequestInbox
+	}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			apierror.WriteError(w, apierror.ErrInvalidRequest)
+			return
+		}
+		query.Limit = limit
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	page, err := h.useCase.ListCoinRequests(ctx, userID, query)
+	if err != nil {
+		slog.Error("useCase.ListCoinRequests", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, page, http.StatusOK)
+}
+
+func (h *HTTPHandler) AcceptCoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.answerCoinRequest(w, r, "useCase.AcceptCoinRequest", h.useCase.AcceptCoinRequest)
+}
+
+func (h *HTTPHandler) DeclineCoinRequest(w http.ResponseWriter, r *http.Request) {
+	h.answerCoinRequest(w, r, "useCase.DeclineCoinRequest", h.useCase.DeclineCoinRequest)
+}
+
+func (h *HTTPHandler) answerCoinRequest(
+	w http.ResponseWriter,
+	r *http.Request,
+	op string,
+	answer func(ctx context.Context, payerID, requestID uint64) (domain.CoinRequest, error),
+) {
+	ctx := r.Context()
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	requestID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	request, err := answer(ctx, userID, requestID)
+	if err != nil {
+		slog.Error(op, "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, request, http.StatusOK)
+}
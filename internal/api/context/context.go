@@ -1,10 +1,17 @@
 package context
 
-import "context"
+import (
+	"context"
+	"merch-shop/internal/domain"
+)
 
 type contextKey int
 
-const userIDKey contextKey = iota
+const (
+	userIDKey contextKey = iota
+	sessionKey
+	roleKey
+)
 
 func WithUserID(ctx context.Context, userID uint64) context.Context {
 	if ctx == nil {
@@ -25,3 +32,35 @@ func UserID(ctx context.Context) (uint64, bool) {
 
 	return eID, ok
 }
+
+func WithSession(ctx context.Context, session domain.Session) context.Context {
+	if ctx == nil {
+		return nil
+	}
+	return context.WithValue(ctx, sessionKey, session)
+}
+
+func Session(ctx context.Context) (domain.Session, bool) {
+	if ctx == nil {
+		return domain.Session{}, false
+	}
+
+	session, ok := ctx.Value(sessionKey).(domain.Session)
+	return session, ok
+}
+
+func WithRole(ctx context.Context, role domain.Role) context.Context {
+	if ctx == nil {
+		return nil
+	}
+	return context.WithValue(ctx, roleKey, role)
+}
+
+func Role(ctx context.Context) (domain.Role, bool) {
+	if ctx == nil {
+		return "", false
+	}
+
+	role, ok := ctx.Value(roleKey).(domain.Role)
+	return role, ok
+}
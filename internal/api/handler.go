@@ -3,14 +3,19 @@ package api
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-playground/validator/v10"
+	"io"
 	"log/slog"
 	"merch-shop/internal/api/apierror"
 	shopcontext "merch-shop/internal/api/context"
 	"merch-shop/internal/domain"
 	"merch-shop/internal/usecase"
+	"net"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 type HTTPHandler struct {
@@ -29,11 +34,42 @@ func NewHTTPHandler(useCase *usecase.UseCase) *HTTPHandler {
 
 //go:generate mockery --name=UseCase --output=./mocks --filename=useCase.go --structname=UseCase
 type UseCase interface {
-	GetInfo(ctx context.Context, userID uint64) (domain.Info, error)
-	Login(ctx context.Context, creds domain.Credentials) (string, error)
+	GetInfo(ctx context.Context, userID uint64, historyLimit int) (domain.Info, error)
+	ListHistory(ctx context.Context, userID uint64, query domain.HistoryQuery) (domain.HistoryPage, error)
+	PreviewExpiry(ctx context.Context, userID uint64) (domain.ExpiryPreview, error)
+	Login(ctx context.Context, creds domain.Credentials, clientIP string) (domain.Tokens, error)
+	Register(ctx context.Context, req domain.RegisterRequest) (domain.Tokens, error)
+	CreateInvite(ctx context.Context, userID uint64) (domain.Invite, error)
+	Refresh(ctx context.Context, refreshToken string) (domain.Tokens, error)
+	Logout(ctx context.Context, session domain.Session, refreshToken string) error
+	RevokeUserSessions(ctx context.Context, userID uint64) error
+	RevokeSessionsByUsername(ctx context.Context, username string) error
+	SetUserRole(ctx context.Context, username string, role domain.Role) error
+	SetUserManager(ctx context.Context, username, managerUsername string) error
+	GrantCoins(ctx context.Context, req domain.GrantRequest) (domain.GrantResult, error)
+	CreateMerch(ctx context.Context, req domain.CreateMerchRequest) (domain.Merch, error)
+	UpdateMerch(ctx context.Context, name string, req domain.UpdateMerchRequest) error
+	RetireMerch(ctx context.Context, name string) error
+	RestockMerch(ctx context.Context, name string, quantity uint64) (*uint64, error)
+	SetMerchStock(ctx context.Context, name string, stock *uint64) error
+	ListMerch(ctx context.Context, query domain.CatalogQuery) (domain.CatalogPage, error)
 	CheckCredentials(ctx context.Context, creds domain.Credentials) (uint64, error)
-	SendCoin(ctx context.Context, fromUserID uint64, req domain.SendCoinRequest) error
+	SendCoin(ctx context.Context, fromUserID uint64, req domain.SendCoinRequest) (*domain.PendingTransfer, error)
+	BatchSendCoin(ctx context.Context, fromUserID uint64, req domain.BatchSendCoinRequest) (domain.BatchResult, error)
+	ListApprovals(ctx context.Context, userID uint64, role domain.Role) ([]domain.PendingTransfer, error)
+	DecideTransfer(ctx context.Context, decision domain.TransferDecision) (domain.PendingTransfer, error)
+	CreateCoinRequest(ctx context.Context, requesterID uint64, req domain.CreateCoinRequest) (domain.CoinRequest, error)
+	ListCoinRequests(ctx context.Context, userID uint64, query domain.CoinRequestQuery) (domain.CoinRequestPage, error)
+	AcceptCoinRequest(ctx context.Context, payerID, requestID uint64) (domain.CoinRequest, error)
+	DeclineCoinRequest(ctx context.Context, payerID, requestID uint64) (domain.CoinRequest, error)
+	CreateScheduledTransfer(ctx context.Context, ownerID uint64, req domain.ScheduledTransferRequest) (domain.ScheduledTransfer, error)
+	ListScheduledTransfers(ctx context.Context, ownerID uint64) ([]domain.ScheduledTransfer, error)
+	UpdateScheduledTransfer(ctx context.Context, ownerID, transferID uint64, req domain.ScheduledTransferRequest) (domain.ScheduledTransfer, error)
+	DeleteScheduledTransfer(ctx context.Context, ownerID, transferID uint64) error
 	BuyMerch(ctx context.Context, userID uint64, itemName string) error
+	CreateOrder(ctx context.Context, userID uint64, req domain.OrderRequest) (domain.Order, error)
+	ListOrders(ctx context.Context, userID uint64, query domain.OrderQuery) (domain.OrderPage, error)
+	ReturnOrder(ctx context.Context, userID, orderID uint64, req domain.ReturnRequest) (domain.Refund, error)
 }
 
 type authReq struct {
@@ -41,7 +77,16 @@ type authReq struct {
 }
 
 type authResp struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type refreshReq struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+type logoutReq struct {
+	RefreshToken string `json:"refreshToken"`
 }
 
 func (h *HTTPHandler) Auth(w http.ResponseWriter, r *http.Request) {
@@ -62,14 +107,148 @@ func (h *HTTPHandler) Auth(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	token, err := h.useCase.Login(ctx, body.Credentials)
+	tokens, err := h.useCase.Login(ctx, body.Credentials, clientIP(r))
 	if err != nil {
 		slog.Error("useCase.Login", "error", err)
 		apierror.WriteError(w, err)
 		return
 	}
 
-	apierror.RenderJSONWithStatus(w, authResp{Token: token}, http.StatusOK)
+	apierror.RenderJSONWithStatus(w, authResp{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}, http.StatusOK)
+}
+
+func (h *HTTPHandler) Register(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.RegisterRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	tokens, err := h.useCase.Register(ctx, body)
+	if err != nil {
+		slog.Error("useCase.Register", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, authResp{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}, http.StatusCreated)
+}
+
+func (h *HTTPHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	invite, err := h.useCase.CreateInvite(ctx, userID)
+	if err != nil {
+		slog.Error("useCase.CreateInvite", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, invite, http.StatusCreated)
+}
+
+func (h *HTTPHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	var (
+		body refreshReq
+		err  error
+		ctx  = r.Context()
+	)
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	tokens, err := h.useCase.Refresh(ctx, body.RefreshToken)
+	if err != nil {
+		slog.Error("useCase.Refresh", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, authResp{
+		Token:        tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}, http.StatusOK)
+}
+
+func (h *HTTPHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	var (
+		body logoutReq
+		err  error
+		ctx  = r.Context()
+	)
+
+	session, ok := shopcontext.Session(ctx)
+	if !ok {
+		slog.Error("Failed to get session")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	// Тело запроса необязательно: без него отзывается только access-токен
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.useCase.Logout(ctx, session, body.RefreshToken); err != nil {
+		slog.Error("useCase.Logout", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, apierror.JSON{}, http.StatusOK)
+}
+
+func (h *HTTPHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	if err := h.useCase.RevokeUserSessions(ctx, userID); err != nil {
+		slog.Error("useCase.RevokeUserSessions", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, apierror.JSON{}, http.StatusOK)
 }
 
 func (h *HTTPHandler) Info(w http.ResponseWriter, r *http.Request) {
@@ -82,7 +261,17 @@ func (h *HTTPHandler) Info(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	info, err := h.useCase.GetInfo(ctx, userID)
+	var historyLimit int
+	if v := r.URL.Query().Get("historyLimit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 {
+			apierror.WriteError(w, apierror.ErrInvalidRequest)
+			return
+		}
+		historyLimit = limit
+	}
+
+	info, err := h.useCase.GetInfo(ctx, userID, historyLimit)
 	if err != nil {
 		slog.Error("useCase.GetInfo", "error", err)
 		apierror.WriteError(w, err)
@@ -92,6 +281,113 @@ func (h *HTTPHandler) Info(w http.ResponseWriter, r *http.Request) {
 	apierror.RenderJSONWithStatus(w, info, http.StatusOK)
 }
 
+func (h *HTTPHandler) History(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	query, err := parseHistoryQuery(r)
+	if err != nil {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	if err = h.validate.Struct(query); err != nil {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	page, err := h.useCase.ListHistory(ctx, userID, query)
+	if err != nil {
+		slog.Error("useCase.ListHistory", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, page, http.StatusOK)
+}
+
+func (h *HTTPHandler) ExpiringCoins(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	preview, err := h.useCase.PreviewExpiry(ctx, userID)
+	if err != nil {
+		slog.Error("useCase.PreviewExpiry", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, preview, http.StatusOK)
+}
+
+func parseHistoryQuery(r *http.Request) (domain.HistoryQuery, error) {
+	values := r.URL.Query()
+
+	query := domain.HistoryQuery{
+		Direction:    values.Get("direction"),
+		Counterparty: values.Get("counterparty"),
+		Cursor:       values.Get("cursor"),
+	}
+
+	if v := values.Get("from"); v != "" {
+		from, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return query, err
+		}
+		query.From = &from
+	}
+
+	if v := values.Get("to"); v != "" {
+		to, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return query, err
+		}
+		query.To = &to
+	}
+
+	if query.From != nil && query.To != nil && !query.From.Before(*query.To) {
+		return query, errors.New("empty date range")
+	}
+
+	if v := values.Get("minAmount"); v != "" {
+		minAmount, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return query, err
+		}
+		query.MinAmount = minAmount
+	}
+
+	if v := values.Get("maxAmount"); v != "" {
+		maxAmount, err := strconv.ParseUint(v, 10, 64)
+		if err != nil {
+			return query, err
+		}
+		query.MaxAmount = maxAmount
+	}
+
+	if v := values.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			return query, err
+		}
+		query.Limit = limit
+	}
+
+	return query, nil
+}
+
 func (h *HTTPHandler) SendCoin(w http.ResponseWriter, r *http.Request) {
 	var (
 		body domain.SendCoinRequest
@@ -117,16 +413,58 @@ func (h *HTTPHandler) SendCoin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err = h.useCase.SendCoin(ctx, fromUserID, body); err != nil {
+	pending, err := h.useCase.SendCoin(ctx, fromUserID, body)
+	if err != nil {
 		slog.Error("useCase.SendCoin", "error", err)
 		apierror.WriteError(w, err)
 		return
 	}
 
+	// Крупный перевод принят, но будет выполнен только после одобрения
+	if pending != nil {
+		apierror.RenderJSONWithStatus(w, pending, http.StatusAccepted)
+		return
+	}
+
 	apierror.RenderJSONWithStatus(w, apierror.JSON{}, http.StatusOK)
 
 }
 
+func (h *HTTPHandler) BatchSendCoin(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.BatchSendCoinRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	fromUserID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	result, err := h.useCase.BatchSendCoin(ctx, fromUserID, body)
+	if err != nil {
+		slog.Error("useCase.BatchSendCoin", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, result, http.StatusOK)
+}
+
 func (h *HTTPHandler) BuyMerch(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	item := chi.URLParam(r, "item")
@@ -151,3 +489,181 @@ func (h *HTTPHandler) BuyMerch(w http.ResponseWriter, r *http.Request) {
 
 	apierror.RenderJSONWithStatus(w, apierror.JSON{}, http.StatusOK)
 }
+
+func (h *HTTPHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.OrderRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	order, err := h.useCase.CreateOrder(ctx, userID, body)
+	if err != nil {
+		slog.Error("useCase.CreateOrder", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, order, http.StatusCreated)
+}
+
+func (h *HTTPHandler) ListOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	query := domain.OrderQuery{Cursor: r.URL.Query().Get("cursor")}
+	if v := r.URL.Query().Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil {
+			apierror.WriteError(w, apierror.ErrInvalidRequest)
+			return
+		}
+		query.Limit = limit
+	}
+
+	if err := h.validate.Struct(query); err != nil {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	page, err := h.useCase.ListOrders(ctx, userID, query)
+	if err != nil {
+		slog.Error("useCase.ListOrders", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, page, http.StatusOK)
+}
+
+func (h *HTTPHandler) ReturnOrder(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.ReturnRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	orderID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	// Без тела возвращаются все позиции заказа
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	refund, err := h.useCase.ReturnOrder(ctx, userID, orderID, body)
+	if err != nil {
+		slog.Error("useCase.ReturnOrder", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, refund, http.StatusOK)
+}
+
+func (h *HTTPHandler) ListMerch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	query, err := parseCatalogQuery(r)
+	if err != nil {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	if err = h.validate.Struct(query); err != nil {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	page, err := h.useCase.ListMerch(ctx, query)
+	if err != nil {
+		slog.Error("useCase.ListMerch", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithETag(w, r, page)
+}
+
+func parseCatalogQuery(r *http.Request) (domain.CatalogQuery, error) {
+	values := r.URL.Query()
+
+	query := domain.CatalogQuery{
+		Category: values.Get("category"),
+		Sort:     values.Get("sort"),
+		Cursor:   values.Get("cursor"),
+	}
+
+	var err error
+	if v := values.Get("minPrice"); v != "" {
+		if query.MinPrice, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return query, err
+		}
+	}
+
+	if v := values.Get("maxPrice"); v != "" {
+		if query.MaxPrice, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return query, err
+		}
+	}
+
+	if v := values.Get("limit"); v != "" {
+		if query.Limit, err = strconv.Atoi(v); err != nil {
+			return query, err
+		}
+	}
+
+	return query, nil
+}
+
+// clientIP берёт адрес из соединения: заголовкам X-Forwarded-For доверять нельзя,
+// иначе ограничение по IP обходится подстановкой произвольного адреса
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
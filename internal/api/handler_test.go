@@ -9,10 +9,12 @@ import (
 	shopcontext "merch-shop/internal/api/context"
 	"merch-shop/internal/api/mocks"
 	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
@@ -60,6 +62,13 @@ func TestBuyMerch(t *testing.T) {
 			mockUseCaseErr: errors.New("DB error"),
 			expectedStatus: http.StatusInternalServerError,
 		},
+		{
+			name:           "Out of stock",
+			item:           "powerbank",
+			authHeader:     "Bearer valid_token",
+			mockUseCaseErr: fmt.Errorf("repo.BuyMerch: %w", usecase.ErrOutOfStock),
+			expectedStatus: http.StatusConflict,
+		},
 	}
 
 	for _, tt := range tests {
@@ -129,6 +138,29 @@ func TestSendCoin(t *testing.T) {
 			expectedStatus: http.StatusUnauthorized,
 			expectMockCall: false,
 		},
+		{
+			name: "Amount out of range",
+			requestBody: domain.SendCoinRequest{
+				ToUser: "recipient",
+				Amount: 2147483648,
+			},
+			authHeader:     "Bearer valid_token",
+			mockUseCaseErr: nil,
+			expectedStatus: http.StatusBadRequest,
+			expectMockCall: false,
+		},
+		{
+			name: "Memo too long",
+			requestBody: domain.SendCoinRequest{
+				ToUser: "recipient",
+				Amount: 10,
+				Memo:   strings.Repeat("м", 201),
+			},
+			authHeader:     "Bearer valid_token",
+			mockUseCaseErr: nil,
+			expectedStatus: http.StatusBadRequest,
+			expectMockCall: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -154,7 +186,7 @@ func TestSendCoin(t *testing.T) {
 			if tt.expectMockCall {
 				if validReq, ok := tt.requestBody.(domain.SendCoinRequest); ok {
 					mockUseCase.On("SendCoin", mock.Anything, uint64(1), validReq).
-						Return(tt.mockUseCaseErr).Once()
+						Return(nil, tt.mockUseCaseErr).Once()
 				}
 			}
 
@@ -179,3 +211,736 @@ func TestSendCoin(t *testing.T) {
 		})
 	}
 }
+
+func TestAuth(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name             string
+		mockUseCaseErr   error
+		expectedStatus   int
+		expectRetryAfter string
+	}{
+		{
+			name:           "Successful login",
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid password",
+			mockUseCaseErr: usecase.ErrUnauthorized,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:             "Login locked",
+			mockUseCaseErr:   &usecase.LockedError{RetryAfter: 90*time.Second + time.Millisecond},
+			expectedStatus:   http.StatusTooManyRequests,
+			expectRetryAfter: "91",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockUseCase := new(mocks.UseCase)
+			handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+			creds := domain.Credentials{Username: "testuser", Password: "TestPassword1"}
+			mockUseCase.On("Login", mock.Anything, creds, "192.0.2.1").
+				Return(domain.Tokens{AccessToken: "access", RefreshToken: "refresh"}, tt.mockUseCaseErr).Once()
+
+			reqBody, err := json.Marshal(creds)
+			if err != nil {
+				t.Fatalf("Failed to marshal request body: %v", err)
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/auth", bytes.NewBuffer(reqBody))
+			req.RemoteAddr = "192.0.2.1:1234"
+
+			rec := httptest.NewRecorder()
+			handler.Auth(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectRetryAfter, rec.Header().Get("Retry-After"))
+
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateMerch(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name           string
+		requestBody    string
+		mockUseCaseErr error
+		expectedStatus int
+		expectMockCall bool
+	}{
+		{
+			name:           "Reprice",
+			requestBody:    `{"price": 120}`,
+			expectedStatus: http.StatusOK,
+			expectMockCall: true,
+		},
+		{
+			name:           "Rename with scheduled price",
+			requestBody:    `{"name": "cap", "price": 120, "effectiveFrom": "2030-01-01T00:00:00Z"}`,
+			expectedStatus: http.StatusOK,
+			expectMockCall: true,
+		},
+		{
+			name:           "Empty update",
+			requestBody:    `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Effective date without price",
+			requestBody:    `{"name": "cap", "effectiveFrom": "2030-01-01T00:00:00Z"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown item",
+			requestBody:    `{"price": 120}`,
+			mockUseCaseErr: usecase.ErrMerchNotFound,
+			expectedStatus: http.StatusNotFound,
+			expectMockCall: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockUseCase := new(mocks.UseCase)
+			handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+			if tt.expectMockCall {
+				mockUseCase.On("UpdateMerch", mock.Anything, "hat", mock.AnythingOfType("domain.UpdateMerchRequest")).
+					Return(tt.mockUseCaseErr).Once()
+			}
+
+			req := httptest.NewRequest(http.MethodPut, "/admin/merch/hat", strings.NewReader(tt.requestBody))
+
+			r := chi.NewRouter()
+			r.Put("/admin/merch/{name}", handler.UpdateMerch)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestListMerch(t *testing.T) {
+	t.Parallel()
+
+	mockUseCase := new(mocks.UseCase)
+	handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+	query := domain.CatalogQuery{Category: "clothing", MinPrice: 10, MaxPrice: 100, Sort: "-price"}
+	mockUseCase.On("ListMerch", mock.Anything, query).Return(domain.CatalogPage{
+		Items: []domain.CatalogItem{{Name: "t-shirt", Category: "clothing", Price: 80, Available: true}},
+	}, nil).Twice()
+
+	target := "/merch?category=clothing&minPrice=10&maxPrice=100&sort=-price"
+
+	rec := httptest.NewRecorder()
+	handler.ListMerch(rec, httptest.NewRequest(http.MethodGet, target, nil))
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	etag := rec.Header().Get("ETag")
+	assert.NotEmpty(t, etag)
+
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.Header.Set("If-None-Match", etag)
+
+	rec = httptest.NewRecorder()
+	handler.ListMerch(rec, req)
+
+	assert.Equal(t, http.StatusNotModified, rec.Code)
+	assert.Empty(t, rec.Body.Bytes())
+
+	for _, target := range []string{
+		"/merch?sort=popularity",
+		"/merch?minPrice=abc",
+		"/merch?minPrice=100&maxPrice=10",
+		"/merch?minPrice=2147483648",
+		"/merch?maxPrice=2147483648",
+		"/merch?limit=1000",
+	} {
+		rec = httptest.NewRecorder()
+		handler.ListMerch(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+	}
+
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestCreateOrder(t *testing.T) {
+	t.Parallel()
+
+	mockUseCase := new(mocks.UseCase)
+	handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+	body := domain.OrderRequest{Items: []domain.OrderLine{{Item: "cup", Quantity: 2}, {Item: "mug", Quantity: 1}}}
+	mockUseCase.On("CreateOrder", mock.Anything, uint64(1), body).Return(domain.Order{}, &usecase.OrderError{
+		Lines: []domain.OrderLineError{{Item: "mug", Quantity: 1, Reason: domain.LineNotFound}},
+	}).Once()
+
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Failed to marshal request body: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/orders", bytes.NewBuffer(reqBody))
+	req.Header.Set("Authorization", "Bearer valid_token")
+
+	r := chi.NewRouter()
+	r.With(mockJWTMiddleware).Post("/orders", handler.CreateOrder)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{
+		"error": "order cannot be fulfilled",
+		"details": [{"item": "mug", "quantity": 1, "reason": "not_found"}]
+	}`, rec.Body.String())
+
+	req = httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(`{"items": [{"item": "cup", "quantity": 0}]}`))
+	req.Header.Set("Authorization", "Bearer valid_token")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestReturnOrder(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name           string
+		orderID        string
+		requestBody    string
+		expectItems    []string
+		mockUseCaseErr error
+		expectedStatus int
+		expectMockCall bool
+	}{
+		{
+			name:           "Return whole order without body",
+			orderID:        "7",
+			expectedStatus: http.StatusOK,
+			expectMockCall: true,
+		},
+		{
+			name:           "Return selected items",
+			orderID:        "7",
+			requestBody:    `{"items": ["cup"]}`,
+			expectItems:    []string{"cup"},
+			expectedStatus: http.StatusOK,
+			expectMockCall: true,
+		},
+		{
+			name:           "Window expired",
+			orderID:        "7",
+			mockUseCaseErr: usecase.ErrReturnWindowExpired,
+			expectedStatus: http.StatusConflict,
+			expectMockCall: true,
+		},
+		{
+			name:           "Invalid order id",
+			orderID:        "abc",
+			expectedStatus: http.StatusBadRequest,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockUseCase := new(mocks.UseCase)
+			handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+			if tt.expectMockCall {
+				mockUseCase.On("ReturnOrder", mock.Anything, uint64(1), uint64(7), domain.ReturnRequest{Items: tt.expectItems}).
+					Return(domain.Refund{OrderID: 7, Amount: 20}, tt.mockUseCaseErr).Once()
+			}
+
+			req := httptest.NewRequest(http.MethodPost, "/orders/"+tt.orderID+"/return", strings.NewReader(tt.requestBody))
+			req.Header.Set("Authorization", "Bearer valid_token")
+
+			r := chi.NewRouter()
+			r.With(mockJWTMiddleware).Post("/orders/{id}/return", handler.ReturnOrder)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHistory(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name           string
+		query          string
+		expectQuery    domain.HistoryQuery
+		expectedStatus int
+	}{
+		{
+			name:  "All filters",
+			query: "direction=received&counterparty=bob&from=2025-01-01T00:00:00Z&to=2025-02-01T00:00:00Z&minAmount=5&maxAmount=50&limit=10",
+			expectQuery: domain.HistoryQuery{
+				Direction:    domain.DirectionReceived,
+				Counterparty: "bob",
+				From:         &from,
+				To:           &to,
+				MinAmount:    5,
+				MaxAmount:    50,
+				Limit:        10,
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Unknown direction",
+			query:          "direction=both",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Inverted date range",
+			query:          "from=2025-02-01T00:00:00Z&to=2025-01-01T00:00:00Z",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Inverted amount range",
+			query:          "minAmount=50&maxAmount=5",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Largest amount",
+			query:          "minAmount=2147483647&maxAmount=2147483647",
+			expectQuery:    domain.HistoryQuery{MinAmount: 2147483647, MaxAmount: 2147483647},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Min amount out of range",
+			query:          "minAmount=2147483648",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Max amount out of range",
+			query:          "maxAmount=2147483648",
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Amount overflows uint64",
+			query:          "maxAmount=18446744073709551616",
+			expectedStatus: http.StatusBadRequest,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockUseCase := new(mocks.UseCase)
+			handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+			if tt.expectedStatus == http.StatusOK {
+				mockUseCase.On("ListHistory", mock.Anything, uint64(1), tt.expectQuery).
+					Return(domain.HistoryPage{Items: []domain.HistoryEntry{}}, nil).Once()
+			}
+
+			req := httptest.NewRequest(http.MethodGet, "/history?"+tt.query, nil)
+			req.Header.Set("Authorization", "Bearer valid_token")
+
+			r := chi.NewRouter()
+			r.With(mockJWTMiddleware).Get("/history", handler.History)
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+
+			mockUseCase.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGrantCoins(t *testing.T) {
+	t.Parallel()
+
+	mockUseCase := new(mocks.UseCase)
+	handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+	r := chi.NewRouter()
+	r.With(mockJWTMiddleware).Post("/admin/grants", handler.GrantCoins)
+
+	csvBody := domain.GrantRequest{
+		Reason: "hackathon",
+		Grants: []domain.GrantLine{{Username: "alice", Amount: 500}, {Username: "bob", Amount: 300}},
+		DryRun: true,
+	}
+	mockUseCase.On("GrantCoins", mock.Anything, csvBody).Return(domain.GrantResult{
+		DryRun:     true,
+		Recipients: 2,
+		Total:      800,
+		Grants:     csvBody.Grants,
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/admin/grants?reason=hackathon&dryRun=true",
+		strings.NewReader("username,amount\nalice,500\nbob, 300\n"))
+	req.Header.Set("Authorization", "Bearer valid_token")
+	req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"dryRun": true,
+		"recipients": 2,
+		"total": 800,
+		"grants": [{"username": "alice", "amount": 500}, {"username": "bob", "amount": 300}]
+	}`, rec.Body.String())
+
+	jsonBody := domain.GrantRequest{
+		Reason: "birthday",
+		Grants: []domain.GrantLine{{Username: "carol", Amount: 100}},
+	}
+	mockUseCase.On("GrantCoins", mock.Anything, jsonBody).Return(domain.GrantResult{}, &usecase.GrantError{
+		Lines: []domain.GrantLineError{{Username: "carol", Reason: domain.LineNotFound}},
+	}).Once()
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/grants",
+		strings.NewReader(`{"reason": "birthday", "grants": [{"username": "carol", "amount": 100}]}`))
+	req.Header.Set("Authorization", "Bearer valid_token")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.JSONEq(t, `{
+		"error": "grant cannot be applied",
+		"details": [{"username": "carol", "reason": "not_found"}]
+	}`, rec.Body.String())
+
+	for _, body := range []string{
+		`{"grants": [{"username": "carol", "amount": 100}]}`,
+		`{"reason": "birthday", "grants": []}`,
+		`{"reason": "birthday", "grants": [{"username": "carol", "amount": 0}]}`,
+	} {
+		req = httptest.NewRequest(http.MethodPost, "/admin/grants", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer valid_token")
+
+		rec = httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code, body)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/admin/grants?reason=x", strings.NewReader("alice,many\n"))
+	req.Header.Set("Authorization", "Bearer valid_token")
+	req.Header.Set("Content-Type", "text/csv")
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestSendCoinLimit(t *testing.T) {
+	t.Parallel()
+
+	mockUseCase := new(mocks.UseCase)
+	handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+	body := domain.SendCoinRequest{ToUser: "recipient", Amount: 300}
+	mockUseCase.On("SendCoin", mock.Anything, uint64(1), body).Return(nil, &usecase.LimitError{
+		Limit:      usecase.LimitHourlyCount,
+		Max:        5,
+		RetryAfter: 90 * time.Second,
+	}).Once()
+
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Failed to marshal request body: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/sendCoin", bytes.NewBuffer(reqBody))
+	req.Header.Set("Authorization", "Bearer valid_token")
+
+	r := chi.NewRouter()
+	r.With(mockJWTMiddleware).Post("/sendCoin", handler.SendCoin)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Equal(t, "90", rec.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{
+		"error": "transfer limit exceeded",
+		"details": {"limit": "hourly_count", "max": 5, "remaining": 0}
+	}`, rec.Body.String())
+
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestSendCoinPendingApproval(t *testing.T) {
+	t.Parallel()
+
+	mockUseCase := new(mocks.UseCase)
+	handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+	expiresAt := time.Date(2025, 3, 4, 12, 0, 0, 0, time.UTC)
+	body := domain.SendCoinRequest{ToUser: "recipient", Amount: 800}
+	mockUseCase.On("SendCoin", mock.Anything, uint64(1), body).Return(&domain.PendingTransfer{
+		ID:        7,
+		FromUser:  "sender",
+		ToUser:    "recipient",
+		Amount:    800,
+		Status:    domain.TransferPending,
+		ExpiresAt: expiresAt,
+		CreatedAt: expiresAt.Add(-72 * time.Hour),
+	}, nil).Once()
+
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("Failed to marshal request body: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/sendCoin", bytes.NewBuffer(reqBody))
+	req.Header.Set("Authorization", "Bearer valid_token")
+
+	r := chi.NewRouter()
+	r.With(mockJWTMiddleware).Post("/sendCoin", handler.SendCoin)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+	assert.JSONEq(t, `{
+		"id": 7,
+		"fromUser": "sender",
+		"toUser": "recipient",
+		"amount": 800,
+		"status": "pending",
+		"expiresAt": "2025-03-04T12:00:00Z",
+		"createdAt": "2025-03-01T12:00:00Z"
+	}`, rec.Body.String())
+
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestDecideTransfer(t *testing.T) {
+	t.Parallel()
+
+	mockUseCase := new(mocks.UseCase)
+	handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+	withRole := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(shopcontext.WithRole(r.Context(), domain.RoleUser)))
+		})
+	}
+
+	r := chi.NewRouter()
+	r.With(mockJWTMiddleware, withRole).Post("/approvals/{id}/approve", handler.ApproveTransfer)
+	r.With(mockJWTMiddleware, withRole).Post("/approvals/{id}/reject", handler.RejectTransfer)
+
+	mockUseCase.On("DecideTransfer", mock.Anything, domain.TransferDecision{
+		TransferID: 7, ApproverID: 1, Role: domain.RoleUser, Approve: true,
+	}).Return(domain.PendingTransfer{ID: 7, Status: domain.TransferApproved}, nil).Once()
+	mockUseCase.On("DecideTransfer", mock.Anything, domain.TransferDecision{
+		TransferID: 8, ApproverID: 1, Role: domain.RoleUser, Approve: false,
+	}).Return(domain.PendingTransfer{}, usecase.ErrNotApprover).Once()
+
+	for _, tt := range []struct {
+		path           string
+		expectedStatus int
+	}{
+		{path: "/approvals/7/approve", expectedStatus: http.StatusOK},
+		{path: "/approvals/8/reject", expectedStatus: http.StatusForbidden},
+		{path: "/approvals/abc/approve", expectedStatus: http.StatusBadRequest},
+	} {
+		req := httptest.NewRequest(http.MethodPost, tt.path, nil)
+		req.Header.Set("Authorization", "Bearer valid_token")
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, tt.expectedStatus, rec.Code, tt.path)
+	}
+
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestCoinRequests(t *testing.T) {
+	t.Parallel()
+
+	mockUseCase := new(mocks.UseCase)
+	handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+	r := chi.NewRouter()
+	r.With(mockJWTMiddleware).Post("/coin-requests", handler.CreateCoinRequest)
+	r.With(mockJWTMiddleware).Get("/coin-requests", handler.ListCoinRequests)
+	r.With(mockJWTMiddleware).Post("/coin-requests/{id}/accept", handler.AcceptCoinRequest)
+	r.With(mockJWTMiddleware).Post("/coin-requests/{id}/decline", handler.DeclineCoinRequest)
+
+	mockUseCase.On("CreateCoinRequest", mock.Anything, uint64(1), domain.CreateCoinRequest{
+		Payer: "ivanov", Amount: 50, Memo: "за пиццу",
+	}).Return(domain.CoinRequest{ID: 3, Requester: "petrov", Payer: "ivanov", Amount: 50, Status: domain.CoinRequestPending}, nil).Once()
+	mockUseCase.On("ListCoinRequests", mock.Anything, uint64(1), domain.CoinRequestQuery{
+		Box: domain.CoinRequestOutbox, Status: domain.CoinRequestPending,
+	}).Return(domain.CoinRequestPage{Requests: []domain.CoinRequest{}}, nil).Once()
+	mockUseCase.On("AcceptCoinRequest", mock.Anything, uint64(1), uint64(3)).
+		Return(domain.CoinRequest{}, usecase.ErrCoinRequestDecided).Once()
+	mockUseCase.On("DeclineCoinRequest", mock.Anything, uint64(1), uint64(4)).
+		Return(domain.CoinRequest{ID: 4, Status: domain.CoinRequestDeclined}, nil).Once()
+
+	for _, tt := range []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{method: http.MethodPost, path: "/coin-requests", body: `{"payer":"ivanov","amount":50,"memo":"за пиццу"}`, expectedStatus: http.StatusCreated},
+		{method: http.MethodPost, path: "/coin-requests", body: `{"payer":"ivanov"}`, expectedStatus: http.StatusBadRequest},
+		{method: http.MethodGet, path: "/coin-requests?box=outbox&status=pending", expectedStatus: http.StatusOK},
+		{method: http.MethodGet, path: "/coin-requests?box=spam", expectedStatus: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/coin-requests/3/accept", expectedStatus: http.StatusConflict},
+		{method: http.MethodPost, path: "/coin-requests/4/decline", expectedStatus: http.StatusOK},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Authorization", "Bearer valid_token")
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, tt.expectedStatus, rec.Code, tt.path)
+	}
+
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestScheduledTransfers(t *testing.T) {
+	t.Parallel()
+
+	mockUseCase := new(mocks.UseCase)
+	handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+	r := chi.NewRouter()
+	r.With(mockJWTMiddleware).Post("/scheduled-transfers", handler.CreateScheduledTransfer)
+	r.With(mockJWTMiddleware).Delete("/scheduled-transfers/{id}", handler.DeleteScheduledTransfer)
+
+	startAt := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+	mockUseCase.On("CreateScheduledTransfer", mock.Anything, uint64(1), domain.ScheduledTransferRequest{
+		ToUser: "intern", Amount: 20, Repeat: domain.RepeatWeekly, StartAt: startAt,
+	}).Return(domain.ScheduledTransfer{ID: 5, ToUser: "intern", Amount: 20, Repeat: domain.RepeatWeekly, NextRunAt: startAt, Active: true}, nil).Once()
+	mockUseCase.On("DeleteScheduledTransfer", mock.Anything, uint64(1), uint64(6)).
+		Return(usecase.ErrScheduleNotFound).Once()
+
+	for _, tt := range []struct {
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+	}{
+		{
+			method:         http.MethodPost,
+			path:           "/scheduled-transfers",
+			body:           `{"toUser":"intern","amount":20,"repeat":"weekly","startAt":"2025-03-03T09:00:00Z"}`,
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			method:         http.MethodPost,
+			path:           "/scheduled-transfers",
+			body:           `{"toUser":"intern","amount":20,"repeat":"hourly"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{method: http.MethodDelete, path: "/scheduled-transfers/6", expectedStatus: http.StatusNotFound},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Authorization", "Bearer valid_token")
+
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+
+		assert.Equal(t, tt.expectedStatus, rec.Code, tt.path)
+	}
+
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestBatchSendCoin(t *testing.T) {
+	t.Parallel()
+
+	mockUseCase := new(mocks.UseCase)
+	handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+	mockUseCase.On("BatchSendCoin", mock.Anything, uint64(1), domain.BatchSendCoinRequest{
+		Transfers: []domain.SendCoinRequest{{ToUser: "ivanov", Amount: 30}, {ToUser: "nobody", Amount: 20}},
+	}).Return(domain.BatchResult{}, &usecase.BatchError{
+		Lines: []domain.BatchLineError{{ToUser: "nobody", Amount: 20, Reason: domain.LineNotFound}},
+	}).Once()
+
+	req := httptest.NewRequest(http.MethodPost, "/sendCoin/batch", strings.NewReader(
+		`{"transfers":[{"toUser":"ivanov","amount":30},{"toUser":"nobody","amount":20}]}`))
+	req.Header.Set("Authorization", "Bearer valid_token")
+
+	r := chi.NewRouter()
+	r.With(mockJWTMiddleware).Post("/sendCoin/batch", handler.BatchSendCoin)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.Contains(t, rec.Body.String(), `{"toUser":"nobody","amount":20,"reason":"not_found"}`)
+
+	mockUseCase.AssertExpectations(t)
+}
+
+func TestExpiringCoins(t *testing.T) {
+	t.Parallel()
+
+	mockUseCase := new(mocks.UseCase)
+	handler := &HTTPHandler{useCase: mockUseCase, validate: validator.New()}
+
+	mockUseCase.On("PreviewExpiry", mock.Anything, uint64(1)).Return(domain.ExpiryPreview{
+		Enabled:  true,
+		Total:    250,
+		Expiring: []domain.ExpiringCoins{{Amount: 250, ExpiresAt: time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)}},
+	}, nil).Once()
+
+	req := httptest.NewRequest(http.MethodGet, "/coins/expiring", nil)
+	req.Header.Set("Authorization", "Bearer valid_token")
+
+	r := chi.NewRouter()
+	r.With(mockJWTMiddleware).Get("/coins/expiring", handler.ExpiringCoins)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{
+		"enabled": true,
+		"total": 250,
+		"expiring": [{"amount": 250, "expiresAt": "2025-04-10T12:00:00Z"}]
+	}`, rec.Body.String())
+
+	mockUseCase.AssertExpectations(t)
+}
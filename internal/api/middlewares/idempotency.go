@@ -0,0 +1,148 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"merch-shop/internal/api/apierror"
+	shopcontext "merch-shop/internal/api/context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/idempotency"
+	"merch-shop/internal/usecase"
+	"net/http"
+	"time"
+)
+
+const (
+	idempotencyHeader = "Idempotency-Key"
+	replayedHeader    = "Idempotent-Replayed"
+	maxKeyLength      = 255
+	// maxBodySize с запасом вмещает самый крупный запрос с ключом — список из 1000 начислений
+	maxBodySize = 1 << 20
+)
+
+//go:generate mockery --name=IdempotencyStore --output=./mocks --filename=idempotency_store.go --structname=IdempotencyStore
+type IdempotencyStore interface {
+	GetIdempotentResponse(ctx context.Context, userID uint64, key string) (domain.IdempotentResponse, error)
+	SaveIdempotentResponse(ctx context.Context, userID uint64, key string, status int, body []byte) error
+}
+
+// Idempotency повторяет сохранённый ответ для запроса с уже использованным
+// ключом. Сам ключ резервирует репозиторий в транзакции операции, поэтому
+// неуспешный запрос ключ не занимает и его можно повторить.
+func (m *Middlewares) Idempotency(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(idempotencyHeader)
+		if key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if len(key) > maxKeyLength {
+			apierror.WriteError(w, apierror.ErrInvalidRequest)
+			return
+		}
+
+		userID, ok := shopcontext.UserID(r.Context())
+		if !ok {
+			apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodySize))
+		if err != nil {
+			var maxBytesErr *http.MaxBytesError
+			if errors.As(err, &maxBytesErr) {
+				apierror.WriteError(w, apierror.ErrBodyTooLarge)
+				return
+			}
+			apierror.WriteError(w, apierror.ErrParsingBody)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		requestHash := hashRequest(r, body)
+
+		stored, err := m.idempotency.GetIdempotentResponse(r.Context(), userID, key)
+		switch {
+		case errors.Is(err, usecase.ErrNotFound):
+		case err != nil:
+			slog.Error("idempotency.GetIdempotentResponse", "error", err)
+			apierror.WriteError(w, err)
+			return
+		case stored.RequestHash != requestHash:
+			apierror.WriteError(w, usecase.ErrIdempotencyMismatch)
+			return
+		case stored.Status == 0:
+			apierror.WriteError(w, usecase.ErrIdempotencyConflict)
+			return
+		default:
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.Header().Set(replayedHeader, "true")
+			w.WriteHeader(stored.Status)
+			_, _ = w.Write(stored.Body)
+			return
+		}
+
+		var claimed bool
+		ctx := idempotency.WithKey(r.Context(), domain.IdempotencyKey{
+			Key:         key,
+			UserID:      userID,
+			RequestHash: requestHash,
+			ExpiresAt:   time.Now().Add(m.idempotencyTTL),
+			Claimed:     &claimed,
+		})
+
+		rec := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		// Ответ сохраняет только запрос, чья операция зарезервировала ключ; ответ
+		// параллельного повтора (409, 400) не должен заменить ответ на саму операцию
+		if !claimed {
+			return
+		}
+
+		// Ответ сохраняется, даже если клиент уже отключился и контекст запроса отменён.
+		// Ключ при ошибке не освобождается: операция уже выполнена, и до конца TTL
+		// повторы получат 409, а не выполнят её второй раз
+		ctx = context.WithoutCancel(r.Context())
+		if err = m.idempotency.SaveIdempotentResponse(ctx, userID, key, rec.status, rec.body.Bytes()); err != nil {
+			slog.Error("idempotency.SaveIdempotentResponse", "error", err)
+		}
+	})
+}
+
+// Ключ привязан к конкретной операции: тот же ключ с другим путём, параметрами
+// или телом — ошибка клиента. Параметры сортируются, их порядок не важен
+func hashRequest(r *http.Request, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(r.Method))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Path))
+	h.Write([]byte{0})
+	h.Write([]byte(r.URL.Query().Encode()))
+	h.Write([]byte{0})
+	h.Write(body)
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,202 @@
+package middlewares
+
+import (
+	"errors"
+	shopcontext "merch-shop/internal/api/context"
+	"merch-shop/internal/api/middlewares/mocks"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/idempotency"
+	"merch-shop/internal/usecase"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestIdempotency(t *testing.T) {
+	t.Parallel()
+
+	const body = `{"toUser": "bob", "amount": 10}`
+
+	newRequest := func(target, key, body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(idempotencyHeader, key)
+		return req.WithContext(shopcontext.WithUserID(req.Context(), 1))
+	}
+
+	requestHash := hashRequest(newRequest("/api/sendCoin", "key", body), []byte(body))
+
+	for _, tt := range []struct {
+		name           string
+		target         string
+		key            string
+		body           string
+		stored         domain.IdempotentResponse
+		storedErr      error
+		saveErr        error
+		expectLookup   bool
+		claim          bool
+		expectedStatus int
+		expectedBody   string
+		expectHandler  bool
+		expectReplay   bool
+	}{
+		{
+			name:           "Request without key",
+			body:           body,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{}\n",
+			expectHandler:  true,
+		},
+		{
+			name:           "First request with key",
+			key:            "key",
+			body:           body,
+			storedErr:      usecase.ErrNotFound,
+			expectLookup:   true,
+			claim:          true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{}\n",
+			expectHandler:  true,
+		},
+		{
+			// Ключ занял параллельный запрос: его ответ не должен быть перезаписан
+			name:           "Response not saved without claim",
+			key:            "key",
+			body:           body,
+			storedErr:      usecase.ErrNotFound,
+			expectLookup:   true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{}\n",
+			expectHandler:  true,
+		},
+		{
+			// Операция выполнена, поэтому ключ остаётся занятым и без ответа
+			name:           "Key kept when response is not saved",
+			key:            "key",
+			body:           body,
+			storedErr:      usecase.ErrNotFound,
+			saveErr:        errors.New("DB error"),
+			expectLookup:   true,
+			claim:          true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{}\n",
+			expectHandler:  true,
+		},
+		{
+			name:           "Duplicate request is replayed",
+			key:            "key",
+			body:           body,
+			stored:         domain.IdempotentResponse{RequestHash: requestHash, Status: http.StatusOK, Body: []byte("{}\n")},
+			expectLookup:   true,
+			expectedStatus: http.StatusOK,
+			expectedBody:   "{}\n",
+			expectReplay:   true,
+		},
+		{
+			name:           "Key reused with different body",
+			key:            "key",
+			body:           `{"toUser": "bob", "amount": 20}`,
+			stored:         domain.IdempotentResponse{RequestHash: requestHash, Status: http.StatusOK},
+			expectLookup:   true,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Key reused with different query",
+			target:         "/api/sendCoin?dryRun=true",
+			key:            "key",
+			body:           body,
+			stored:         domain.IdempotentResponse{RequestHash: requestHash, Status: http.StatusOK},
+			expectLookup:   true,
+			expectedStatus: http.StatusUnprocessableEntity,
+		},
+		{
+			name:           "Body too large",
+			key:            "key",
+			body:           strings.Repeat(" ", maxBodySize+1),
+			expectedStatus: http.StatusRequestEntityTooLarge,
+		},
+		{
+			name:           "Original request still in progress",
+			key:            "key",
+			body:           body,
+			stored:         domain.IdempotentResponse{RequestHash: requestHash},
+			expectLookup:   true,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Store failure",
+			key:            "key",
+			body:           body,
+			storedErr:      errors.New("DB error"),
+			expectLookup:   true,
+			expectedStatus: http.StatusInternalServerError,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			store := new(mocks.IdempotencyStore)
+			m := New(nil, nil, store, time.Hour)
+
+			if tt.expectLookup {
+				store.On("GetIdempotentResponse", mock.Anything, uint64(1), tt.key).
+					Return(tt.stored, tt.storedErr).Once()
+			}
+
+			if tt.claim {
+				store.On("SaveIdempotentResponse", mock.Anything, uint64(1), tt.key, http.StatusOK, []byte("{}\n")).
+					Return(tt.saveErr).Once()
+			}
+
+			target := tt.target
+			if target == "" {
+				target = "/api/sendCoin"
+			}
+
+			var called bool
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+
+				key, ok := idempotency.FromContext(r.Context())
+				assert.Equal(t, tt.key != "", ok)
+				if ok {
+					assert.Equal(t, requestHash, key.RequestHash)
+					// Так репозиторий отмечает резерв ключа в транзакции операции
+					*key.Claimed = tt.claim
+				}
+
+				w.Header().Set("Content-Type", "application/json; charset=utf-8")
+				_, _ = w.Write([]byte("{}\n"))
+			})
+
+			rec := httptest.NewRecorder()
+			m.Idempotency(next).ServeHTTP(rec, newRequest(target, tt.key, tt.body))
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			assert.Equal(t, tt.expectHandler, called)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+			}
+			assert.Equal(t, tt.expectReplay, rec.Header().Get(replayedHeader) == "true")
+
+			store.AssertExpectations(t)
+		})
+	}
+}
+
+func TestHashRequest_QueryOrder(t *testing.T) {
+	t.Parallel()
+
+	a := httptest.NewRequest(http.MethodPost, "/api/admin/grants?reason=hackathon&dryRun=true", nil)
+	b := httptest.NewRequest(http.MethodPost, "/api/admin/grants?dryRun=true&reason=hackathon", nil)
+	c := httptest.NewRequest(http.MethodPost, "/api/admin/grants?reason=hackathon", nil)
+
+	assert.Equal(t, hashRequest(a, nil), hashRequest(b, nil))
+	assert.NotEqual(t, hashRequest(a, nil), hashRequest(c, nil))
+}
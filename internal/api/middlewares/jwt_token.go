@@ -2,13 +2,17 @@ package middlewares
 
 import (
 	"crypto/rsa"
+	"errors"
 	"fmt"
 	"github.com/golang-jwt/jwt"
+	"log/slog"
 	"merch-shop/internal/api/apierror"
 	shopcontext "merch-shop/internal/api/context"
+	"merch-shop/internal/domain"
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const authHeader = "Authorization"
@@ -27,33 +31,72 @@ func (m *Middlewares) JWTToken(next http.Handler) http.Handler {
 			return
 		}
 
-		if claims, ok := token.Claims.(jwt.MapClaims); ok {
-			userID, ok := claims["sub"]
-			if ok && userID != nil {
-				userIDStr, isString := userID.(string)
-				if !isString {
-					apierror.WriteError(w, apierror.ErrInvalidToken)
-					return
-				}
-
-				userIDUint, err := strconv.ParseUint(userIDStr, 10, 64)
-				if err != nil {
-					apierror.WriteError(w, apierror.ErrInvalidToken)
-					return
-				}
-
-				ctx := shopcontext.WithUserID(r.Context(), userIDUint)
-				r = r.WithContext(ctx)
-			}
-		} else {
+		claims, ok := token.Claims.(jwt.MapClaims)
+		if !ok {
 			apierror.WriteError(w, apierror.ErrInvalidToken)
 			return
 		}
 
+		session, err := sessionFromClaims(claims)
+		if err != nil {
+			apierror.WriteError(w, err)
+			return
+		}
+
+		if err = m.sessions.ValidateSession(r.Context(), session); err != nil {
+			slog.Error("sessions.ValidateSession", "error", err)
+			apierror.WriteError(w, err)
+			return
+		}
+
+		ctx := shopcontext.WithUserID(r.Context(), session.UserID)
+		ctx = shopcontext.WithRole(ctx, session.Role)
+		ctx = shopcontext.WithSession(ctx, session)
+		r = r.WithContext(ctx)
+
 		next.ServeHTTP(w, r)
 	})
 }
 
+func sessionFromClaims(claims jwt.MapClaims) (domain.Session, error) {
+	userIDStr, ok := claims["sub"].(string)
+	if !ok {
+		return domain.Session{}, apierror.ErrInvalidToken
+	}
+
+	userID, err := strconv.ParseUint(userIDStr, 10, 64)
+	if err != nil {
+		return domain.Session{}, apierror.ErrInvalidToken
+	}
+
+	tokenID, ok := claims["jti"].(string)
+	if !ok || tokenID == "" {
+		return domain.Session{}, apierror.ErrInvalidToken
+	}
+
+	// Числовые claims после разбора JSON приходят как float64
+	expiresAt, _ := claims["exp"].(float64)
+	generation, _ := claims["gen"].(float64)
+
+	// Токены, выпущенные до появления ролей, считаются пользовательскими
+	role := domain.RoleUser
+	if claimRole, ok := claims["role"].(string); ok && claimRole != "" {
+		role = domain.Role(claimRole)
+	}
+
+	if !role.Valid() {
+		return domain.Session{}, apierror.ErrInvalidToken
+	}
+
+	return domain.Session{
+		UserID:     userID,
+		Role:       role,
+		TokenID:    tokenID,
+		Generation: uint64(generation),
+		ExpiresAt:  time.Unix(int64(expiresAt), 0),
+	}, nil
+}
+
 func getToken(key *rsa.PublicKey, tokenString string) (*jwt.Token, error) {
 	token, err := jwt.Parse(
 		tokenString,
@@ -66,6 +109,10 @@ func getToken(key *rsa.PublicKey, tokenString string) (*jwt.Token, error) {
 		},
 	)
 	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorExpired != 0 {
+			return nil, apierror.ErrTokenExpired
+		}
 		return nil, apierror.ErrParsingToken
 	}
 
@@ -73,6 +120,12 @@ func getToken(key *rsa.PublicKey, tokenString string) (*jwt.Token, error) {
 		return nil, apierror.ErrInvalidToken
 	}
 
+	// Токены без срока жизни выпускались до появления exp и больше не принимаются
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, apierror.ErrInvalidToken
+	}
+
 	return token, nil
 }
 
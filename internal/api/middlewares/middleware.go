@@ -1,15 +1,33 @@
 package middlewares
 
-import "crypto/rsa"
+import (
+	"context"
+	"crypto/rsa"
+	"merch-shop/internal/domain"
+	"time"
+)
+
+type SessionValidator interface {
+	ValidateSession(ctx context.Context, session domain.Session) error
+}
 
 type Middlewares struct {
-	publicKey *rsa.PublicKey
+	publicKey      *rsa.PublicKey
+	sessions       SessionValidator
+	idempotency    IdempotencyStore
+	idempotencyTTL time.Duration
 }
 
 func New(
 	publicKey *rsa.PublicKey,
+	sessions SessionValidator,
+	idempotency IdempotencyStore,
+	idempotencyTTL time.Duration,
 ) *Middlewares {
 	return &Middlewares{
-		publicKey: publicKey,
+		publicKey:      publicKey,
+		sessions:       sessions,
+		idempotency:    idempotency,
+		idempotencyTTL: idempotencyTTL,
 	}
 }
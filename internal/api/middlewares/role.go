@@ -0,0 +1,31 @@
+package middlewares
+
+import (
+	"merch-shop/internal/api/apierror"
+	shopcontext "merch-shop/internal/api/context"
+	"merch-shop/internal/domain"
+	"net/http"
+)
+
+// RequireRole пропускает запрос, только если роль из токена входит в список.
+// Должен стоять после JWTToken, который кладёт роль в контекст.
+func (m *Middlewares) RequireRole(roles ...domain.Role) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			role, ok := shopcontext.Role(r.Context())
+			if !ok {
+				apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+				return
+			}
+
+			for _, allowed := range roles {
+				if role == allowed {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			apierror.WriteError(w, apierror.ErrForbidden)
+		})
+	}
+}
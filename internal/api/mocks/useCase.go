@@ -14,6 +14,48 @@ type UseCase struct {
 	mock.Mock
 }
 
+// AcceptCoinRequest provides a mock function with given fields: ctx, payerID, requestID
+func (_m *UseCase) AcceptCoinRequest(ctx context.Context, payerID uint64, requestID uint64) (domain.CoinRequest, error) {
+	ret := _m.Called(ctx, payerID, requestID)
+
+	var r0 domain.CoinRequest
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) domain.CoinRequest); ok {
+		r0 = rf(ctx, payerID, requestID)
+	} else {
+		r0 = ret.Get(0).(domain.CoinRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64) error); ok {
+		r1 = rf(ctx, payerID, requestID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BatchSendCoin provides a mock function with given fields: ctx, fromUserID, req
+func (_m *UseCase) BatchSendCoin(ctx context.Context, fromUserID uint64, req domain.BatchSendCoinRequest) (domain.BatchResult, error) {
+	ret := _m.Called(ctx, fromUserID, req)
+
+	var r0 domain.BatchResult
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.BatchSendCoinRequest) domain.BatchResult); ok {
+		r0 = rf(ctx, fromUserID, req)
+	} else {
+		r0 = ret.Get(0).(domain.BatchResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.BatchSendCoinRequest) error); ok {
+		r1 = rf(ctx, fromUserID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // BuyMerch provides a mock function with given fields: ctx, userID, itemName
 func (_m *UseCase) BuyMerch(ctx context.Context, userID uint64, itemName string) error {
 	ret := _m.Called(ctx, userID, itemName)
@@ -49,17 +91,385 @@ func (_m *UseCase) CheckCredentials(ctx context.Context, creds domain.Credential
 	return r0, r1
 }
 
-// GetInfo provides a mock function with given fields: ctx, userID
-func (_m *UseCase) GetInfo(ctx context.Context, userID uint64) (domain.Info, error) {
+// CreateCoinRequest provides a mock function with given fields: ctx, requesterID, req
+func (_m *UseCase) CreateCoinRequest(ctx context.Context, requesterID uint64, req domain.CreateCoinRequest) (domain.CoinRequest, error) {
+	ret := _m.Called(ctx, requesterID, req)
+
+	var r0 domain.CoinRequest
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.CreateCoinRequest) domain.CoinRequest); ok {
+		r0 = rf(ctx, requesterID, req)
+	} else {
+		r0 = ret.Get(0).(domain.CoinRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.CreateCoinRequest) error); ok {
+		r1 = rf(ctx, requesterID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateInvite provides a mock function with given fields: ctx, userID
+func (_m *UseCase) CreateInvite(ctx context.Context, userID uint64) (domain.Invite, error) {
 	ret := _m.Called(ctx, userID)
 
-	var r0 domain.Info
-	if rf, ok := ret.Get(0).(func(context.Context, uint64) domain.Info); ok {
+	var r0 domain.Invite
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) domain.Invite); ok {
 		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(domain.Invite)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateMerch provides a mock function with given fields: ctx, req
+func (_m *UseCase) CreateMerch(ctx context.Context, req domain.CreateMerchRequest) (domain.Merch, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 domain.Merch
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CreateMerchRequest) domain.Merch); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(domain.Merch)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.CreateMerchRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateOrder provides a mock function with given fields: ctx, userID, req
+func (_m *UseCase) CreateOrder(ctx context.Context, userID uint64, req domain.OrderRequest) (domain.Order, error) {
+	ret := _m.Called(ctx, userID, req)
+
+	var r0 domain.Order
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.OrderRequest) domain.Order); ok {
+		r0 = rf(ctx, userID, req)
+	} else {
+		r0 = ret.Get(0).(domain.Order)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.OrderRequest) error); ok {
+		r1 = rf(ctx, userID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateScheduledTransfer provides a mock function with given fields: ctx, ownerID, req
+func (_m *UseCase) CreateScheduledTransfer(ctx context.Context, ownerID uint64, req domain.ScheduledTransferRequest) (domain.ScheduledTransfer, error) {
+	ret := _m.Called(ctx, ownerID, req)
+
+	var r0 domain.ScheduledTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.ScheduledTransferRequest) domain.ScheduledTransfer); ok {
+		r0 = rf(ctx, ownerID, req)
+	} else {
+		r0 = ret.Get(0).(domain.ScheduledTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.ScheduledTransferRequest) error); ok {
+		r1 = rf(ctx, ownerID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DecideTransfer provides a mock function with given fields: ctx, decision
+func (_m *UseCase) DecideTransfer(ctx context.Context, decision domain.TransferDecision) (domain.PendingTransfer, error) {
+	ret := _m.Called(ctx, decision)
+
+	var r0 domain.PendingTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TransferDecision) domain.PendingTransfer); ok {
+		r0 = rf(ctx, decision)
+	} else {
+		r0 = ret.Get(0).(domain.PendingTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.TransferDecision) error); ok {
+		r1 = rf(ctx, decision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeclineCoinRequest provides a mock function with given fields: ctx, payerID, requestID
+func (_m *UseCase) DeclineCoinRequest(ctx context.Context, payerID uint64, requestID uint64) (domain.CoinRequest, error) {
+	ret := _m.Called(ctx, payerID, requestID)
+
+	var r0 domain.CoinRequest
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) domain.CoinRequest); ok {
+		r0 = rf(ctx, payerID, requestID)
+	} else {
+		r0 = ret.Get(0).(domain.CoinRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64) error); ok {
+		r1 = rf(ctx, payerID, requestID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteScheduledTransfer provides a mock function with given fields: ctx, ownerID, transferID
+func (_m *UseCase) DeleteScheduledTransfer(ctx context.Context, ownerID uint64, transferID uint64) error {
+	ret := _m.Called(ctx, ownerID, transferID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) error); ok {
+		r0 = rf(ctx, ownerID, transferID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetInfo provides a mock function with given fields: ctx, userID, historyLimit
+func (_m *UseCase) GetInfo(ctx context.Context, userID uint64, historyLimit int) (domain.Info, error) {
+	ret := _m.Called(ctx, userID, historyLimit)
+
+	var r0 domain.Info
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, int) domain.Info); ok {
+		r0 = rf(ctx, userID, historyLimit)
 	} else {
 		r0 = ret.Get(0).(domain.Info)
 	}
 
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, int) error); ok {
+		r1 = rf(ctx, userID, historyLimit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GrantCoins provides a mock function with given fields: ctx, req
+func (_m *UseCase) GrantCoins(ctx context.Context, req domain.GrantRequest) (domain.GrantResult, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 domain.GrantResult
+	if rf, ok := ret.Get(0).(func(context.Context, domain.GrantRequest) domain.GrantResult); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(domain.GrantResult)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.GrantRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListApprovals provides a mock function with given fields: ctx, userID, role
+func (_m *UseCase) ListApprovals(ctx context.Context, userID uint64, role domain.Role) ([]domain.PendingTransfer, error) {
+	ret := _m.Called(ctx, userID, role)
+
+	var r0 []domain.PendingTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.Role) []domain.PendingTransfer); ok {
+		r0 = rf(ctx, userID, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PendingTransfer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.Role) error); ok {
+		r1 = rf(ctx, userID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCoinRequests provides a mock function with given fields: ctx, userID, query
+func (_m *UseCase) ListCoinRequests(ctx context.Context, userID uint64, query domain.CoinRequestQuery) (domain.CoinRequestPage, error) {
+	ret := _m.Called(ctx, userID, query)
+
+	var r0 domain.CoinRequestPage
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.CoinRequestQuery) domain.CoinRequestPage); ok {
+		r0 = rf(ctx, userID, query)
+	} else {
+		r0 = ret.Get(0).(domain.CoinRequestPage)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.CoinRequestQuery) error); ok {
+		r1 = rf(ctx, userID, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListHistory provides a mock function with given fields: ctx, userID, query
+func (_m *UseCase) ListHistory(ctx context.Context, userID uint64, query domain.HistoryQuery) (domain.HistoryPage, error) {
+	ret := _m.Called(ctx, userID, query)
+
+	var r0 domain.HistoryPage
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.HistoryQuery) domain.HistoryPage); ok {
+		r0 = rf(ctx, userID, query)
+	} else {
+		r0 = ret.Get(0).(domain.HistoryPage)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.HistoryQuery) error); ok {
+		r1 = rf(ctx, userID, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMerch provides a mock function with given fields: ctx, query
+func (_m *UseCase) ListMerch(ctx context.Context, query domain.CatalogQuery) (domain.CatalogPage, error) {
+	ret := _m.Called(ctx, query)
+
+	var r0 domain.CatalogPage
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CatalogQuery) domain.CatalogPage); ok {
+		r0 = rf(ctx, query)
+	} else {
+		r0 = ret.Get(0).(domain.CatalogPage)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.CatalogQuery) error); ok {
+		r1 = rf(ctx, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOrders provides a mock function with given fields: ctx, userID, query
+func (_m *UseCase) ListOrders(ctx context.Context, userID uint64, query domain.OrderQuery) (domain.OrderPage, error) {
+	ret := _m.Called(ctx, userID, query)
+
+	var r0 domain.OrderPage
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.OrderQuery) domain.OrderPage); ok {
+		r0 = rf(ctx, userID, query)
+	} else {
+		r0 = ret.Get(0).(domain.OrderPage)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.OrderQuery) error); ok {
+		r1 = rf(ctx, userID, query)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListScheduledTransfers provides a mock function with given fields: ctx, ownerID
+func (_m *UseCase) ListScheduledTransfers(ctx context.Context, ownerID uint64) ([]domain.ScheduledTransfer, error) {
+	ret := _m.Called(ctx, ownerID)
+
+	var r0 []domain.ScheduledTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) []domain.ScheduledTransfer); ok {
+		r0 = rf(ctx, ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ScheduledTransfer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Login provides a mock function with given fields: ctx, creds, clientIP
+func (_m *UseCase) Login(ctx context.Context, creds domain.Credentials, clientIP string) (domain.Tokens, error) {
+	ret := _m.Called(ctx, creds, clientIP)
+
+	var r0 domain.Tokens
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Credentials, string) domain.Tokens); ok {
+		r0 = rf(ctx, creds, clientIP)
+	} else {
+		r0 = ret.Get(0).(domain.Tokens)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Credentials, string) error); ok {
+		r1 = rf(ctx, creds, clientIP)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Logout provides a mock function with given fields: ctx, session, refreshToken
+func (_m *UseCase) Logout(ctx context.Context, session domain.Session, refreshToken string) error {
+	ret := _m.Called(ctx, session, refreshToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Session, string) error); ok {
+		r0 = rf(ctx, session, refreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// PreviewExpiry provides a mock function with given fields: ctx, userID
+func (_m *UseCase) PreviewExpiry(ctx context.Context, userID uint64) (domain.ExpiryPreview, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 domain.ExpiryPreview
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) domain.ExpiryPreview); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(domain.ExpiryPreview)
+	}
+
 	var r1 error
 	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
 		r1 = rf(ctx, userID)
@@ -70,20 +480,99 @@ func (_m *UseCase) GetInfo(ctx context.Context, userID uint64) (domain.Info, err
 	return r0, r1
 }
 
-// Login provides a mock function with given fields: ctx, creds
-func (_m *UseCase) Login(ctx context.Context, creds domain.Credentials) (string, error) {
-	ret := _m.Called(ctx, creds)
+// Refresh provides a mock function with given fields: ctx, refreshToken
+func (_m *UseCase) Refresh(ctx context.Context, refreshToken string) (domain.Tokens, error) {
+	ret := _m.Called(ctx, refreshToken)
 
-	var r0 string
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Credentials) string); ok {
-		r0 = rf(ctx, creds)
+	var r0 domain.Tokens
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.Tokens); ok {
+		r0 = rf(ctx, refreshToken)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(domain.Tokens)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, domain.Credentials) error); ok {
-		r1 = rf(ctx, creds)
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Register provides a mock function with given fields: ctx, req
+func (_m *UseCase) Register(ctx context.Context, req domain.RegisterRequest) (domain.Tokens, error) {
+	ret := _m.Called(ctx, req)
+
+	var r0 domain.Tokens
+	if rf, ok := ret.Get(0).(func(context.Context, domain.RegisterRequest) domain.Tokens); ok {
+		r0 = rf(ctx, req)
+	} else {
+		r0 = ret.Get(0).(domain.Tokens)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.RegisterRequest) error); ok {
+		r1 = rf(ctx, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestockMerch provides a mock function with given fields: ctx, name, quantity
+func (_m *UseCase) RestockMerch(ctx context.Context, name string, quantity uint64) (*uint64, error) {
+	ret := _m.Called(ctx, name, quantity)
+
+	var r0 *uint64
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint64) *uint64); ok {
+		r0 = rf(ctx, name, quantity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*uint64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, uint64) error); ok {
+		r1 = rf(ctx, name, quantity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetireMerch provides a mock function with given fields: ctx, name
+func (_m *UseCase) RetireMerch(ctx context.Context, name string) error {
+	ret := _m.Called(ctx, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReturnOrder provides a mock function with given fields: ctx, userID, orderID, req
+func (_m *UseCase) ReturnOrder(ctx context.Context, userID uint64, orderID uint64, req domain.ReturnRequest) (domain.Refund, error) {
+	ret := _m.Called(ctx, userID, orderID, req)
+
+	var r0 domain.Refund
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64, domain.ReturnRequest) domain.Refund); ok {
+		r0 = rf(ctx, userID, orderID, req)
+	} else {
+		r0 = ret.Get(0).(domain.Refund)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64, domain.ReturnRequest) error); ok {
+		r1 = rf(ctx, userID, orderID, req)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -91,13 +580,64 @@ func (_m *UseCase) Login(ctx context.Context, creds domain.Credentials) (string,
 	return r0, r1
 }
 
+// RevokeSessionsByUsername provides a mock function with given fields: ctx, username
+func (_m *UseCase) RevokeSessionsByUsername(ctx context.Context, username string) error {
+	ret := _m.Called(ctx, username)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, username)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeUserSessions provides a mock function with given fields: ctx, userID
+func (_m *UseCase) RevokeUserSessions(ctx context.Context, userID uint64) error {
+	ret := _m.Called(ctx, userID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) error); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // SendCoin provides a mock function with given fields: ctx, fromUserID, req
-func (_m *UseCase) SendCoin(ctx context.Context, fromUserID uint64, req domain.SendCoinRequest) error {
+func (_m *UseCase) SendCoin(ctx context.Context, fromUserID uint64, req domain.SendCoinRequest) (*domain.PendingTransfer, error) {
 	ret := _m.Called(ctx, fromUserID, req)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.SendCoinRequest) error); ok {
+	var r0 *domain.PendingTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.SendCoinRequest) *domain.PendingTransfer); ok {
 		r0 = rf(ctx, fromUserID, req)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*domain.PendingTransfer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.SendCoinRequest) error); ok {
+		r1 = rf(ctx, fromUserID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetMerchStock provides a mock function with given fields: ctx, name, stock
+func (_m *UseCase) SetMerchStock(ctx context.Context, name string, stock *uint64) error {
+	ret := _m.Called(ctx, name, stock)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *uint64) error); ok {
+		r0 = rf(ctx, name, stock)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -105,6 +645,69 @@ func (_m *UseCase) SendCoin(ctx context.Context, fromUserID uint64, req domain.S
 	return r0
 }
 
+// SetUserManager provides a mock function with given fields: ctx, username, managerUsername
+func (_m *UseCase) SetUserManager(ctx context.Context, username string, managerUsername string) error {
+	ret := _m.Called(ctx, username, managerUsername)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, username, managerUsername)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetUserRole provides a mock function with given fields: ctx, username, role
+func (_m *UseCase) SetUserRole(ctx context.Context, username string, role domain.Role) error {
+	ret := _m.Called(ctx, username, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.Role) error); ok {
+		r0 = rf(ctx, username, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateMerch provides a mock function with given fields: ctx, name, req
+func (_m *UseCase) UpdateMerch(ctx context.Context, name string, req domain.UpdateMerchRequest) error {
+	ret := _m.Called(ctx, name, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.UpdateMerchRequest) error); ok {
+		r0 = rf(ctx, name, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateScheduledTransfer provides a mock function with given fields: ctx, ownerID, transferID, req
+func (_m *UseCase) UpdateScheduledTransfer(ctx context.Context, ownerID uint64, transferID uint64, req domain.ScheduledTransferRequest) (domain.ScheduledTransfer, error) {
+	ret := _m.Called(ctx, ownerID, transferID, req)
+
+	var r0 domain.ScheduledTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64, domain.ScheduledTransferRequest) domain.ScheduledTransfer); ok {
+		r0 = rf(ctx, ownerID, transferID, req)
+	} else {
+		r0 = ret.Get(0).(domain.ScheduledTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64, domain.ScheduledTransferRequest) error); ok {
+		r1 = rf(ctx, ownerID, transferID, req)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewUseCase interface {
 	mock.TestingT
 	Cleanup(func())
@@ -4,23 +4,66 @@ import (
 	"crypto/rsa"
 	"github.com/go-chi/chi/v5"
 	"merch-shop/internal/api/middlewares"
+	"merch-shop/internal/domain"
 	"net/http"
+	"time"
 )
 
 func NewRouter(
 	handler *HTTPHandler,
 	publicKey *rsa.PublicKey,
+	sessions middlewares.SessionValidator,
+	idempotency middlewares.IdempotencyStore,
+	idempotencyTTL time.Duration,
 ) (http.Handler, error) {
 	r := chi.NewRouter()
 
-	mid := middlewares.New(publicKey)
+	mid := middlewares.New(publicKey, sessions, idempotency, idempotencyTTL)
 
 	r.Route("/api", func(r chi.Router) {
 
 		r.Post("/auth", handler.Auth)
+		r.Post("/register", handler.Register)
+		r.Post("/auth/refresh", handler.Refresh)
+		r.With(mid.JWTToken).Post("/auth/logout", handler.Logout)
+		r.With(mid.JWTToken).Post("/auth/logout/all", handler.LogoutAll)
 		r.With(mid.JWTToken).Get("/info", handler.Info)
-		r.With(mid.JWTToken).Post("/sendCoin", handler.SendCoin)
-		r.With(mid.JWTToken).Get("/buy/{item}", handler.BuyMerch)
+		r.With(mid.JWTToken).Get("/history", handler.History)
+		r.With(mid.JWTToken).Get("/coins/expiring", handler.ExpiringCoins)
+		r.With(mid.JWTToken).Get("/approvals", handler.ListApprovals)
+		r.With(mid.JWTToken).Post("/approvals/{id}/approve", handler.ApproveTransfer)
+		r.With(mid.JWTToken).Post("/approvals/{id}/reject", handler.RejectTransfer)
+		r.With(mid.JWTToken, mid.Idempotency).Post("/sendCoin", handler.SendCoin)
+		r.With(mid.JWTToken, mid.Idempotency).Post("/sendCoin/batch", handler.BatchSendCoin)
+		r.With(mid.JWTToken).Post("/coin-requests", handler.CreateCoinRequest)
+		r.With(mid.JWTToken).Get("/coin-requests", handler.ListCoinRequests)
+		r.With(mid.JWTToken, mid.Idempotency).Post("/coin-requests/{id}/accept", handler.AcceptCoinRequest)
+		r.With(mid.JWTToken, mid.Idempotency).Post("/coin-requests/{id}/decline", handler.DeclineCoinRequest)
+		r.With(mid.JWTToken).Post("/scheduled-transfers", handler.CreateScheduledTransfer)
+		r.With(mid.JWTToken).Get("/scheduled-transfers", handler.ListScheduledTransfers)
+		r.With(mid.JWTToken).Put("/scheduled-transfers/{id}", handler.UpdateScheduledTransfer)
+		r.With(mid.JWTToken).Delete("/scheduled-transfers/{id}", handler.DeleteScheduledTransfer)
+		r.With(mid.JWTToken, mid.Idempotency).Get("/buy/{item}", handler.BuyMerch)
+		r.With(mid.JWTToken, mid.Idempotency).Post("/orders", handler.CreateOrder)
+		r.With(mid.JWTToken).Get("/orders", handler.ListOrders)
+		r.With(mid.JWTToken).Post("/orders/{id}/return", handler.ReturnOrder)
+		r.Get("/merch", handler.ListMerch)
+
+		r.Route("/admin", func(r chi.Router) {
+			r.Use(mid.JWTToken, mid.RequireRole(domain.RoleAdmin))
+
+			r.Put("/users/{username}/role", handler.SetUserRole)
+			r.Put("/users/{username}/manager", handler.SetUserManager)
+			r.Post("/users/{username}/sessions/revoke", handler.RevokeUserSessions)
+			r.Post("/invites", handler.CreateInvite)
+			r.With(mid.Idempotency).Post("/grants", handler.GrantCoins)
+
+			r.Post("/merch", handler.CreateMerch)
+			r.Put("/merch/{name}", handler.UpdateMerch)
+			r.Delete("/merch/{name}", handler.RetireMerch)
+			r.Post("/merch/{name}/restock", handler.RestockMerch)
+			r.Put("/merch/{name}/stock", handler.SetMerchStock)
+		})
 	})
 
 	return r, nil
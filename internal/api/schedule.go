@@ -0,0 +1,137 @@
+package api
+
+import (
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"log/slog"
+	"merch-shop/internal/api/apierror"
+	shopcontext "merch-shop/internal/api/context"
+	"merch-shop/internal/domain"
+	"net/http"
+	"strconv"
+)
+
+type scheduledTransfersResp struct {
+	Transfers []domain.ScheduledTransfer `json:"transfers"`
+}
+
+func (h *HTTPHandler) CreateScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.ScheduledTransferRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	transfer, err := h.useCase.CreateScheduledTransfer(ctx, userID, body)
+	if err != nil {
+		slog.Error("useCase.CreateScheduledTransfer", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, transfer, http.StatusCreated)
+}
+
+func (h *HTTPHandler) ListScheduledTransfers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	transfers, err := h.useCase.ListScheduledTransfers(ctx, userID)
+	if err != nil {
+		slog.Error("useCase.ListScheduledTransfers", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, scheduledTransfersResp{Transfers: transfers}, http.StatusOK)
+}
+
+func (h *HTTPHandler) UpdateScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	var (
+		body domain.ScheduledTransferRequest
+		err  error
+		ctx  = r.Context()
+	)
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	transferID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	if err = json.NewDecoder(r.Body).Decode(&body); err != nil {
+		apierror.WriteError(w, apierror.ErrParsingBody)
+		return
+	}
+	defer r.Body.Close()
+
+	if err = h.validate.Struct(body); err != nil {
+		apierror.WriteError(w, apierror.ErrValidatingBody)
+		return
+	}
+
+	transfer, err := h.useCase.UpdateScheduledTransfer(ctx, userID, transferID, body)
+	if err != nil {
+		slog.Error("useCase.UpdateScheduledTransfer", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, transfer, http.StatusOK)
+}
+
+func (h *HTTPHandler) DeleteScheduledTransfer(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := shopcontext.UserID(ctx)
+	if !ok {
+		slog.Error("Failed to get user ID")
+		apierror.WriteError(w, apierror.ErrAuthorizationRequired)
+		return
+	}
+
+	transferID, err := strconv.ParseUint(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		apierror.WriteError(w, apierror.ErrInvalidRequest)
+		return
+	}
+
+	if err = h.useCase.DeleteScheduledTransfer(ctx, userID, transferID); err != nil {
+		slog.Error("useCase.DeleteScheduledTransfer", "error", err)
+		apierror.WriteError(w, err)
+		return
+	}
+
+	apierror.RenderJSONWithStatus(w, apierror.JSON{}, http.StatusOK)
+}
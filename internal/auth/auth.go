@@ -1,28 +1,56 @@
 package authorization
 
 import (
+	"crypto/rand"
 	"crypto/rsa"
+	"encoding/base64"
+	"encoding/hex"
 	"fmt"
 	"github.com/golang-jwt/jwt"
+	"merch-shop/internal/domain"
 	"strconv"
+	"time"
 )
 
+const refreshTokenSize = 32
+
+type Claims struct {
+	jwt.StandardClaims
+	Role       domain.Role `json:"role"`
+	Generation uint64      `json:"gen"`
+}
+
 type TokenManager struct {
-	privateKey *rsa.PrivateKey
-	publicKey  *rsa.PublicKey
+	privateKey     *rsa.PrivateKey
+	publicKey      *rsa.PublicKey
+	accessTokenTTL time.Duration
 }
 
-func New(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey) *TokenManager {
+func New(privateKey *rsa.PrivateKey, publicKey *rsa.PublicKey, accessTokenTTL time.Duration) *TokenManager {
 	return &TokenManager{
-		privateKey: privateKey,
-		publicKey:  publicKey,
+		privateKey:     privateKey,
+		publicKey:      publicKey,
+		accessTokenTTL: accessTokenTTL,
 	}
 }
 
-func (m *TokenManager) NewAccessToken(userID uint64) (string, error) {
-	claims := jwt.StandardClaims{
-		Audience: "client_id",
-		Subject:  strconv.Itoa(int(userID)),
+func (m *TokenManager) NewAccessToken(user domain.User) (string, error) {
+	jti, err := randomString(16, hex.EncodeToString)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+
+	now := time.Now()
+	claims := Claims{
+		StandardClaims: jwt.StandardClaims{
+			Audience:  "client_id",
+			Subject:   strconv.Itoa(int(user.ID)),
+			Id:        jti,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(m.accessTokenTTL).Unix(),
+		},
+		Role:       user.Role,
+		Generation: user.TokenGeneration,
 	}
 
 	t := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
@@ -33,3 +61,22 @@ func (m *TokenManager) NewAccessToken(userID uint64) (string, error) {
 
 	return tokenString, nil
 }
+
+// NewRefreshToken возвращает непрозрачный случайный токен, на сервере хранится только его хеш
+func (m *TokenManager) NewRefreshToken() (string, error) {
+	token, err := randomString(refreshTokenSize, base64.RawURLEncoding.EncodeToString)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+func randomString(size int, encode func([]byte) string) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return encode(b), nil
+}
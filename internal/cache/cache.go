@@ -0,0 +1,65 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+type entry[V any] struct {
+	value     V
+	expiresAt time.Time
+}
+
+// Cache — простой in-process кеш с фиксированным временем жизни записей
+type Cache[K comparable, V any] struct {
+	mu        sync.RWMutex
+	ttl       time.Duration
+	items     map[K]entry[V]
+	lastSweep time.Time
+}
+
+func New[K comparable, V any](ttl time.Duration) *Cache[K, V] {
+	return &Cache[K, V]{
+		ttl:       ttl,
+		items:     make(map[K]entry[V]),
+		lastSweep: time.Now(),
+	}
+}
+
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.RLock()
+	e, ok := c.items[key]
+	c.mu.RUnlock()
+
+	if !ok || !time.Now().Before(e.expiresAt) {
+		var zero V
+		return zero, false
+	}
+
+	return e.value, true
+}
+
+func (c *Cache[K, V]) Set(key K, value V) {
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.items[key] = entry[V]{value: value, expiresAt: now.Add(c.ttl)}
+
+	// Устаревшие записи вычищаются не чаще одного раза за ttl
+	if now.Sub(c.lastSweep) >= c.ttl {
+		for k, e := range c.items {
+			if !now.Before(e.expiresAt) {
+				delete(c.items, k)
+			}
+		}
+		c.lastSweep = now
+	}
+}
+
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	delete(c.items, key)
+	c.mu.Unlock()
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCache(t *testing.T) {
+	t.Parallel()
+
+	c := New[string, int](50 * time.Millisecond)
+
+	_, ok := c.Get("missing")
+	assert.False(t, ok)
+
+	c.Set("key", 42)
+	value, ok := c.Get("key")
+	assert.True(t, ok)
+	assert.Equal(t, 42, value)
+
+	c.Delete("key")
+	_, ok = c.Get("key")
+	assert.False(t, ok)
+
+	c.Set("expiring", 1)
+	time.Sleep(60 * time.Millisecond)
+	_, ok = c.Get("expiring")
+	assert.False(t, ok)
+
+	c.Set("fresh", 2)
+	assert.Len(t, c.items, 1)
+}
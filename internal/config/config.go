@@ -11,6 +11,7 @@ import (
 	"github.com/kelseyhightower/envconfig"
 	"log/slog"
 	"strings"
+	"time"
 )
 
 type Config struct {
@@ -18,6 +19,62 @@ type Config struct {
 	DatabaseURL string `envconfig:"DATABASE_URL" required:"true"`
 	PrivateKey  string `envconfig:"PRIVATE_KEY" required:"true"`
 	PublicKey   string `envconfig:"PUBLIC_KEY" required:"true"`
+
+	AccessTokenTTL  time.Duration `envconfig:"ACCESS_TOKEN_TTL" default:"15m"`
+	RefreshTokenTTL time.Duration `envconfig:"REFRESH_TOKEN_TTL" default:"720h"`
+	SessionCacheTTL time.Duration `envconfig:"SESSION_CACHE_TTL" default:"5s"`
+
+	PasswordHasher    string `envconfig:"PASSWORD_HASHER" default:"argon2id"`
+	Argon2Memory      uint32 `envconfig:"ARGON2_MEMORY" default:"65536"`
+	Argon2Iterations  uint32 `envconfig:"ARGON2_ITERATIONS" default:"3"`
+	Argon2Parallelism uint8  `envconfig:"ARGON2_PARALLELISM" default:"2"`
+	BcryptCost        int    `envconfig:"BCRYPT_COST" default:"12"`
+
+	LoginAttemptStore  string        `envconfig:"LOGIN_ATTEMPT_STORE" default:"memory"`
+	LoginMaxFailures   int           `envconfig:"LOGIN_MAX_FAILURES" default:"5"`
+	LoginFailureWindow time.Duration `envconfig:"LOGIN_FAILURE_WINDOW" default:"15m"`
+	LoginLockoutBase   time.Duration `envconfig:"LOGIN_LOCKOUT_BASE" default:"30s"`
+	LoginLockoutMax    time.Duration `envconfig:"LOGIN_LOCKOUT_MAX" default:"1h"`
+
+	AutoRegistration bool          `envconfig:"AUTO_REGISTRATION" default:"true"`
+	InviteRequired   bool          `envconfig:"INVITE_REQUIRED" default:"false"`
+	InviteTTL        time.Duration `envconfig:"INVITE_TTL" default:"168h"`
+	StartingBalance  uint64        `envconfig:"STARTING_BALANCE" default:"1000"`
+
+	ReturnWindow   time.Duration `envconfig:"RETURN_WINDOW" default:"336h"`
+	IdempotencyTTL time.Duration `envconfig:"IDEMPOTENCY_TTL" default:"24h"`
+
+	// Лимиты исходящих переводов; 0 — без ограничения
+	TransferMaxAmount      uint64        `envconfig:"TRANSFER_MAX_AMOUNT" default:"0"`
+	TransferMaxDailyVolume uint64        `envconfig:"TRANSFER_MAX_DAILY_VOLUME" default:"0"`
+	TransferMaxPerHour     int           `envconfig:"TRANSFER_MAX_PER_HOUR" default:"0"`
+	TransferMinAccountAge  time.Duration `envconfig:"TRANSFER_MIN_ACCOUNT_AGE" default:"0"`
+
+	// Переводы больше TRANSFER_APPROVAL_THRESHOLD ждут одобрения; 0 — одобрение не требуется.
+	// TRANSFER_APPROVAL_EXPIRY_INTERVAL = 0 отключает возврат истёкших удержаний на этой реплике
+	ApprovalThreshold      uint64        `envconfig:"TRANSFER_APPROVAL_THRESHOLD" default:"0"`
+	ApprovalTTL            time.Duration `envconfig:"TRANSFER_APPROVAL_TTL" default:"72h"`
+	ApprovalExpiryInterval time.Duration `envconfig:"TRANSFER_APPROVAL_EXPIRY_INTERVAL" default:"1m"`
+
+	CoinRequestTTL time.Duration `envconfig:"COIN_REQUEST_TTL" default:"168h"`
+
+	// SCHEDULER_INTERVAL = 0 отключает выполнение запланированных переводов на этой реплике
+	SchedulerInterval    time.Duration `envconfig:"SCHEDULER_INTERVAL" default:"1m"`
+	ScheduleLease        time.Duration `envconfig:"SCHEDULE_LEASE" default:"5m"`
+	ScheduleRetryBackoff time.Duration `envconfig:"SCHEDULE_RETRY_BACKOFF" default:"5m"`
+	ScheduleMaxAttempts  int           `envconfig:"SCHEDULE_MAX_ATTEMPTS" default:"5"`
+
+	// Экономическая политика: ALLOWANCE_AMOUNT = 0 отключает пособие, COIN_EXPIRY_MONTHS = 0 — сгорание,
+	// ECONOMY_INTERVAL = 0 — фоновую задачу на этой реплике
+	AllowanceAmount       uint64        `envconfig:"ALLOWANCE_AMOUNT" default:"0"`
+	AllowanceActiveWithin time.Duration `envconfig:"ALLOWANCE_ACTIVE_WITHIN" default:"720h"`
+	CoinExpiryMonths      int           `envconfig:"COIN_EXPIRY_MONTHS" default:"0"`
+	EconomyInterval       time.Duration `envconfig:"ECONOMY_INTERVAL" default:"1h"`
+
+	// RECONCILE_INTERVAL = 0 отключает фоновую сверку; METRICS_PORT пустой — метрики не публикуются
+	ReconcileInterval  time.Duration `envconfig:"RECONCILE_INTERVAL" default:"1h"`
+	ReconcileAnomalies bool          `envconfig:"RECONCILE_ANOMALIES" default:"true"`
+	MetricsPort        string        `envconfig:"METRICS_PORT"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -0,0 +1,52 @@
+package domain
+
+import "time"
+
+// Статусы перевода, ожидающего одобрения
+const (
+	TransferPending  = "pending"
+	TransferApproved = "approved"
+	TransferRejected = "rejected"
+	TransferExpired  = "expired"
+)
+
+// PendingTransfer — крупный перевод, который ждёт решения руководителя отправителя
+// или администратора. Пока он не решён, сумма удерживается с баланса отправителя.
+type PendingTransfer struct {
+	ID         uint64    `json:"id"`
+	FromUserID uint64    `json:"-"`
+	FromUser   string    `json:"fromUser"`
+	ToUserID   uint64    `json:"-"`
+	ToUser     string    `json:"toUser"`
+	Amount     uint64    `json:"amount"`
+	Memo       string    `json:"memo,omitempty"`
+	Status     string    `json:"status"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	CreatedAt  time.Time `json:"createdAt"`
+	// ManagerID — руководитель отправителя на момент проверки, 0 — не назначен
+	ManagerID uint64 `json:"-"`
+}
+
+// TransferDecision — решение по ожидающему переводу
+type TransferDecision struct {
+	TransferID uint64
+	ApproverID uint64
+	Role       Role
+	Approve    bool
+}
+
+// CanDecide сообщает, может ли пользователь решать судьбу перевода:
+// это руководитель отправителя или администратор, но не сам отправитель
+// и не получатель — иначе перевод самому себе одобрялся бы без проверки
+func (t PendingTransfer) CanDecide(approverID uint64, role Role) bool {
+	if approverID == t.FromUserID || approverID == t.ToUserID {
+		return false
+	}
+
+	return role == RoleAdmin || (t.ManagerID != 0 && t.ManagerID == approverID)
+}
+
+type SetManagerRequest struct {
+	// Manager — имя руководителя; пустая строка снимает назначение
+	Manager string `json:"manager" validate:"max=100"`
+}
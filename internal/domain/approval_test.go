@@ -0,0 +1,42 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingTransfer_CanDecide(t *testing.T) {
+	t.Parallel()
+
+	transfer := PendingTransfer{FromUserID: 1, ToUserID: 2, ManagerID: 3}
+
+	for _, tt := range []struct {
+		name       string
+		transfer   PendingTransfer
+		approverID uint64
+		role       Role
+		expected   bool
+	}{
+		{name: "Manager", transfer: transfer, approverID: 3, role: RoleUser, expected: true},
+		{name: "Admin", transfer: transfer, approverID: 9, role: RoleAdmin, expected: true},
+		{name: "Sender cannot approve own transfer", transfer: transfer, approverID: 1, role: RoleAdmin},
+		{name: "Recipient", transfer: transfer, approverID: 2, role: RoleUser},
+		{name: "Admin cannot approve transfer to themselves", transfer: transfer, approverID: 2, role: RoleAdmin},
+		{
+			name:       "Manager cannot approve transfer to themselves",
+			transfer:   PendingTransfer{FromUserID: 1, ToUserID: 3, ManagerID: 3},
+			approverID: 3,
+			role:       RoleUser,
+		},
+		{name: "Auditor", transfer: transfer, approverID: 9, role: RoleAuditor},
+		{name: "No manager assigned", transfer: PendingTransfer{FromUserID: 1}, approverID: 0, role: RoleUser},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, tt.transfer.CanDecide(tt.approverID, tt.role))
+		})
+	}
+}
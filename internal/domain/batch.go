@@ -0,0 +1,57 @@
+package domain
+
+import "math"
+
+// Причины отказа по строке пакетного перевода; LineNotFound, LineDuplicate
+// и LineInsufficientFunds общие с заказами и начислениями
+const (
+	LineSelf             = "self"
+	LineSystemAccount    = "system_account"
+	LineApprovalRequired = "approval_required"
+)
+
+type BatchSendCoinRequest struct {
+	Transfers []SendCoinRequest `json:"transfers" validate:"required,min=1,max=100,dive"`
+}
+
+type BatchLineError struct {
+	ToUser string `json:"toUser"`
+	Amount uint64 `json:"amount"`
+	Reason string `json:"reason"`
+}
+
+type BatchResult struct {
+	Recipients int    `json:"recipients"`
+	Total      uint64 `json:"total"`
+}
+
+// CheckBatch проверяет строки пакета по заблокированным данным: получатель существует,
+// это не сам отправитель, и баланса хватает на все строки до текущей включительно
+func CheckBatch(fromUserID, balance uint64, lines []SendCoinRequest, recipients map[string]uint64) []BatchLineError {
+	var (
+		failures []BatchLineError
+		total    uint64
+	)
+
+	for _, line := range lines {
+		id, ok := recipients[line.ToUser]
+		switch {
+		case !ok:
+			failures = append(failures, BatchLineError{ToUser: line.ToUser, Amount: line.Amount, Reason: LineNotFound})
+		case id == fromUserID:
+			failures = append(failures, BatchLineError{ToUser: line.ToUser, Amount: line.Amount, Reason: LineSelf})
+		default:
+			// Сумма не переполняется: иначе огромные строки прошли бы проверку баланса
+			if line.Amount > math.MaxUint64-total {
+				total = math.MaxUint64
+			} else {
+				total += line.Amount
+			}
+			if total > balance {
+				failures = append(failures, BatchLineError{ToUser: line.ToUser, Amount: line.Amount, Reason: LineInsufficientFunds})
+			}
+		}
+	}
+
+	return failures
+}
@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBatch(t *testing.T) {
+	t.Parallel()
+
+	recipients := map[string]uint64{"sender": 1, "ivanov": 2, "petrova": 3}
+
+	for _, tt := range []struct {
+		name     string
+		balance  uint64
+		lines    []SendCoinRequest
+		expected []BatchLineError
+	}{
+		{
+			name:    "Valid batch",
+			balance: 100,
+			lines:   []SendCoinRequest{{ToUser: "ivanov", Amount: 60}, {ToUser: "petrova", Amount: 40}},
+		},
+		{
+			name:    "Unknown recipient and self",
+			balance: 100,
+			lines:   []SendCoinRequest{{ToUser: "nobody", Amount: 10}, {ToUser: "sender", Amount: 10}, {ToUser: "ivanov", Amount: 10}},
+			expected: []BatchLineError{
+				{ToUser: "nobody", Amount: 10, Reason: LineNotFound},
+				{ToUser: "sender", Amount: 10, Reason: LineSelf},
+			},
+		},
+		{
+			name:    "Lines beyond the balance",
+			balance: 100,
+			lines:   []SendCoinRequest{{ToUser: "ivanov", Amount: 70}, {ToUser: "petrova", Amount: 40}},
+			expected: []BatchLineError{
+				{ToUser: "petrova", Amount: 40, Reason: LineInsufficientFunds},
+			},
+		},
+		{
+			name:    "Amounts that overflow the total",
+			balance: 100,
+			lines:   []SendCoinRequest{{ToUser: "ivanov", Amount: math.MaxUint64}, {ToUser: "petrova", Amount: 101}},
+			expected: []BatchLineError{
+				{ToUser: "ivanov", Amount: math.MaxUint64, Reason: LineInsufficientFunds},
+				{ToUser: "petrova", Amount: 101, Reason: LineInsufficientFunds},
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.expected, CheckBatch(1, tt.balance, tt.lines, recipients))
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package domain
+
+import "time"
+
+// Статусы запроса монет. CoinRequestExpired не хранится в базе: его получает
+// неотвеченный запрос, срок которого истёк.
+const (
+	CoinRequestPending  = "pending"
+	CoinRequestAccepted = "accepted"
+	CoinRequestDeclined = "declined"
+	CoinRequestExpired  = "expired"
+)
+
+// Папки запросов: входящие адресованы пользователю, исходящие созданы им
+const (
+	CoinRequestInbox  = "inbox"
+	CoinRequestOutbox = "outbox"
+)
+
+// CoinRequest — просьба одного сотрудника перевести ему монеты.
+// Плательщик может её принять, тогда перевод выполняется как обычный sendCoin, или отклонить.
+type CoinRequest struct {
+	ID          uint64    `json:"id"`
+	RequesterID uint64    `json:"-"`
+	Requester   string    `json:"requester"`
+	PayerID     uint64    `json:"-"`
+	Payer       string    `json:"payer"`
+	Amount      uint64    `json:"amount"`
+	Memo        string    `json:"memo,omitempty"`
+	Status      string    `json:"status"`
+	ExpiresAt   time.Time `json:"expiresAt"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type CreateCoinRequest struct {
+	Payer  string `json:"payer" validate:"required"`
+	Amount uint64 `json:"amount" validate:"required"`
+	Memo   string `json:"memo,omitempty" validate:"max=200"`
+}
+
+type CoinRequestQuery struct {
+	Box    string `validate:"oneof=inbox outbox"`
+	Status string `validate:"omitempty,oneof=pending accepted declined expired"`
+	Limit  int    `validate:"omitempty,min=1,max=100"`
+	Cursor string
+}
+
+// CoinRequestFilter выбирает запросы пользователя из папки Box с id меньше BeforeID (0 — с самого нового)
+type CoinRequestFilter struct {
+	Box      string
+	Status   string
+	BeforeID uint64
+	Limit    int
+}
+
+type CoinRequestPage struct {
+	Requests   []CoinRequest `json:"requests"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
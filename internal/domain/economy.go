@@ -0,0 +1,81 @@
+package domain
+
+import "time"
+
+// Источники партий монет. Сгорать могут только начисленные монеты: стартовый баланс,
+// начисления администратора и ежемесячное пособие. Перевод передаёт партии получателю с
+// прежними источником и датой, а возврат покупки партий не образует.
+const (
+	LotOpening   = "opening"
+	LotGrant     = "grant"
+	LotAllowance = "allowance"
+)
+
+// CoinLot — партия начисленных монет. Траты списываются с партий по порядку
+// начисления (FIFO), Remaining — сколько из партии ещё не потрачено.
+type CoinLot struct {
+	ID        uint64
+	UserID    uint64
+	Source    string
+	Amount    uint64
+	Remaining uint64
+	CreatedAt time.Time
+}
+
+// ExpiresAt — момент, когда неизрасходованный остаток партии сгорит
+func (l CoinLot) ExpiresAt(months int) time.Time {
+	return l.CreatedAt.AddDate(0, months, 0)
+}
+
+type ExpiringCoins struct {
+	Amount    uint64    `json:"amount"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// ExpiryPreview показывает, сколько монет пользователя сгорит и когда
+type ExpiryPreview struct {
+	Enabled  bool            `json:"enabled"`
+	Total    uint64          `json:"total"`
+	Expiring []ExpiringCoins `json:"expiring"`
+}
+
+// PreviewExpiry суммирует неизрасходованные остатки партий по дате сгорания; партии
+// должны идти в порядке начисления. months = 0 — сгорание отключено, и ничего не сгорает.
+func PreviewExpiry(lots []CoinLot, months int) ExpiryPreview {
+	preview := ExpiryPreview{Enabled: months > 0, Expiring: make([]ExpiringCoins, 0)}
+	if !preview.Enabled {
+		return preview
+	}
+
+	for _, lot := range lots {
+		if lot.Remaining == 0 {
+			continue
+		}
+
+		preview.Total += lot.Remaining
+
+		expiresAt := lot.ExpiresAt(months)
+		if last := len(preview.Expiring) - 1; last >= 0 && preview.Expiring[last].ExpiresAt.Equal(expiresAt) {
+			preview.Expiring[last].Amount += lot.Remaining
+			continue
+		}
+
+		preview.Expiring = append(preview.Expiring, ExpiringCoins{Amount: lot.Remaining, ExpiresAt: expiresAt})
+	}
+
+	return preview
+}
+
+// AllowancePeriod — месяц, за который начисляется пособие: первое число месяца now в UTC
+func AllowancePeriod(now time.Time) time.Time {
+	now = now.UTC()
+	return time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// EconomyReport — итог запуска экономической политики
+type EconomyReport struct {
+	AllowancesCredited int
+	AllowanceTotal     uint64
+	UsersExpired       int
+	CoinsExpired       uint64
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewExpiry(t *testing.T) {
+	t.Parallel()
+
+	signup := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	grant := time.Date(2025, 2, 1, 9, 0, 0, 0, time.UTC)
+
+	lots := []CoinLot{
+		{ID: 1, Source: LotOpening, Amount: 1000, Remaining: 400, CreatedAt: signup},
+		{ID: 2, Source: LotGrant, Amount: 100, Remaining: 100, CreatedAt: grant},
+		{ID: 3, Source: LotGrant, Amount: 50, Remaining: 50, CreatedAt: grant},
+		{ID: 4, Source: LotAllowance, Amount: 30, Remaining: 0, CreatedAt: grant.AddDate(0, 1, 0)},
+	}
+
+	assert.Equal(t, ExpiryPreview{
+		Enabled: true,
+		Total:   550,
+		Expiring: []ExpiringCoins{
+			{Amount: 400, ExpiresAt: time.Date(2025, 7, 15, 10, 0, 0, 0, time.UTC)},
+			{Amount: 150, ExpiresAt: time.Date(2025, 8, 1, 9, 0, 0, 0, time.UTC)},
+		},
+	}, PreviewExpiry(lots, 6))
+
+	assert.Equal(t, ExpiryPreview{Expiring: []ExpiringCoins{}}, PreviewExpiry(lots, 0))
+}
+
+func TestAllowancePeriod(t *testing.T) {
+	t.Parallel()
+
+	msk := time.FixedZone("MSK", 3*60*60)
+
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), AllowancePeriod(time.Date(2025, 3, 31, 23, 0, 0, 0, time.UTC)))
+	// 1 апреля 01:00 по Москве — ещё 31 марта в UTC
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), AllowancePeriod(time.Date(2025, 4, 1, 1, 0, 0, 0, msk)))
+}
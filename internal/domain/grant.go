@@ -0,0 +1,31 @@
+package domain
+
+// SystemUsername — служебная учётная запись, от имени которой начисляются монеты.
+// Войти под ней и переводить ей монеты нельзя.
+const SystemUsername = "system"
+
+// Причины отказа по строке начисления; LineNotFound общая с заказами
+const LineDuplicate = "duplicate"
+
+type GrantRequest struct {
+	Reason string      `json:"reason" validate:"required,max=200"`
+	Grants []GrantLine `json:"grants" validate:"required,min=1,max=1000,dive"`
+	DryRun bool        `json:"dryRun"`
+}
+
+type GrantLine struct {
+	Username string `json:"username" validate:"required,max=100"`
+	Amount   uint64 `json:"amount" validate:"required,gt=0,lte=1000000"`
+}
+
+type GrantLineError struct {
+	Username string `json:"username"`
+	Reason   string `json:"reason"`
+}
+
+type GrantResult struct {
+	DryRun     bool        `json:"dryRun"`
+	Recipients int         `json:"recipients"`
+	Total      uint64      `json:"total"`
+	Grants     []GrantLine `json:"grants"`
+}
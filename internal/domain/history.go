@@ -0,0 +1,72 @@
+package domain
+
+import "time"
+
+const (
+	DirectionSent     = "sent"
+	DirectionReceived = "received"
+)
+
+// HistoryEntry — операция с монетами с точки зрения запросившего пользователя
+type HistoryEntry struct {
+	ID           uint64    `json:"id"`
+	Direction    string    `json:"direction"`
+	Counterparty string    `json:"counterparty,omitempty"`
+	Amount       uint64    `json:"amount"`
+	Kind         string    `json:"kind"`
+	Memo         string    `json:"memo,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+}
+
+// HistoryQuery — параметры GET /api/history. Интервал дат полуоткрытый: [From, To).
+// Суммы сравниваются с колонкой INT, поэтому не могут превышать int32.
+type HistoryQuery struct {
+	Direction    string `validate:"omitempty,oneof=sent received"`
+	Counterparty string `validate:"omitempty,max=100"`
+	From         *time.Time
+	To           *time.Time
+	MinAmount    uint64 `validate:"max=2147483647"`
+	MaxAmount    uint64 `validate:"omitempty,max=2147483647,gtefield=MinAmount"`
+	Limit        int    `validate:"omitempty,min=1,max=100"`
+	Cursor       string
+}
+
+type HistoryFilter struct {
+	Direction    string
+	Counterparty string
+	From         *time.Time
+	To           *time.Time
+	MinAmount    uint64
+	MaxAmount    uint64
+	BeforeID     uint64
+	Limit        int
+}
+
+type HistoryPage struct {
+	Items      []HistoryEntry `json:"items"`
+	NextCursor string         `json:"nextCursor,omitempty"`
+}
+
+// Add раскладывает запись по направлению, указывая контрагента в поле,
+// которое соответствует направлению перевода
+func (h *CoinHistory) Add(entry HistoryEntry) {
+	tx := CoinTransaction{
+		ID:        entry.ID,
+		Amount:    int(entry.Amount),
+		Memo:      entry.Memo,
+		CreatedAt: entry.CreatedAt,
+	}
+
+	if entry.Kind != TransactionTransfer {
+		tx.Kind = entry.Kind
+	}
+
+	switch entry.Direction {
+	case DirectionSent:
+		tx.ToUser = entry.Counterparty
+		h.Sent = append(h.Sent, tx)
+	case DirectionReceived:
+		tx.FromUser = entry.Counterparty
+		h.Received = append(h.Received, tx)
+	}
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// IdempotencyKey резервируется в той же транзакции, что и сама операция
+type IdempotencyKey struct {
+	Key         string
+	UserID      uint64
+	RequestHash string
+	ExpiresAt   time.Time
+
+	// Claimed выставляет репозиторий, когда транзакция, зарезервировавшая ключ,
+	// зафиксирована. Только такой запрос сохраняет свой ответ под этим ключом
+	Claimed *bool
+}
+
+// IdempotentResponse — сохранённый ответ на запрос с ключом идемпотентности.
+// Status = 0, пока операция выполнена, но ответ ещё не записан.
+type IdempotentResponse struct {
+	RequestHash string
+	Status      int
+	Body        []byte
+}
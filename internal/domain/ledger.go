@@ -0,0 +1,82 @@
+package domain
+
+// Счета главной книги. Кошелёк заводится на каждого пользователя, системные
+// счета (выручка магазина, эмиссия, удержания) существуют в единственном экземпляре.
+type AccountKind string
+
+const (
+	AccountWallet  AccountKind = "wallet"
+	AccountRevenue AccountKind = "revenue"
+	AccountMint    AccountKind = "mint"
+	AccountEscrow  AccountKind = "escrow"
+)
+
+// Виды записей главной книги
+const (
+	EntryOpening   = "opening"
+	EntryTransfer  = "transfer"
+	EntryPurchase  = "purchase"
+	EntryRefund    = "refund"
+	EntryGrant     = "grant"
+	EntryHold      = "hold"
+	EntryRelease   = "release"
+	EntryAllowance = "allowance"
+	EntryExpiry    = "expiry"
+)
+
+type LedgerAccount struct {
+	Kind   AccountKind
+	UserID uint64
+}
+
+var (
+	RevenueAccount = LedgerAccount{Kind: AccountRevenue}
+	MintAccount    = LedgerAccount{Kind: AccountMint}
+	EscrowAccount  = LedgerAccount{Kind: AccountEscrow}
+)
+
+func WalletAccount(userID uint64) LedgerAccount {
+	return LedgerAccount{Kind: AccountWallet, UserID: userID}
+}
+
+type Posting struct {
+	Account LedgerAccount
+	Amount  int64
+}
+
+// LedgerEntry — одна операция в главной книге. TransactionID и OrderID связывают
+// её с записью истории или заказом, 0 — связи нет.
+type LedgerEntry struct {
+	Kind          string
+	TransactionID uint64
+	OrderID       uint64
+	Postings      []Posting
+}
+
+// NewMovement переносит amount монет со счёта from на счёт to
+func NewMovement(kind string, from, to LedgerAccount, amount uint64) LedgerEntry {
+	return LedgerEntry{
+		Kind: kind,
+		Postings: []Posting{
+			{Account: from, Amount: -int64(amount)},
+			{Account: to, Amount: int64(amount)},
+		},
+	}
+}
+
+// Balanced сообщает, что запись непуста, не содержит нулевых проводок и сумма проводок равна нулю
+func (e LedgerEntry) Balanced() bool {
+	if len(e.Postings) == 0 {
+		return false
+	}
+
+	var sum int64
+	for _, posting := range e.Postings {
+		if posting.Amount == 0 {
+			return false
+		}
+		sum += posting.Amount
+	}
+
+	return sum == 0
+}
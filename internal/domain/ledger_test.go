@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewMovement(t *testing.T) {
+	t.Parallel()
+
+	entry := NewMovement(EntryTransfer, WalletAccount(1), WalletAccount(2), 150)
+
+	assert.Equal(t, EntryTransfer, entry.Kind)
+	assert.Equal(t, []Posting{
+		{Account: LedgerAccount{Kind: AccountWallet, UserID: 1}, Amount: -150},
+		{Account: LedgerAccount{Kind: AccountWallet, UserID: 2}, Amount: 150},
+	}, entry.Postings)
+	assert.True(t, entry.Balanced())
+}
+
+func TestLedgerEntry_Balanced(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name     string
+		postings []Posting
+		expected bool
+	}{
+		{
+			name: "Purchase",
+			postings: []Posting{
+				{Account: WalletAccount(1), Amount: -80},
+				{Account: RevenueAccount, Amount: 80},
+			},
+			expected: true,
+		},
+		{
+			name: "Split across accounts",
+			postings: []Posting{
+				{Account: MintAccount, Amount: -100},
+				{Account: WalletAccount(1), Amount: 60},
+				{Account: WalletAccount(2), Amount: 40},
+			},
+			expected: true,
+		},
+		{
+			name: "Does not sum to zero",
+			postings: []Posting{
+				{Account: WalletAccount(1), Amount: -80},
+				{Account: RevenueAccount, Amount: 70},
+			},
+		},
+		{
+			name: "Zero posting",
+			postings: []Posting{
+				{Account: WalletAccount(1), Amount: 0},
+				{Account: RevenueAccount, Amount: 0},
+			},
+		},
+		{
+			name: "Empty",
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := LedgerEntry{Kind: EntryPurchase, Postings: tt.postings}
+			assert.Equal(t, tt.expected, entry.Balanced())
+		})
+	}
+}
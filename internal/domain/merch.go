@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+const DefaultCategory = "other"
+
+type Merch struct {
+	ID        uint64     `json:"-"`
+	Name      string     `json:"name"`
+	Category  string     `json:"category"`
+	Price     uint64     `json:"price"`
+	Stock     *uint64    `json:"stock"`
+	RetiredAt *time.Time `json:"retiredAt,omitempty"`
+
+	// ReturnWindowHours — срок возврата товара; nil означает срок по умолчанию
+	ReturnWindowHours *uint64 `json:"returnWindowHours,omitempty"`
+}
+
+type CreateMerchRequest struct {
+	Name     string  `json:"name" validate:"required,max=100"`
+	Category string  `json:"category" validate:"omitempty,max=50"`
+	Price    uint64  `json:"price" validate:"required,gt=0,lte=2147483647"`
+	Stock    *uint64 `json:"stock" validate:"omitempty,lte=2147483647"`
+
+	ReturnWindowHours *uint64 `json:"returnWindowHours" validate:"omitempty,lte=8760"`
+}
+
+// UpdateMerchRequest меняет название, категорию, срок возврата и/или задаёт новую цену.
+// Без EffectiveFrom цена вступает в силу сразу.
+type UpdateMerchRequest struct {
+	Name              string     `json:"name" validate:"required_without_all=Category Price ReturnWindowHours,omitempty,max=100"`
+	Category          string     `json:"category" validate:"omitempty,max=50"`
+	Price             uint64     `json:"price" validate:"omitempty,gt=0,lte=2147483647"`
+	EffectiveFrom     *time.Time `json:"effectiveFrom" validate:"excluded_without=Price"`
+	ReturnWindowHours *uint64    `json:"returnWindowHours" validate:"omitempty,lte=8760"`
+}
+
+// RestockRequest пополняет остаток товара на Quantity единиц
+type RestockRequest struct {
+	Quantity uint64 `json:"quantity" validate:"required,gt=0,lte=2147483647"`
+}
+
+// SetStockRequest задаёт остаток явно; null снимает ограничение
+type SetStockRequest struct {
+	Stock *uint64 `json:"stock" validate:"omitempty,lte=2147483647"`
+}
+
+type CatalogItem struct {
+	Name      string  `json:"name"`
+	Category  string  `json:"category"`
+	Price     uint64  `json:"price"`
+	Stock     *uint64 `json:"stock,omitempty"`
+	Available bool    `json:"available"`
+}
+
+// CatalogQuery — параметры GET /api/merch. Sort с минусом означает сортировку по убыванию.
+// Цены сравниваются с колонкой INT, поэтому не могут превышать int32.
+type CatalogQuery struct {
+	Category string `validate:"omitempty,max=50"`
+	MinPrice uint64 `validate:"lte=2147483647"`
+	MaxPrice uint64 `validate:"omitempty,lte=2147483647,gtefield=MinPrice"`
+	Sort     string `validate:"omitempty,oneof=name -name price -price"`
+	Limit    int    `validate:"omitempty,min=1,max=100"`
+	Cursor   string
+}
+
+// CatalogFilter передаётся в репозиторий; After — последний элемент предыдущей страницы
+type CatalogFilter struct {
+	Category string
+	MinPrice uint64
+	MaxPrice uint64
+	Sort     string
+	Limit    int
+	After    *CatalogItem
+}
+
+type CatalogPage struct {
+	Items      []CatalogItem `json:"items"`
+	NextCursor string        `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,114 @@
+package domain
+
+import "time"
+
+// Причины отказа по строке заказа
+const (
+	LineNotFound          = "not_found"
+	LineOutOfStock        = "out_of_stock"
+	LineInsufficientFunds = "insufficient_funds"
+)
+
+type OrderRequest struct {
+	Items []OrderLine `json:"items" validate:"required,min=1,max=50,dive"`
+}
+
+type OrderLine struct {
+	Item     string `json:"item" validate:"required,max=100"`
+	Quantity uint64 `json:"quantity" validate:"required,gt=0,lte=1000"`
+}
+
+type Order struct {
+	ID        uint64      `json:"orderId"`
+	Total     uint64      `json:"total"`
+	Items     []OrderItem `json:"items"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+type OrderItem struct {
+	MerchID   uint64 `json:"-"`
+	Item      string `json:"item"`
+	Quantity  uint64 `json:"quantity"`
+	UnitPrice uint64 `json:"unitPrice"`
+}
+
+type OrderQuery struct {
+	Limit  int `validate:"omitempty,min=1,max=100"`
+	Cursor string
+}
+
+// OrderFilter выбирает заказы с id меньше BeforeID (0 — с самого нового)
+type OrderFilter struct {
+	BeforeID uint64
+	Limit    int
+}
+
+type OrderPage struct {
+	Orders     []Order `json:"orders"`
+	NextCursor string  `json:"nextCursor,omitempty"`
+}
+
+// ReturnRequest перечисляет возвращаемые позиции заказа; пустой список — все невозвращённые
+type ReturnRequest struct {
+	Items []string `json:"items" validate:"omitempty,max=50,dive,required"`
+}
+
+type Refund struct {
+	OrderID uint64      `json:"orderId"`
+	Amount  uint64      `json:"amount"`
+	Items   []OrderItem `json:"items"`
+}
+
+type OrderLineError struct {
+	Item     string `json:"item"`
+	Quantity uint64 `json:"quantity"`
+	Reason   string `json:"reason"`
+}
+
+// MerchSnapshot — цена и остаток товара, зафиксированные блокировкой на время оформления
+type MerchSnapshot struct {
+	ID    uint64
+	Name  string
+	Price uint64
+	Stock *uint64
+}
+
+// PriceOrder считает заказ по зафиксированным ценам. Строки проверяются по порядку:
+// если нарастающий итог превышает баланс, эта и все следующие строки помечаются как неоплачиваемые.
+func PriceOrder(lines []OrderLine, merch map[string]MerchSnapshot, balance uint64) (Order, []OrderLineError) {
+	var (
+		order     = Order{Items: make([]OrderItem, 0, len(lines))}
+		failures  []OrderLineError
+		overdrawn bool
+	)
+
+	for _, line := range lines {
+		item, ok := merch[line.Item]
+		if !ok {
+			failures = append(failures, OrderLineError{Item: line.Item, Quantity: line.Quantity, Reason: LineNotFound})
+			continue
+		}
+
+		if item.Stock != nil && *item.Stock < line.Quantity {
+			failures = append(failures, OrderLineError{Item: line.Item, Quantity: line.Quantity, Reason: LineOutOfStock})
+			continue
+		}
+
+		cost := item.Price * line.Quantity
+		if overdrawn || order.Total+cost > balance {
+			failures = append(failures, OrderLineError{Item: line.Item, Quantity: line.Quantity, Reason: LineInsufficientFunds})
+			overdrawn = true
+			continue
+		}
+
+		order.Total += cost
+		order.Items = append(order.Items, OrderItem{
+			MerchID:   item.ID,
+			Item:      item.Name,
+			Quantity:  line.Quantity,
+			UnitPrice: item.Price,
+		})
+	}
+
+	return order, failures
+}
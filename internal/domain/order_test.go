@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPriceOrder(t *testing.T) {
+	t.Parallel()
+
+	stock := uint64(2)
+	merch := map[string]MerchSnapshot{
+		"cup":       {ID: 1, Name: "cup", Price: 20},
+		"powerbank": {ID: 2, Name: "powerbank", Price: 200, Stock: &stock},
+		"hoody":     {ID: 3, Name: "hoody", Price: 300},
+	}
+
+	for _, tt := range []struct {
+		name           string
+		lines          []OrderLine
+		balance        uint64
+		expectTotal    uint64
+		expectFailures []OrderLineError
+	}{
+		{
+			name:        "Whole order fits",
+			lines:       []OrderLine{{Item: "cup", Quantity: 3}, {Item: "powerbank", Quantity: 2}},
+			balance:     1000,
+			expectTotal: 460,
+		},
+		{
+			name:    "Unknown item and not enough stock",
+			lines:   []OrderLine{{Item: "cup", Quantity: 1}, {Item: "mug", Quantity: 1}, {Item: "powerbank", Quantity: 3}},
+			balance: 1000,
+			expectFailures: []OrderLineError{
+				{Item: "mug", Quantity: 1, Reason: LineNotFound},
+				{Item: "powerbank", Quantity: 3, Reason: LineOutOfStock},
+			},
+			expectTotal: 20,
+		},
+		{
+			name:    "Funds run out mid-order",
+			lines:   []OrderLine{{Item: "hoody", Quantity: 2}, {Item: "powerbank", Quantity: 1}, {Item: "cup", Quantity: 1}},
+			balance: 700,
+			expectFailures: []OrderLineError{
+				{Item: "powerbank", Quantity: 1, Reason: LineInsufficientFunds},
+				{Item: "cup", Quantity: 1, Reason: LineInsufficientFunds},
+			},
+			expectTotal: 600,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			order, failures := PriceOrder(tt.lines, merch, tt.balance)
+			assert.Equal(t, tt.expectFailures, failures)
+			assert.Equal(t, tt.expectTotal, order.Total)
+		})
+	}
+}
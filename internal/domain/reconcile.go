@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// Виды аномалий, которые открывает сверка балансов
+const (
+	AnomalyBalanceMismatch = "balance_mismatch"
+	AnomalyUnbalancedEntry = "unbalanced_entry"
+)
+
+// BalanceMismatch — кошелёк, у которого кэш баланса, главная книга и пересчёт
+// по истории операций расходятся между собой
+type BalanceMismatch struct {
+	UserID   uint64 `json:"userId"`
+	Username string `json:"username"`
+	Cached   int64  `json:"cached"`
+	Ledger   int64  `json:"ledger"`
+	History  int64  `json:"history"`
+}
+
+type ReconciliationReport struct {
+	CheckedAt         time.Time         `json:"checkedAt"`
+	Wallets           int               `json:"wallets"`
+	Mismatches        []BalanceMismatch `json:"mismatches"`
+	UnbalancedEntries []uint64          `json:"unbalancedEntries"`
+	AnomaliesOpened   int               `json:"anomaliesOpened"`
+}
+
+func (r ReconciliationReport) Consistent() bool {
+	return len(r.Mismatches) == 0 && len(r.UnbalancedEntries) == 0
+}
+
+// Anomaly — сигнал для разбора. Ref идентифицирует объект ("user:42", "entry:7"):
+// пока аномалия по нему не закрыта, повторная не открывается.
+type Anomaly struct {
+	Kind    string
+	Ref     string
+	Details interface{}
+}
@@ -0,0 +1,21 @@
+package domain
+
+type Role string
+
+const (
+	RoleUser    Role = "user"
+	RoleAdmin   Role = "admin"
+	RoleAuditor Role = "auditor"
+)
+
+func (r Role) Valid() bool {
+	switch r {
+	case RoleUser, RoleAdmin, RoleAuditor:
+		return true
+	}
+	return false
+}
+
+type SetRoleRequest struct {
+	Role Role `json:"role" validate:"required,oneof=user admin auditor"`
+}
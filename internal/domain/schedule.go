@@ -0,0 +1,76 @@
+package domain
+
+import "time"
+
+// Периодичность запланированного перевода
+const (
+	RepeatOnce    = "once"
+	RepeatDaily   = "daily"
+	RepeatWeekly  = "weekly"
+	RepeatMonthly = "monthly"
+)
+
+// ScheduledTransfer — перевод, который планировщик выполняет от имени владельца
+// в NextRunAt и затем, если он повторяющийся, переносит на следующий срок.
+// RetryAt задан, пока срок повторяется после неудачи или занят планировщиком.
+type ScheduledTransfer struct {
+	ID        uint64     `json:"id"`
+	OwnerID   uint64     `json:"-"`
+	ToUserID  uint64     `json:"-"`
+	ToUser    string     `json:"toUser"`
+	Amount    uint64     `json:"amount"`
+	Memo      string     `json:"memo,omitempty"`
+	Repeat    string     `json:"repeat"`
+	NextRunAt time.Time  `json:"nextRunAt"`
+	Active    bool       `json:"active"`
+	Failures  int        `json:"failures"`
+	RetryAt   *time.Time `json:"retryAt,omitempty"`
+	LastError string     `json:"lastError,omitempty"`
+	LastRunAt *time.Time `json:"lastRunAt,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// NextAfter отдаёт первый срок по расписанию позже now. Пропущенные сроки
+// (например, пока сервис был остановлен) не наверстываются. Для разового
+// перевода следующего срока нет.
+func (s ScheduledTransfer) NextAfter(now time.Time) (time.Time, bool) {
+	next := s.NextRunAt
+	for !next.After(now) {
+		switch s.Repeat {
+		case RepeatDaily:
+			next = next.AddDate(0, 0, 1)
+		case RepeatWeekly:
+			next = next.AddDate(0, 0, 7)
+		case RepeatMonthly:
+			next = next.AddDate(0, 1, 0)
+		default:
+			return time.Time{}, false
+		}
+	}
+
+	return next, true
+}
+
+type ScheduledTransferRequest struct {
+	ToUser string `json:"toUser" validate:"required"`
+	Amount uint64 `json:"amount" validate:"required"`
+	Memo   string `json:"memo,omitempty" validate:"max=200"`
+	Repeat string `json:"repeat" validate:"required,oneof=once daily weekly monthly"`
+	// StartAt — первый срок; если не задан, перевод выполнится при ближайшем запуске планировщика
+	StartAt time.Time `json:"startAt"`
+	// Active = false приостанавливает перевод; по умолчанию перевод активен
+	Active *bool `json:"active,omitempty"`
+}
+
+// ScheduledRun — итог выполнения перевода, занятого планировщиком до LeaseUntil.
+// RetryAt = 0 — срок закрыт, следующий — NextRunAt.
+type ScheduledRun struct {
+	ID         uint64
+	LeaseUntil time.Time
+	RanAt      time.Time
+	NextRunAt  time.Time
+	RetryAt    time.Time
+	Active     bool
+	Failures   int
+	LastError  string
+}
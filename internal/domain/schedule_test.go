@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduledTransfer_NextAfter(t *testing.T) {
+	t.Parallel()
+
+	// Понедельник, 9:00
+	due := time.Date(2025, 3, 3, 9, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		name     string
+		repeat   string
+		now      time.Time
+		expected time.Time
+		ok       bool
+	}{
+		{
+			name:     "Weekly",
+			repeat:   RepeatWeekly,
+			now:      due.Add(time.Minute),
+			expected: time.Date(2025, 3, 10, 9, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "Missed occurrences are skipped",
+			repeat:   RepeatDaily,
+			now:      due.Add(50 * time.Hour),
+			expected: time.Date(2025, 3, 6, 9, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "Monthly",
+			repeat:   RepeatMonthly,
+			now:      due,
+			expected: time.Date(2025, 4, 3, 9, 0, 0, 0, time.UTC),
+			ok:       true,
+		},
+		{
+			name:     "Future due date is kept",
+			repeat:   RepeatWeekly,
+			now:      due.Add(-time.Hour),
+			expected: due,
+			ok:       true,
+		},
+		{
+			name:   "Once has no next run",
+			repeat: RepeatOnce,
+			now:    due.Add(time.Minute),
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			next, ok := ScheduledTransfer{Repeat: tt.repeat, NextRunAt: due}.NextAfter(tt.now)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, next)
+		})
+	}
+}
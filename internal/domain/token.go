@@ -0,0 +1,34 @@
+package domain
+
+import "time"
+
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+}
+
+type RefreshToken struct {
+	ID        uint64
+	UserID    uint64
+	TokenHash string
+	FamilyID  string
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+func (t RefreshToken) Expired(now time.Time) bool {
+	return !now.Before(t.ExpiresAt)
+}
+
+func (t RefreshToken) Revoked() bool {
+	return t.RevokedAt != nil
+}
+
+// Session описывает access-токен, с которым пришёл запрос
+type Session struct {
+	UserID     uint64
+	Role       Role
+	TokenID    string
+	Generation uint64
+	ExpiresAt  time.Time
+}
@@ -1,13 +1,13 @@
 package domain
 
-import (
-	"crypto/sha256"
-	"encoding/base64"
-)
+import "time"
 
 type User struct {
-	ID    uint64 `json:"user_id"`
-	Coins uint64 `json:"coins"`
+	ID              uint64    `json:"user_id"`
+	Coins           uint64    `json:"coins"`
+	Role            Role      `json:"role"`
+	TokenGeneration uint64    `json:"-"`
+	CreatedAt       time.Time `json:"-"`
 	Credentials
 }
 
@@ -16,16 +16,19 @@ type Credentials struct {
 	Password Password `json:"password" validate:"required"`
 }
 
-type Password string
-
-func (p *Password) Secure() Password {
-	return Password(p.hash())
+type RegisterRequest struct {
+	Credentials
+	InviteCode string `json:"inviteCode"`
 }
 
-func (p *Password) Verify(password Password) bool {
-	return p.String() == password.hash()
+type Invite struct {
+	Code      string    `json:"code"`
+	CreatedBy uint64    `json:"-"`
+	ExpiresAt time.Time `json:"expiresAt"`
 }
 
+type Password string
+
 func (p *Password) String() string {
 	if p == nil {
 		return ""
@@ -33,14 +36,6 @@ func (p *Password) String() string {
 	return string(*p)
 }
 
-func (p *Password) hash() string {
-	hasher := sha256.New()
-	hasher.Write([]byte(p.String()))
-	h := hasher.Sum(nil)
-
-	return base64.StdEncoding.EncodeToString(h)
-}
-
 type Info struct {
 	UserID      uint64      `json:"user_id"`
 	Coins       uint64      `json:"coins"`
@@ -58,12 +53,52 @@ type CoinHistory struct {
 	Sent     []CoinTransaction `json:"sent"`
 }
 
+const (
+	TransactionTransfer  = "transfer"
+	TransactionRefund    = "refund"
+	TransactionGrant     = "grant"
+	TransactionAllowance = "allowance"
+	TransactionExpiry    = "expiry"
+)
+
+// CoinTransaction — запись истории. Для входящих заполняется FromUser,
+// для исходящих — ToUser; у возвратов контрагента нет.
 type CoinTransaction struct {
-	UserName string `json:"fromUser,omitempty"`
-	Amount   int    `json:"amount"`
+	ID        uint64    `json:"id"`
+	FromUser  string    `json:"fromUser,omitempty"`
+	ToUser    string    `json:"toUser,omitempty"`
+	Amount    int       `json:"amount"`
+	Kind      string    `json:"kind,omitempty"`
+	Memo      string    `json:"memo,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
 }
 
 type SendCoinRequest struct {
 	ToUser string `json:"toUser" validate:"required"`
-	Amount uint64 `json:"amount" validate:"required"`
+	Amount uint64 `json:"amount" validate:"required,lte=2147483647"`
+	Memo   string `json:"memo,omitempty" validate:"max=200"`
+}
+
+// TransferStats — исходящие переводы пользователя за скользящие окна, нужные политикам лимитов
+type TransferStats struct {
+	SentLastDay       uint64
+	TransfersLastHour int
+	// FirstInHour — время самого раннего перевода в часовом окне
+	FirstInHour time.Time
+}
+
+// TransferGuard проверяет лимиты внутри транзакции списания: репозиторий блокирует
+// отправителя, считает его переводы после DaySince и HourSince и передаёт их в Check.
+// Поэтому параллельные переводы одного отправителя проверяются по очереди, и каждый
+// видит предыдущие. Нулевое значение лимиты не проверяет.
+type TransferGuard struct {
+	DaySince  time.Time
+	HourSince time.Time
+	Check     func(stats TransferStats) error
+}
+
+type FailedLogin struct {
+	Username string
+	IP       string
+	Reason   string
 }
@@ -0,0 +1,26 @@
+// Package idempotency передаёт ключ идемпотентности из HTTP-слоя
+// в репозиторий, который резервирует его вместе с записью операции.
+package idempotency
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+)
+
+type contextKey struct{}
+
+func WithKey(ctx context.Context, key domain.IdempotencyKey) context.Context {
+	if ctx == nil {
+		return nil
+	}
+	return context.WithValue(ctx, contextKey{}, key)
+}
+
+func FromContext(ctx context.Context) (domain.IdempotencyKey, bool) {
+	if ctx == nil {
+		return domain.IdempotencyKey{}, false
+	}
+
+	key, ok := ctx.Value(contextKey{}).(domain.IdempotencyKey)
+	return key, ok
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"expvar"
+	"merch-shop/internal/domain"
+	"net/http"
+)
+
+var (
+	reconcileRuns       = expvar.NewInt("reconcile_runs_total")
+	reconcileFailures   = expvar.NewInt("reconcile_failures_total")
+	reconcileWallets    = expvar.NewInt("reconcile_wallets")
+	reconcileMismatches = expvar.NewInt("reconcile_mismatches")
+	reconcileUnbalanced = expvar.NewInt("reconcile_unbalanced_entries")
+	reconcileLastRun    = expvar.NewInt("reconcile_last_run_unix")
+)
+
+// ObserveReconciliation публикует результат последней сверки
+func ObserveReconciliation(report domain.ReconciliationReport) {
+	reconcileRuns.Add(1)
+	reconcileWallets.Set(int64(report.Wallets))
+	reconcileMismatches.Set(int64(len(report.Mismatches)))
+	reconcileUnbalanced.Set(int64(len(report.UnbalancedEntries)))
+	reconcileLastRun.Set(report.CheckedAt.Unix())
+}
+
+func ObserveReconciliationFailure() {
+	reconcileFailures.Add(1)
+}
+
+// Handler отдаёт метрики в формате expvar (JSON)
+func Handler() http.Handler {
+	return expvar.Handler()
+}
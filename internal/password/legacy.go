@@ -0,0 +1,34 @@
+package password
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"strings"
+)
+
+var errLegacyHashing = errors.New("legacy SHA-256 hashing is verify-only")
+
+// legacySHA256 проверяет несолёные SHA-256 хеши в base64, которые сохранялись
+// до перехода на argon2id/bcrypt. Новые хеши в этом формате не создаются.
+type legacySHA256 struct{}
+
+func (legacySHA256) Hash(string) (string, error) {
+	return "", errLegacyHashing
+}
+
+func (legacySHA256) Verify(password, encoded string) (bool, error) {
+	h := sha256.Sum256([]byte(password))
+	expected := base64.StdEncoding.EncodeToString(h[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(encoded)) == 1, nil
+}
+
+func (legacySHA256) Matches(encoded string) bool {
+	return !strings.HasPrefix(encoded, "$") && len(encoded) == base64.StdEncoding.EncodedLen(sha256.Size)
+}
+
+func (legacySHA256) NeedsRehash(string) bool {
+	return true
+}
@@ -0,0 +1,84 @@
+package password
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var (
+	ErrUnknownAlgorithm = errors.New("unknown password hashing algorithm")
+	ErrUnknownFormat    = errors.New("unknown password hash format")
+)
+
+// Algorithm — одна схема хеширования. Хеши самоописываемые, поэтому по строке
+// хеша можно определить, какой схемой и с какими параметрами он получен.
+type Algorithm interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (bool, error)
+	Matches(encoded string) bool
+	NeedsRehash(encoded string) bool
+}
+
+type Params struct {
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        int
+}
+
+// Hasher хеширует пароли предпочтительным алгоритмом и проверяет хеши любого
+// известного формата, включая устаревший несолёный SHA-256.
+type Hasher struct {
+	preferred  Algorithm
+	algorithms []Algorithm
+}
+
+func New(algorithm string, params Params) (*Hasher, error) {
+	argon := NewArgon2id(params.Argon2Memory, params.Argon2Iterations, params.Argon2Parallelism)
+	bcrypt := NewBcrypt(params.BcryptCost)
+
+	var preferred Algorithm
+	switch algorithm {
+	case AlgorithmArgon2id:
+		preferred = argon
+	case AlgorithmBcrypt:
+		preferred = bcrypt
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownAlgorithm, algorithm)
+	}
+
+	return &Hasher{
+		preferred:  preferred,
+		algorithms: []Algorithm{argon, bcrypt, legacySHA256{}},
+	}, nil
+}
+
+func (h *Hasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+// Verify сообщает, подходит ли пароль к хешу и нужно ли перехешировать его
+// текущим алгоритмом с текущими параметрами.
+func (h *Hasher) Verify(password, encoded string) (bool, bool, error) {
+	for _, algorithm := range h.algorithms {
+		if !algorithm.Matches(encoded) {
+			continue
+		}
+
+		ok, err := algorithm.Verify(password, encoded)
+		if err != nil || !ok {
+			return false, false, err
+		}
+
+		needsRehash := algorithm != h.preferred || h.preferred.NeedsRehash(encoded)
+
+		return true, needsRehash, nil
+	}
+
+	return false, false, ErrUnknownFormat
+}
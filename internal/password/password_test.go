@@ -0,0 +1,112 @@
+package password
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testParams = Params{
+	Argon2Memory:      1024,
+	Argon2Iterations:  1,
+	Argon2Parallelism: 1,
+	BcryptCost:        4,
+}
+
+func TestHasher(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name   string
+		algo   string
+		prefix string
+	}{
+		{name: "argon2id", algo: AlgorithmArgon2id, prefix: "$argon2id$v=19$m=1024,t=1,p=1$"},
+		{name: "bcrypt", algo: AlgorithmBcrypt, prefix: "$2a$04$"},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			hasher, err := New(tt.algo, testParams)
+			require.NoError(t, err)
+
+			encoded, err := hasher.Hash("Secret123")
+			require.NoError(t, err)
+			assert.True(t, strings.HasPrefix(encoded, tt.prefix), encoded)
+
+			again, err := hasher.Hash("Secret123")
+			require.NoError(t, err)
+			assert.NotEqual(t, encoded, again, "hashes must be salted")
+
+			ok, rehash, err := hasher.Verify("Secret123", encoded)
+			assert.NoError(t, err)
+			assert.True(t, ok)
+			assert.False(t, rehash)
+
+			ok, _, err = hasher.Verify("Wrong123", encoded)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
+
+func TestHasher_Rehash(t *testing.T) {
+	t.Parallel()
+
+	sum := sha256.Sum256([]byte("Secret123"))
+	legacy := base64.StdEncoding.EncodeToString(sum[:])
+
+	argon, err := New(AlgorithmArgon2id, testParams)
+	require.NoError(t, err)
+
+	ok, rehash, err := argon.Verify("Secret123", legacy)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash, "legacy hashes must be upgraded")
+
+	ok, _, err = argon.Verify("Wrong123", legacy)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	bcryptHash, err := NewBcrypt(testParams.BcryptCost).Hash("Secret123")
+	require.NoError(t, err)
+
+	ok, rehash, err = argon.Verify("Secret123", bcryptHash)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash, "hashes of non-preferred algorithm must be upgraded")
+
+	stronger := testParams
+	stronger.Argon2Iterations = 2
+	upgraded, err := New(AlgorithmArgon2id, stronger)
+	require.NoError(t, err)
+
+	encoded, err := argon.Hash("Secret123")
+	require.NoError(t, err)
+
+	ok, rehash, err = upgraded.Verify("Secret123", encoded)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, rehash, "hashes with outdated parameters must be upgraded")
+}
+
+func TestHasher_Errors(t *testing.T) {
+	t.Parallel()
+
+	_, err := New("md5", testParams)
+	assert.ErrorIs(t, err, ErrUnknownAlgorithm)
+
+	hasher, err := New(AlgorithmArgon2id, testParams)
+	require.NoError(t, err)
+
+	_, _, err = hasher.Verify("Secret123", "plain-text")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+
+	_, _, err = hasher.Verify("Secret123", "$argon2id$broken")
+	assert.ErrorIs(t, err, ErrUnknownFormat)
+}
@@ -0,0 +1,299 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
+	"time"
+)
+
+const (
+	holdCoins             = `UPDATE public.users SET coins = coins - $2 WHERE id = $1 AND coins >= $2`
+	insertPendingTransfer = `
+	INSERT INTO public.pending_transfers (from_user_id, to_user_id, amount, memo, expires_at)
+	VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+	RETURNING id, created_at`
+)
+
+// CreatePendingTransfer удерживает сумму с баланса отправителя и заводит перевод,
+// ожидающий одобрения. Удержанные монеты недоступны ни для покупок, ни для переводов.
+func (r *Repository) CreatePendingTransfer(ctx context.Context, transfer domain.PendingTransfer, guard domain.TransferGuard) (domain.PendingTransfer, error) {
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := claimIdempotencyKey(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := checkTransferLimits(ctx, tx, transfer.FromUserID, guard); err != nil {
+			return err
+		}
+
+		result, err := tx.ExecContext(ctx, holdCoins, transfer.FromUserID, transfer.Amount)
+		if err != nil {
+			return fmt.Errorf("ошибка удержания монет: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("ошибка при проверке обновления: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return usecase.ErrNoCoins
+		}
+
+		err = tx.QueryRowContext(ctx, insertPendingTransfer,
+			transfer.FromUserID, transfer.ToUserID, transfer.Amount, transfer.Memo, transfer.ExpiresAt,
+		).Scan(&transfer.ID, &transfer.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("ошибка создания перевода: %w", err)
+		}
+
+		// Партии удерживаются вместе с монетами и после решения достаются получателю
+		// или возвращаются отправителю с прежней датой
+		if err = moveCoinLots(ctx, tx, transfer.FromUserID, transfer.FromUserID, transfer.Amount, transfer.ID); err != nil {
+			return err
+		}
+
+		entry := domain.NewMovement(domain.EntryHold,
+			domain.WalletAccount(transfer.FromUserID), domain.EscrowAccount, transfer.Amount)
+
+		return postLedgerEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		return domain.PendingTransfer{}, err
+	}
+
+	transfer.Status = domain.TransferPending
+
+	return transfer, nil
+}
+
+const listPendingApprovals = `
+	SELECT p.id, p.from_user_id, f.username, p.to_user_id, t.username, p.amount,
+		COALESCE(p.memo, ''), p.status, p.expires_at, p.created_at
+	FROM public.pending_transfers p
+	JOIN public.users f ON f.id = p.from_user_id
+	JOIN public.users t ON t.id = p.to_user_id
+	WHERE p.status = 'pending' AND p.expires_at > $3
+		AND p.from_user_id <> $1 AND p.to_user_id <> $1
+		AND ($2 OR f.manager_id = $1)
+	ORDER BY p.id`
+
+// ListPendingApprovals отдаёт неистёкшие переводы, по которым пользователь может принять решение
+func (r *Repository) ListPendingApprovals(ctx context.Context, approverID uint64, role domain.Role) ([]domain.PendingTransfer, error) {
+	rows, err := r.db.QueryContext(ctx, listPendingApprovals, approverID, role == domain.RoleAdmin, time.Now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения переводов на одобрение: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := make([]domain.PendingTransfer, 0)
+	for rows.Next() {
+		var t domain.PendingTransfer
+		if err = rows.Scan(
+			&t.ID, &t.FromUserID, &t.FromUser, &t.ToUserID, &t.ToUser, &t.Amount,
+			&t.Memo, &t.Status, &t.ExpiresAt, &t.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка обработки строки: %w", err)
+		}
+		transfers = append(transfers, t)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения переводов на одобрение: %w", err)
+	}
+
+	return transfers, nil
+}
+
+const (
+	lockPendingTransfer = `
+	SELECT p.id, p.from_user_id, f.username, p.to_user_id, t.username, p.amount,
+		COALESCE(p.memo, ''), p.status, p.expires_at, p.created_at, COALESCE(f.manager_id, 0)
+	FROM public.pending_transfers p
+	JOIN public.users f ON f.id = p.from_user_id
+	JOIN public.users t ON t.id = p.to_user_id
+	WHERE p.id = $1
+	FOR UPDATE OF p`
+	lockExpiredTransfers = `
+	SELECT id, from_user_id, amount
+	FROM public.pending_transfers
+	WHERE status = 'pending' AND expires_at <= $1
+	ORDER BY id
+	LIMIT $2
+	FOR UPDATE SKIP LOCKED`
+	settleTransfer = `
+	UPDATE public.pending_transfers
+	SET status = $2, decided_by = NULLIF($3::bigint, 0), transaction_id = NULLIF($4::bigint, 0), decided_at = NOW()
+	WHERE id = $1`
+	insertApprovedTransfer = `
+	INSERT INTO public.transactions (from_user_id, to_user_id, quantity, memo)
+	VALUES ($1, $2, $3, NULLIF($4, ''))
+	RETURNING id`
+)
+
+// DecidePendingTransfer одобряет или отклоняет перевод. При одобрении удержанная сумма
+// зачисляется получателю, при отклонении возвращается отправителю.
+func (r *Repository) DecidePendingTransfer(ctx context.Context, decision domain.TransferDecision) (domain.PendingTransfer, error) {
+	var transfer domain.PendingTransfer
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(ctx, lockPendingTransfer, decision.TransferID).Scan(
+			&transfer.ID, &transfer.FromUserID, &transfer.FromUser, &transfer.ToUserID, &transfer.ToUser,
+			&transfer.Amount, &transfer.Memo, &transfer.Status, &transfer.ExpiresAt, &transfer.CreatedAt,
+			&transfer.ManagerID,
+		)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return usecase.ErrTransferNotFound
+			}
+			return fmt.Errorf("ошибка получения перевода: %w", err)
+		}
+
+		if !transfer.CanDecide(decision.ApproverID, decision.Role) {
+			return usecase.ErrNotApprover
+		}
+
+		if transfer.Status != domain.TransferPending {
+			return usecase.ErrTransferDecided
+		}
+
+		// Истёкшие переводы освобождает фоновая задача, решение по ним уже не принимается
+		if !transfer.ExpiresAt.After(time.Now().UTC()) {
+			return usecase.ErrTransferExpired
+		}
+
+		if !decision.Approve {
+			transfer.Status = domain.TransferRejected
+			return releaseHold(ctx, tx, transfer.ID, transfer.FromUserID, transfer.Amount, domain.TransferRejected, decision.ApproverID)
+		}
+
+		if _, err = tx.ExecContext(ctx, creditCoins, transfer.ToUserID, transfer.Amount); err != nil {
+			return fmt.Errorf("ошибка зачисления монет: %w", err)
+		}
+
+		var transactionID uint64
+		err = tx.QueryRowContext(ctx, insertApprovedTransfer,
+			transfer.FromUserID, transfer.ToUserID, transfer.Amount, transfer.Memo,
+		).Scan(&transactionID)
+		if err != nil {
+			return fmt.Errorf("ошибка записи перевода: %w", err)
+		}
+
+		entry := domain.NewMovement(domain.EntryTransfer,
+			domain.EscrowAccount, domain.WalletAccount(transfer.ToUserID), transfer.Amount)
+		entry.TransactionID = transactionID
+
+		if err = postLedgerEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+
+		if err = settleCoinLots(ctx, tx, transfer.ID, transfer.ToUserID); err != nil {
+			return err
+		}
+
+		if _, err = tx.ExecContext(ctx, settleTransfer, transfer.ID, domain.TransferApproved, decision.ApproverID, transactionID); err != nil {
+			return fmt.Errorf("ошибка обновления перевода: %w", err)
+		}
+
+		transfer.Status = domain.TransferApproved
+
+		return nil
+	})
+	if err != nil {
+		return domain.PendingTransfer{}, err
+	}
+
+	return transfer, nil
+}
+
+// ExpirePendingTransfers возвращает отправителям суммы не более limit истёкших переводов.
+// Строки, занятые параллельным решением, пропускаются до следующего запуска.
+func (r *Repository) ExpirePendingTransfers(ctx context.Context, now time.Time, limit int) (int, error) {
+	var expired int
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		rows, err := tx.QueryContext(ctx, lockExpiredTransfers, now, limit)
+		if err != nil {
+			return fmt.Errorf("ошибка получения истёкших переводов: %w", err)
+		}
+		defer rows.Close()
+
+		type hold struct {
+			id, fromUserID, amount uint64
+		}
+
+		var holds []hold
+		for rows.Next() {
+			var h hold
+			if err = rows.Scan(&h.id, &h.fromUserID, &h.amount); err != nil {
+				return fmt.Errorf("ошибка обработки строки: %w", err)
+			}
+			holds = append(holds, h)
+		}
+
+		if err = rows.Err(); err != nil {
+			return fmt.Errorf("ошибка чтения истёкших переводов: %w", err)
+		}
+
+		for _, h := range holds {
+			if err = releaseHold(ctx, tx, h.id, h.fromUserID, h.amount, domain.TransferExpired, 0); err != nil {
+				return err
+			}
+		}
+
+		expired = len(holds)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return expired, nil
+}
+
+func releaseHold(ctx context.Context, tx *sql.Tx, transferID, fromUserID, amount uint64, status string, decidedBy uint64) error {
+	if _, err := tx.ExecContext(ctx, creditCoins, fromUserID, amount); err != nil {
+		return fmt.Errorf("ошибка возврата удержания: %w", err)
+	}
+
+	entry := domain.NewMovement(domain.EntryRelease, domain.EscrowAccount, domain.WalletAccount(fromUserID), amount)
+	if err := postLedgerEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	if err := settleCoinLots(ctx, tx, transferID, fromUserID); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, settleTransfer, transferID, status, decidedBy, 0); err != nil {
+		return fmt.Errorf("ошибка обновления перевода: %w", err)
+	}
+
+	return nil
+}
+
+const setUserManager = `UPDATE public.users SET manager_id = NULLIF($2::bigint, 0) WHERE id = $1`
+
+// SetUserManager назначает руководителя, managerID = 0 снимает назначение
+func (r *Repository) SetUserManager(ctx context.Context, userID, managerID uint64) error {
+	result, err := r.db.ExecContext(ctx, setUserManager, userID, managerID)
+	if err != nil {
+		return fmt.Errorf("ошибка назначения руководителя: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке обновления: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return usecase.ErrNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+	"time"
+)
+
+// LoginAttempts хранит счётчики неудачных входов в Postgres, чтобы блокировки
+// действовали согласованно на всех экземплярах сервиса
+type LoginAttempts struct {
+	db *sql.DB
+}
+
+func NewLoginAttempts(db *sql.DB) *LoginAttempts {
+	return &LoginAttempts{
+		db: db,
+	}
+}
+
+const getLockedUntil = `SELECT locked_until FROM public.login_attempts WHERE key = $1`
+
+func (s *LoginAttempts) LockedUntil(ctx context.Context, key string) (time.Time, error) {
+	var lockedUntil sql.NullTime
+
+	if err := s.db.QueryRowContext(ctx, getLockedUntil, key).Scan(&lockedUntil); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("ошибка получения блокировки входа: %w", err)
+	}
+
+	return lockedUntil.Time, nil
+}
+
+const registerFailure = `
+	INSERT INTO public.login_attempts (key, failures, window_start)
+	VALUES ($1, 1, NOW())
+	ON CONFLICT (key) DO UPDATE SET
+		failures = CASE
+			WHEN login_attempts.window_start < NOW() - make_interval(secs => $2) THEN 1
+			ELSE login_attempts.failures + 1
+		END,
+		window_start = CASE
+			WHEN login_attempts.window_start < NOW() - make_interval(secs => $2) THEN NOW()
+			ELSE login_attempts.window_start
+		END
+	RETURNING failures`
+
+func (s *LoginAttempts) RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error) {
+	var failures int
+
+	if err := s.db.QueryRowContext(ctx, registerFailure, key, window.Seconds()).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("ошибка учёта неудачного входа: %w", err)
+	}
+
+	return failures, nil
+}
+
+const lockLogin = `UPDATE public.login_attempts SET locked_until = $2 WHERE key = $1`
+
+func (s *LoginAttempts) Lock(ctx context.Context, key string, until time.Time) error {
+	if _, err := s.db.ExecContext(ctx, lockLogin, key, until); err != nil {
+		return fmt.Errorf("ошибка блокировки входа: %w", err)
+	}
+
+	return nil
+}
+
+const resetLoginAttempts = `DELETE FROM public.login_attempts WHERE key = $1`
+
+func (s *LoginAttempts) Reset(ctx context.Context, key string) error {
+	if _, err := s.db.ExecContext(ctx, resetLoginAttempts, key); err != nil {
+		return fmt.Errorf("ошибка сброса счётчика входов: %w", err)
+	}
+
+	return nil
+}
+
+const saveFailedLogin = `INSERT INTO public.auth_audit (username, ip, reason) VALUES ($1, $2, $3)`
+
+func (r *Repository) SaveFailedLogin(ctx context.Context, attempt domain.FailedLogin) error {
+	if _, err := r.db.ExecContext(ctx, saveFailedLogin, attempt.Username, attempt.IP, attempt.Reason); err != nil {
+		return fmt.Errorf("ошибка записи в журнал входов: %w", err)
+	}
+
+	return nil
+}
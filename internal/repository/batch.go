@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
+)
+
+// Отправитель и получатели блокируются одним запросом в порядке id,
+// чтобы встречные пакеты не взаимоблокировались
+const lockBatchUsers = `
+	SELECT id, username, coins FROM public.users
+	WHERE (id = $1 OR username = ANY($2)) AND username <> $3
+	ORDER BY id
+	FOR UPDATE`
+
+// BatchTransferCoins выполняет все переводы пакета в одной транзакции. Если хотя бы одна
+// строка не проходит проверку, ничего не переводится и возвращается *usecase.BatchError.
+func (r *Repository) BatchTransferCoins(ctx context.Context, fromUserID uint64, lines []domain.SendCoinRequest, guard domain.TransferGuard) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := claimIdempotencyKey(ctx, tx); err != nil {
+			return err
+		}
+
+		usernames := make([]string, 0, len(lines))
+		for _, line := range lines {
+			usernames = append(usernames, line.ToUser)
+		}
+
+		rows, err := tx.QueryContext(ctx, lockBatchUsers, fromUserID, usernames, domain.SystemUsername)
+		if err != nil {
+			return fmt.Errorf("ошибка блокировки участников перевода: %w", err)
+		}
+		defer rows.Close()
+
+		var (
+			balance uint64
+			ids     = make(map[string]uint64, len(lines))
+		)
+		for rows.Next() {
+			var (
+				id       uint64
+				username string
+				coins    uint64
+			)
+			if err = rows.Scan(&id, &username, &coins); err != nil {
+				return fmt.Errorf("ошибка обработки строки: %w", err)
+			}
+			if id == fromUserID {
+				balance = coins
+			}
+			ids[username] = id
+		}
+		if err = rows.Err(); err != nil {
+			return fmt.Errorf("ошибка чтения участников перевода: %w", err)
+		}
+
+		// Отправитель уже заблокирован вместе с получателями
+		if err = checkTransferLimits(ctx, tx, fromUserID, guard); err != nil {
+			return err
+		}
+
+		if failures := domain.CheckBatch(fromUserID, balance, lines, ids); len(failures) > 0 {
+			return &usecase.BatchError{Lines: failures}
+		}
+
+		for _, line := range lines {
+			if _, err = transferCoinsTx(ctx, tx, fromUserID, ids[line.ToUser], line.Amount, line.Memo); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"merch-shop/internal/domain"
 	"merch-shop/internal/usecase"
+	"time"
 )
 
 const transferCoins = `
@@ -21,55 +23,199 @@ const transferCoins = `
 		WHERE id = $3
 		RETURNING id
 	)
-	INSERT INTO public.transactions (from_user_id, to_user_id, quantity)
-	SELECT $2, $3, $1
+	INSERT INTO public.transactions (from_user_id, to_user_id, quantity, memo)
+	SELECT $2, $3, $1, NULLIF($4, '')
 	WHERE EXISTS (SELECT 1 FROM updated_sender) AND EXISTS (SELECT 1 FROM updated_receiver)
+	RETURNING id
 	`
 
-func (r *Repository) TransferCoins(ctx context.Context, fromUserID, toUserID uint64, amount uint64) error {
+func (r *Repository) TransferCoins(ctx context.Context, fromUserID, toUserID uint64, amount uint64, memo string, guard domain.TransferGuard) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := claimIdempotencyKey(ctx, tx); err != nil {
+			return err
+		}
+
+		if err := checkTransferLimits(ctx, tx, fromUserID, guard); err != nil {
+			return err
+		}
 
-	_, err := r.db.ExecContext(ctx, transferCoins, amount, fromUserID, toUserID)
+		_, err := transferCoinsTx(ctx, tx, fromUserID, toUserID, amount, memo)
+		return err
+	})
+}
+
+// transferCoinsTx переводит монеты внутри транзакции tx и возвращает id записи в истории
+func transferCoinsTx(ctx context.Context, tx *sql.Tx, fromUserID, toUserID uint64, amount uint64, memo string) (uint64, error) {
+	var transactionID uint64
+	err := tx.QueryRowContext(ctx, transferCoins, amount, fromUserID, toUserID, memo).Scan(&transactionID)
 	if err != nil {
-		return fmt.Errorf("ошибка выполнения перевода монет: %w", err)
+		// Списание могло пройти без зачисления, поэтому транзакция откатывается целиком
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, usecase.ErrNoCoins
+		}
+		return 0, fmt.Errorf("ошибка выполнения перевода монет: %w", err)
+	}
+
+	entry := domain.NewMovement(domain.EntryTransfer,
+		domain.WalletAccount(fromUserID), domain.WalletAccount(toUserID), amount)
+	entry.TransactionID = transactionID
+
+	if err = postLedgerEntry(ctx, tx, entry); err != nil {
+		return 0, err
+	}
+
+	if err = moveCoinLots(ctx, tx, fromUserID, toUserID, amount, 0); err != nil {
+		return 0, err
 	}
 
-	return nil
+	return transactionID, nil
 }
 
+const (
+	lockSender       = `SELECT id FROM public.users WHERE id = $1 FOR UPDATE`
+	getTransferStats = `
+	SELECT COALESCE(SUM(amount) FILTER (WHERE created_at > $2), 0),
+		COUNT(*) FILTER (WHERE created_at > $3),
+		MIN(created_at) FILTER (WHERE created_at > $3)
+	FROM (
+		SELECT quantity AS amount, created_at
+		FROM public.transactions
+		WHERE from_user_id = $1 AND kind = 'transfer' AND created_at > LEAST($2::timestamp, $3::timestamp)
+		UNION ALL
+		SELECT amount, created_at
+		FROM public.pending_transfers
+		WHERE from_user_id = $1 AND status = 'pending' AND created_at > LEAST($2::timestamp, $3::timestamp)
+	) t`
+)
+
+// checkTransferLimits блокирует отправителя до конца транзакции и проверяет лимиты по его
+// переводам. Параллельный перевод того же отправителя ждёт блокировку и считает
+// статистику уже с учётом этого перевода.
+func checkTransferLimits(ctx context.Context, tx *sql.Tx, fromUserID uint64, guard domain.TransferGuard) error {
+	if guard.Check == nil {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, lockSender, fromUserID); err != nil {
+		return fmt.Errorf("ошибка блокировки отправителя: %w", err)
+	}
+
+	stats, err := transferStats(ctx, tx, fromUserID, guard.DaySince, guard.HourSince)
+	if err != nil {
+		return err
+	}
+
+	return guard.Check(stats)
+}
+
+// transferStats считает исходящие переводы пользователя после daySince и hourSince. Удержанные
+// переводы, ждущие одобрения, считаются наравне с проведёнными: иначе крупные переводы
+// можно было бы заводить в обход лимитов
+func transferStats(ctx context.Context, tx *sql.Tx, userID uint64, daySince, hourSince time.Time) (domain.TransferStats, error) {
+	var (
+		stats       domain.TransferStats
+		firstInHour sql.NullTime
+	)
+
+	err := tx.QueryRowContext(ctx, getTransferStats, userID, daySince, hourSince).
+		Scan(&stats.SentLastDay, &stats.TransfersLastHour, &firstInHour)
+	if err != nil {
+		return domain.TransferStats{}, fmt.Errorf("ошибка получения статистики переводов: %w", err)
+	}
+
+	stats.FirstInHour = firstInHour.Time
+
+	return stats, nil
+}
+
+// Остаток и баланс списываются одним запросом. Если не удалось хотя бы одно
+// из списаний, транзакция откатывается целиком; stock = NULL означает неограниченный запас.
+// Параллельная покупка последней единицы ждёт блокировку строки товара и после фиксации
+// первой перепроверяет stock > 0, поэтому остаток не уходит в минус.
 const buyMerchQuery = `
-WITH deducted AS (
+WITH item AS (
+	UPDATE public.merch
+	SET stock = stock - 1
+	WHERE name = $3 AND retired_at IS NULL AND (stock IS NULL OR stock > 0)
+	RETURNING id
+),
+deducted AS (
 	UPDATE public.users 
 	SET coins = coins - $1
 	WHERE id = $2 AND coins >= $1
 	RETURNING id
+),
+inserted AS (
+	INSERT INTO public.inventory (user_id, merch_id, quantity)
+	SELECT $2, item.id, 1
+	FROM item, deducted
+	ON CONFLICT (user_id, merch_id) 
+	DO UPDATE SET quantity = inventory.quantity + 1
+	RETURNING id
+),
+ordered AS (
+	INSERT INTO public.orders (user_id, total)
+	SELECT $2, $1
+	FROM item, deducted
+	RETURNING id
+),
+purchased AS (
+	INSERT INTO public.purchases (order_id, user_id, merch_id, quantity, unit_price)
+	SELECT ordered.id, $2, item.id, 1, $1
+	FROM ordered, item
+	RETURNING id
 )
-INSERT INTO public.inventory (user_id, merch_id, quantity)
-SELECT $2, m.id, 1
-FROM public.merch m, deducted
-WHERE m.name = $3
-ON CONFLICT (user_id, merch_id) 
-DO UPDATE SET quantity = inventory.quantity + 1;
+SELECT EXISTS (SELECT 1 FROM public.merch WHERE name = $3 AND retired_at IS NULL),
+	EXISTS (SELECT 1 FROM item), EXISTS (SELECT 1 FROM deducted), EXISTS (SELECT 1 FROM inserted)
+	AND EXISTS (SELECT 1 FROM purchased), (SELECT id FROM ordered);
 `
 
 func (r *Repository) BuyMerch(ctx context.Context, userID uint64, itemName string, itemPrice uint64) error {
-	result, err := r.db.ExecContext(ctx, buyMerchQuery, itemPrice, userID, itemName)
-	if err != nil {
-		return fmt.Errorf("ошибка при покупке товара: %w", err)
-	}
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := claimIdempotencyKey(ctx, tx); err != nil {
+			return err
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("ошибка при проверке обновления: %w", err)
-	}
+		var (
+			found, inStock, deducted, inserted bool
+			orderID                            sql.NullInt64
+		)
 
-	if rowsAffected == 0 {
-		return usecase.ErrNoCoins
-	}
+		err := tx.QueryRowContext(ctx, buyMerchQuery, itemPrice, userID, itemName).
+			Scan(&found, &inStock, &deducted, &inserted, &orderID)
+		if err != nil {
+			return fmt.Errorf("ошибка при покупке товара: %w", err)
+		}
 
-	return nil
+		// Товар могли снять с продажи после того, как была получена его цена
+		switch {
+		case !found:
+			return usecase.ErrNotFound
+		case !inStock:
+			return usecase.ErrOutOfStock
+		case !deducted:
+			return usecase.ErrNoCoins
+		case !inserted:
+			return errors.New("ошибка при покупке товара: запись в инвентарь не добавлена")
+		}
+
+		entry := domain.NewMovement(domain.EntryPurchase, domain.WalletAccount(userID), domain.RevenueAccount, itemPrice)
+		entry.OrderID = uint64(orderID.Int64)
+
+		if err = postLedgerEntry(ctx, tx, entry); err != nil {
+			return err
+		}
+
+		return consumeCoinLots(ctx, tx, userID, itemPrice)
+	})
 }
 
-const getMerchPriceQuery = `SELECT price FROM public.merch WHERE name = $1`
+const getMerchPriceQuery = `
+	SELECT p.price
+	FROM public.merch m
+	JOIN public.merch_current_prices p ON p.merch_id = m.id
+	WHERE m.name = $1 AND m.retired_at IS NULL
+	`
 
 func (r *Repository) GetMerchPrice(ctx context.Context, itemName string) (uint64, error) {
 	var price uint64
@@ -0,0 +1,180 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
+	"time"
+)
+
+const insertCoinRequest = `
+	INSERT INTO public.coin_requests (requester_id, payer_id, amount, memo, expires_at)
+	VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+	RETURNING id, created_at`
+
+func (r *Repository) CreateCoinRequest(ctx context.Context, request domain.CoinRequest) (domain.CoinRequest, error) {
+	err := r.db.QueryRowContext(ctx, insertCoinRequest,
+		request.RequesterID, request.PayerID, request.Amount, request.Memo, request.ExpiresAt,
+	).Scan(&request.ID, &request.CreatedAt)
+	if err != nil {
+		return domain.CoinRequest{}, fmt.Errorf("ошибка создания запроса монет: %w", err)
+	}
+
+	request.Status = domain.CoinRequestPending
+
+	return request, nil
+}
+
+// Неотвеченный запрос с истёкшим сроком отдаётся со статусом expired
+const (
+	coinRequestColumns = `
+	c.id, c.requester_id, rq.username, c.payer_id, p.username, c.amount, COALESCE(c.memo, ''),
+		CASE WHEN c.status = 'pending' AND c.expires_at <= $1 THEN 'expired' ELSE c.status END,
+		c.expires_at, c.created_at
+	FROM public.coin_requests c
+	JOIN public.users rq ON rq.id = c.requester_id
+	JOIN public.users p ON p.id = c.payer_id`
+	listCoinRequests = `SELECT` + coinRequestColumns + `
+	WHERE (($3::text = 'inbox' AND c.payer_id = $2) OR ($3::text = 'outbox' AND c.requester_id = $2))
+		AND ($4::bigint = 0 OR c.id < $4)
+		AND ($5::text = '' OR $5::text = CASE WHEN c.status = 'pending' AND c.expires_at <= $1 THEN 'expired' ELSE c.status END)
+	ORDER BY c.id DESC
+	LIMIT $6`
+	getCoinRequest    = `SELECT` + coinRequestColumns + ` WHERE c.id = $2`
+	lockCoinRequest   = getCoinRequest + ` FOR UPDATE OF c`
+	settleCoinRequest = `
+	UPDATE public.coin_requests
+	SET status = $2, transaction_id = NULLIF($3::bigint, 0), decided_at = NOW()
+	WHERE id = $1`
+)
+
+// ListCoinRequests отдаёт входящие или исходящие запросы пользователя, начиная с самых новых
+func (r *Repository) ListCoinRequests(ctx context.Context, userID uint64, filter domain.CoinRequestFilter) ([]domain.CoinRequest, error) {
+	rows, err := r.db.QueryContext(ctx, listCoinRequests,
+		time.Now().UTC(), userID, filter.Box, filter.BeforeID, filter.Status, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения запросов монет: %w", err)
+	}
+	defer rows.Close()
+
+	requests := make([]domain.CoinRequest, 0)
+	for rows.Next() {
+		request, err := scanCoinRequest(rows)
+		if err != nil {
+			return nil, err
+		}
+		requests = append(requests, request)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения запросов монет: %w", err)
+	}
+
+	return requests, nil
+}
+
+func (r *Repository) GetCoinRequest(ctx context.Context, requestID uint64) (domain.CoinRequest, error) {
+	request, err := scanCoinRequest(r.db.QueryRowContext(ctx, getCoinRequest, time.Now().UTC(), requestID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.CoinRequest{}, usecase.ErrCoinRequestNotFound
+		}
+		return domain.CoinRequest{}, err
+	}
+
+	return request, nil
+}
+
+// AcceptCoinRequest переводит монеты плательщика автору запроса. Перевод и смена статуса
+// выполняются в одной транзакции, поэтому запрос нельзя оплатить дважды.
+func (r *Repository) AcceptCoinRequest(ctx context.Context, requestID, payerID uint64, guard domain.TransferGuard) (domain.CoinRequest, error) {
+	return r.decideCoinRequest(ctx, requestID, payerID, true, guard)
+}
+
+func (r *Repository) DeclineCoinRequest(ctx context.Context, requestID, payerID uint64) (domain.CoinRequest, error) {
+	return r.decideCoinRequest(ctx, requestID, payerID, false, domain.TransferGuard{})
+}
+
+func (r *Repository) decideCoinRequest(ctx context.Context, requestID, payerID uint64, accept bool, guard domain.TransferGuard) (domain.CoinRequest, error) {
+	var request domain.CoinRequest
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := claimIdempotencyKey(ctx, tx); err != nil {
+			return err
+		}
+
+		var err error
+		request, err = scanCoinRequest(tx.QueryRowContext(ctx, lockCoinRequest, time.Now().UTC(), requestID))
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return usecase.ErrCoinRequestNotFound
+			}
+			return err
+		}
+
+		// Чужие запросы не раскрываются
+		if request.PayerID != payerID {
+			return usecase.ErrCoinRequestNotFound
+		}
+
+		switch request.Status {
+		case domain.CoinRequestPending:
+		case domain.CoinRequestExpired:
+			return usecase.ErrCoinRequestExpired
+		default:
+			return usecase.ErrCoinRequestDecided
+		}
+
+		if !accept {
+			request.Status = domain.CoinRequestDeclined
+			if _, err = tx.ExecContext(ctx, settleCoinRequest, request.ID, request.Status, 0); err != nil {
+				return fmt.Errorf("ошибка обновления запроса монет: %w", err)
+			}
+			return nil
+		}
+
+		if err = checkTransferLimits(ctx, tx, request.PayerID, guard); err != nil {
+			return err
+		}
+
+		transactionID, err := transferCoinsTx(ctx, tx, request.PayerID, request.RequesterID, request.Amount, request.Memo)
+		if err != nil {
+			return err
+		}
+
+		request.Status = domain.CoinRequestAccepted
+		if _, err = tx.ExecContext(ctx, settleCoinRequest, request.ID, request.Status, transactionID); err != nil {
+			return fmt.Errorf("ошибка обновления запроса монет: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return domain.CoinRequest{}, err
+	}
+
+	return request, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanCoinRequest(row rowScanner) (domain.CoinRequest, error) {
+	var c domain.CoinRequest
+	err := row.Scan(
+		&c.ID, &c.RequesterID, &c.Requester, &c.PayerID, &c.Payer, &c.Amount,
+		&c.Memo, &c.Status, &c.ExpiresAt, &c.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.CoinRequest{}, err
+		}
+		return domain.CoinRequest{}, fmt.Errorf("ошибка обработки запроса монет: %w", err)
+	}
+
+	return c, nil
+}
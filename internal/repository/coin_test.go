@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_TransferCoins_LimitsAreCheckedUnderLock(t *testing.T) {
+	t.Parallel()
+
+	repo, conn := testRepository(t)
+	ctx := context.Background()
+
+	fromID, _ := createTestUser(t, repo, 1000)
+	toID, _ := createTestUser(t, repo, 0)
+
+	now := time.Now().UTC()
+	// Не больше одного перевода в час
+	guard := domain.TransferGuard{
+		DaySince:  now.Add(-24 * time.Hour),
+		HourSince: now.Add(-time.Hour),
+		Check: func(stats domain.TransferStats) error {
+			if stats.TransfersLastHour >= 1 {
+				return usecase.ErrTransferLimit
+			}
+			return nil
+		},
+	}
+
+	var (
+		wg        sync.WaitGroup
+		succeeded atomic.Int32
+	)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := repo.TransferCoins(ctx, fromID, toID, 10, "", guard)
+			if err == nil {
+				succeeded.Add(1)
+				return
+			}
+			assert.ErrorIs(t, err, usecase.ErrTransferLimit)
+		}()
+	}
+	wg.Wait()
+
+	// Параллельные переводы видят друг друга, поэтому лимит не превышен
+	assert.EqualValues(t, 1, succeeded.Load())
+	assert.EqualValues(t, 990, userCoins(t, conn, fromID))
+	assert.EqualValues(t, 10, userCoins(t, conn, toID))
+}
+
+func TestRepository_CreatePendingTransfer_CountsOpenHolds(t *testing.T) {
+	t.Parallel()
+
+	repo, conn := testRepository(t)
+	ctx := context.Background()
+
+	fromID, _ := createTestUser(t, repo, 1000)
+	toID, _ := createTestUser(t, repo, 0)
+
+	now := time.Now().UTC()
+	// Не больше 300 монет в сутки
+	guard := domain.TransferGuard{
+		DaySince:  now.Add(-24 * time.Hour),
+		HourSince: now.Add(-time.Hour),
+		Check: func(stats domain.TransferStats) error {
+			if stats.SentLastDay+200 > 300 {
+				return usecase.ErrTransferLimit
+			}
+			return nil
+		},
+	}
+
+	hold := func() error {
+		_, err := repo.CreatePendingTransfer(ctx, domain.PendingTransfer{
+			FromUserID: fromID,
+			ToUserID:   toID,
+			Amount:     200,
+			ExpiresAt:  now.Add(time.Hour),
+		}, guard)
+		return err
+	}
+
+	require.NoError(t, hold())
+
+	// Первое удержание ещё не одобрено, но уже занимает суточный лимит
+	assert.ErrorIs(t, hold(), usecase.ErrTransferLimit)
+	assert.ErrorIs(t, repo.TransferCoins(ctx, fromID, toID, 200, "", guard), usecase.ErrTransferLimit)
+	assert.EqualValues(t, 800, userCoins(t, conn, fromID))
+}
+
+func TestRepository_BuyMerch_LastUnit(t *testing.T) {
+	t.Parallel()
+
+	repo, conn := testRepository(t)
+	ctx := context.Background()
+
+	stock := uint64(1)
+	name := fmt.Sprintf("test-merch-%d", time.Now().UnixNano())
+	merchID, err := repo.CreateMerch(ctx, domain.Merch{Name: name, Category: "test", Price: 10, Stock: &stock})
+	require.NoError(t, err)
+
+	const buyers = 10
+
+	buyerIDs := make([]uint64, buyers)
+	for i := range buyerIDs {
+		buyerIDs[i], _ = createTestUser(t, repo, 100)
+	}
+
+	var (
+		wg        sync.WaitGroup
+		succeeded atomic.Int32
+	)
+	for _, buyerID := range buyerIDs {
+		wg.Add(1)
+		go func(buyerID uint64) {
+			defer wg.Done()
+
+			err := repo.BuyMerch(ctx, buyerID, name, 10)
+			if err == nil {
+				succeeded.Add(1)
+				return
+			}
+			assert.ErrorIs(t, err, usecase.ErrOutOfStock)
+		}(buyerID)
+	}
+	wg.Wait()
+
+	// Последнюю единицу получает ровно один покупатель, остальные ничего не платят
+	assert.EqualValues(t, 1, succeeded.Load())
+
+	var left int64
+	require.NoError(t, conn.QueryRow(`SELECT stock FROM public.merch WHERE id = $1`, merchID).Scan(&left))
+	assert.Zero(t, left)
+
+	var spent uint64
+	for _, buyerID := range buyerIDs {
+		spent += 100 - userCoins(t, conn, buyerID)
+	}
+	assert.EqualValues(t, 10, spent)
+}
+
+func TestRepository_BuyMerch_NotFound(t *testing.T) {
+	t.Parallel()
+
+	repo, conn := testRepository(t)
+	ctx := context.Background()
+
+	userID, _ := createTestUser(t, repo, 100)
+
+	name := fmt.Sprintf("test-merch-%d", time.Now().UnixNano())
+	_, err := repo.CreateMerch(ctx, domain.Merch{Name: name, Category: "test", Price: 10})
+	require.NoError(t, err)
+	require.NoError(t, repo.RetireMerch(ctx, name))
+
+	// Неизвестный и снятый с продажи товар — это не «закончился на складе»
+	assert.ErrorIs(t, repo.BuyMerch(ctx, userID, name+"-unknown", 10), usecase.ErrNotFound)
+	assert.ErrorIs(t, repo.BuyMerch(ctx, userID, name, 10), usecase.ErrNotFound)
+	assert.EqualValues(t, 100, userCoins(t, conn, userID))
+}
@@ -0,0 +1,336 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+	"time"
+)
+
+const (
+	insertCoinLot = `
+	INSERT INTO public.coin_lots (user_id, source, amount, remaining)
+	VALUES ($1, $2, $3, $3)`
+	// Трата списывается с партий по порядку начисления: партия расходуется на часть
+	// суммы, которую не покрыли предыдущие партии
+	consumeLots = `
+	WITH ordered AS (
+		SELECT id, remaining, SUM(remaining) OVER (ORDER BY created_at, id) - remaining AS preceding
+		FROM public.coin_lots
+		WHERE user_id = $1 AND remaining > 0 AND pending_transfer_id IS NULL
+	)
+	UPDATE public.coin_lots l
+	SET remaining = l.remaining - LEAST(o.remaining, $2 - o.preceding)
+	FROM ordered o
+	WHERE l.id = o.id AND o.preceding < $2`
+	// Списанное с партий отправителя становится партиями получателя $3 с теми же
+	// источником и датой начисления
+	moveLots = `
+	WITH ordered AS (
+		SELECT id, remaining, SUM(remaining) OVER (ORDER BY created_at, id) - remaining AS preceding
+		FROM public.coin_lots
+		WHERE user_id = $1 AND remaining > 0 AND pending_transfer_id IS NULL
+	),
+	consumed AS (
+		UPDATE public.coin_lots l
+		SET remaining = l.remaining - LEAST(o.remaining, $2 - o.preceding)
+		FROM ordered o
+		WHERE l.id = o.id AND o.preceding < $2
+		RETURNING l.source, l.created_at, LEAST(o.remaining, $2 - o.preceding) AS amount
+	)
+	INSERT INTO public.coin_lots (user_id, source, amount, remaining, created_at, pending_transfer_id)
+	SELECT $3::bigint, source, amount, amount, created_at, NULLIF($4::bigint, 0)
+	FROM consumed`
+	settleHeldLots = `
+	UPDATE public.coin_lots
+	SET user_id = $2, pending_transfer_id = NULL
+	WHERE pending_transfer_id = $1`
+	listCoinLots = `
+	SELECT id, user_id, source, amount, remaining, created_at
+	FROM public.coin_lots
+	WHERE user_id = $1 AND remaining > 0 AND pending_transfer_id IS NULL
+	ORDER BY created_at, id`
+	insertSystemTransaction = `
+	INSERT INTO public.transactions (from_user_id, to_user_id, quantity, kind)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id`
+)
+
+// createCoinLot заводит партию начисленных монет; вызывается в транзакции начисления
+func createCoinLot(ctx context.Context, tx *sql.Tx, userID uint64, source string, amount uint64) error {
+	if _, err := tx.ExecContext(ctx, insertCoinLot, userID, source, amount); err != nil {
+		return fmt.Errorf("ошибка создания партии монет: %w", err)
+	}
+
+	return nil
+}
+
+// consumeCoinLots списывает трату с партий пользователя. Вызывается в транзакции
+// списания после обновления users.coins, поэтому строка пользователя уже заблокирована
+// и партии не меняются параллельно. Сначала расходуются монеты со сроком, а монеты
+// без срока (например, возвраты покупок) — только когда партии закончились.
+func consumeCoinLots(ctx context.Context, tx *sql.Tx, userID uint64, amount uint64) error {
+	if _, err := tx.ExecContext(ctx, consumeLots, userID, amount); err != nil {
+		return fmt.Errorf("ошибка списания с партий монет: %w", err)
+	}
+
+	return nil
+}
+
+// moveCoinLots списывает amount с партий отправителя, как consumeCoinLots, и передаёт
+// списанное toUserID с прежней датой начисления: перевод не продлевает срок монет, в том
+// числе при переводе туда и обратно. pendingTransferID != 0 удерживает партии за
+// ожидающим переводом до решения по нему (settleCoinLots).
+func moveCoinLots(ctx context.Context, tx *sql.Tx, fromUserID, toUserID, amount, pendingTransferID uint64) error {
+	if _, err := tx.ExecContext(ctx, moveLots, fromUserID, amount, toUserID, pendingTransferID); err != nil {
+		return fmt.Errorf("ошибка переноса партий монет: %w", err)
+	}
+
+	return nil
+}
+
+// settleCoinLots отдаёт партии, удержанные за ожидающим переводом, ownerID: получателю
+// при одобрении или отправителю при отклонении и истечении
+func settleCoinLots(ctx context.Context, tx *sql.Tx, pendingTransferID, ownerID uint64) error {
+	if _, err := tx.ExecContext(ctx, settleHeldLots, pendingTransferID, ownerID); err != nil {
+		return fmt.Errorf("ошибка возврата удержанных партий монет: %w", err)
+	}
+
+	return nil
+}
+
+// ListCoinLots отдаёт партии пользователя с неизрасходованным остатком в порядке начисления
+func (r *Repository) ListCoinLots(ctx context.Context, userID uint64) ([]domain.CoinLot, error) {
+	rows, err := r.db.QueryContext(ctx, listCoinLots, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения партий монет: %w", err)
+	}
+	defer rows.Close()
+
+	var lots []domain.CoinLot
+	for rows.Next() {
+		var lot domain.CoinLot
+		if err = rows.Scan(&lot.ID, &lot.UserID, &lot.Source, &lot.Amount, &lot.Remaining, &lot.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка обработки строки: %w", err)
+		}
+		lots = append(lots, lot)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения партий монет: %w", err)
+	}
+
+	return lots, nil
+}
+
+// Пособие получают пользователи, которые входили или обновляли сессию после $2.
+// Первичный ключ allowances не даёт начислить пособие за месяц дважды, даже если
+// задача запущена на нескольких репликах.
+const claimAllowances = `
+	INSERT INTO public.allowances (user_id, period, amount)
+	SELECT u.id, $1::date, $3::int
+	FROM public.users u
+	WHERE u.username <> $4
+		AND EXISTS (SELECT 1 FROM public.refresh_tokens t WHERE t.user_id = u.id AND t.created_at > $2)
+		AND NOT EXISTS (SELECT 1 FROM public.allowances a WHERE a.user_id = u.id AND a.period = $1)
+	ORDER BY u.id
+	LIMIT $5
+	ON CONFLICT (user_id, period) DO NOTHING
+	RETURNING user_id`
+
+// CreditAllowances начисляет пособие за period не более чем limit активным пользователям
+// и возвращает число получателей
+func (r *Repository) CreditAllowances(ctx context.Context, period, activeSince time.Time, amount uint64, limit int) (int, error) {
+	var credited int
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		systemID, err := systemUserID(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, claimAllowances, period, activeSince, amount, domain.SystemUsername, limit)
+		if err != nil {
+			return fmt.Errorf("ошибка выбора получателей пособия: %w", err)
+		}
+		defer rows.Close()
+
+		var userIDs []uint64
+		for rows.Next() {
+			var userID uint64
+			if err = rows.Scan(&userID); err != nil {
+				return fmt.Errorf("ошибка обработки строки: %w", err)
+			}
+			userIDs = append(userIDs, userID)
+		}
+		if err = rows.Err(); err != nil {
+			return fmt.Errorf("ошибка чтения получателей пособия: %w", err)
+		}
+
+		for _, userID := range userIDs {
+			if _, err = tx.ExecContext(ctx, creditCoins, userID, amount); err != nil {
+				return fmt.Errorf("ошибка зачисления пособия: %w", err)
+			}
+
+			var transactionID uint64
+			err = tx.QueryRowContext(ctx, insertSystemTransaction, systemID, userID, amount, domain.TransactionAllowance).
+				Scan(&transactionID)
+			if err != nil {
+				return fmt.Errorf("ошибка записи пособия: %w", err)
+			}
+
+			entry := domain.NewMovement(domain.EntryAllowance, domain.MintAccount, domain.WalletAccount(userID), amount)
+			entry.TransactionID = transactionID
+
+			if err = postLedgerEntry(ctx, tx, entry); err != nil {
+				return err
+			}
+
+			if err = createCoinLot(ctx, tx, userID, domain.LotAllowance, amount); err != nil {
+				return err
+			}
+		}
+
+		credited = len(userIDs)
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return credited, nil
+}
+
+const (
+	listExpiringUsers = `
+	SELECT DISTINCT user_id
+	FROM public.coin_lots
+	WHERE remaining > 0 AND pending_transfer_id IS NULL AND created_at <= $1
+	ORDER BY user_id
+	LIMIT $2`
+	lockUserForExpiry = `SELECT id FROM public.users WHERE id = $1 FOR UPDATE SKIP LOCKED`
+	expireCoinLots    = `
+	WITH due AS (
+		SELECT id, remaining
+		FROM public.coin_lots
+		WHERE user_id = $1 AND remaining > 0 AND pending_transfer_id IS NULL AND created_at <= $2
+	),
+	expired AS (
+		UPDATE public.coin_lots l
+		SET remaining = 0, expired = l.expired + due.remaining
+		FROM due
+		WHERE l.id = due.id
+		RETURNING due.remaining
+	)
+	SELECT COALESCE(SUM(remaining), 0) FROM expired`
+	debitExpiredCoins = `UPDATE public.users SET coins = coins - $2 WHERE id = $1 AND coins >= $2`
+)
+
+// ExpireCoinLots сжигает неизрасходованные остатки партий, начисленных не позже cutoff,
+// не более чем у limit пользователей. Монеты возвращаются на счёт эмиссии, а в истории
+// появляется списание в пользу системного пользователя. Пользователи, чья строка
+// занята параллельной операцией, пропускаются до следующего запуска.
+func (r *Repository) ExpireCoinLots(ctx context.Context, cutoff time.Time, limit int) (int, uint64, error) {
+	var (
+		users int
+		total uint64
+	)
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		systemID, err := systemUserID(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		rows, err := tx.QueryContext(ctx, listExpiringUsers, cutoff, limit)
+		if err != nil {
+			return fmt.Errorf("ошибка выбора партий к сгоранию: %w", err)
+		}
+		defer rows.Close()
+
+		var userIDs []uint64
+		for rows.Next() {
+			var userID uint64
+			if err = rows.Scan(&userID); err != nil {
+				return fmt.Errorf("ошибка обработки строки: %w", err)
+			}
+			userIDs = append(userIDs, userID)
+		}
+		if err = rows.Err(); err != nil {
+			return fmt.Errorf("ошибка чтения партий к сгоранию: %w", err)
+		}
+
+		for _, userID := range userIDs {
+			var locked uint64
+			if err = tx.QueryRowContext(ctx, lockUserForExpiry, userID).Scan(&locked); err != nil {
+				if errors.Is(err, sql.ErrNoRows) {
+					continue
+				}
+				return fmt.Errorf("ошибка блокировки пользователя: %w", err)
+			}
+
+			var amount uint64
+			if err = tx.QueryRowContext(ctx, expireCoinLots, userID, cutoff).Scan(&amount); err != nil {
+				return fmt.Errorf("ошибка сгорания партий: %w", err)
+			}
+
+			if amount == 0 {
+				continue
+			}
+
+			result, err := tx.ExecContext(ctx, debitExpiredCoins, userID, amount)
+			if err != nil {
+				return fmt.Errorf("ошибка списания сгоревших монет: %w", err)
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("ошибка при проверке обновления: %w", err)
+			}
+
+			// Остаток партий не может превышать баланс: траты сначала списываются с партий
+			if rowsAffected == 0 {
+				return fmt.Errorf("остаток партий пользователя %d превышает баланс", userID)
+			}
+
+			var transactionID uint64
+			err = tx.QueryRowContext(ctx, insertSystemTransaction, userID, systemID, amount, domain.TransactionExpiry).
+				Scan(&transactionID)
+			if err != nil {
+				return fmt.Errorf("ошибка записи сгорания: %w", err)
+			}
+
+			entry := domain.NewMovement(domain.EntryExpiry, domain.WalletAccount(userID), domain.MintAccount, amount)
+			entry.TransactionID = transactionID
+
+			if err = postLedgerEntry(ctx, tx, entry); err != nil {
+				return err
+			}
+
+			users++
+			total += amount
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return users, total, nil
+}
+
+func systemUserID(ctx context.Context, tx *sql.Tx) (uint64, error) {
+	var systemID uint64
+	if err := tx.QueryRowContext(ctx, getSystemUserID, domain.SystemUsername).Scan(&systemID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("системный пользователь %q не найден", domain.SystemUsername)
+		}
+		return 0, fmt.Errorf("ошибка получения системного пользователя: %w", err)
+	}
+
+	return systemID, nil
+}
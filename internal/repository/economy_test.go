@@ -0,0 +1,197 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"merch-shop/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// backdateCoinLots сдвигает партии пользователя в прошлое, чтобы они попали под сгорание
+func backdateCoinLots(t *testing.T, conn *sql.DB, userID uint64, age string) time.Time {
+	t.Helper()
+
+	var createdAt time.Time
+	err := conn.QueryRow(`
+		UPDATE public.coin_lots SET created_at = created_at - $2::interval
+		WHERE user_id = $1
+		RETURNING created_at`, userID, age).Scan(&createdAt)
+	require.NoError(t, err)
+
+	return createdAt
+}
+
+func walletBalance(t *testing.T, conn *sql.DB, userID uint64) int64 {
+	t.Helper()
+
+	var balance int64
+	err := conn.QueryRow(`SELECT balance FROM public.ledger_balances WHERE kind = 'wallet' AND user_id = $1`, userID).
+		Scan(&balance)
+	require.NoError(t, err)
+
+	return balance
+}
+
+func TestRepository_ConsumeCoinLots(t *testing.T) {
+	t.Parallel()
+
+	repo, _ := testRepository(t)
+	ctx := context.Background()
+
+	userID, username := createTestUser(t, repo, 100)
+	require.NoError(t, repo.GrantCoins(ctx, []domain.GrantLine{{Username: username, Amount: 50}}, "хакатон", false))
+
+	consume := func(amount uint64) {
+		require.NoError(t, repo.withTx(ctx, func(tx *sql.Tx) error {
+			return consumeCoinLots(ctx, tx, userID, amount)
+		}))
+	}
+
+	// Стартовая партия расходуется целиком, начисление — частично
+	consume(120)
+
+	lots, err := repo.ListCoinLots(ctx, userID)
+	require.NoError(t, err)
+	require.Len(t, lots, 1)
+	assert.Equal(t, domain.LotGrant, lots[0].Source)
+	assert.EqualValues(t, 50, lots[0].Amount)
+	assert.EqualValues(t, 30, lots[0].Remaining)
+
+	// Трата больше остатка партий расходует их до нуля, остальное — монеты без срока
+	consume(40)
+
+	lots, err = repo.ListCoinLots(ctx, userID)
+	require.NoError(t, err)
+	assert.Empty(t, lots)
+}
+
+func TestRepository_TransferCoins_CarriesLotAge(t *testing.T) {
+	t.Parallel()
+
+	repo, conn := testRepository(t)
+	ctx := context.Background()
+
+	aliceID, _ := createTestUser(t, repo, 100)
+	bobID, _ := createTestUser(t, repo, 0)
+	// Моложе срока сгорания, чтобы параллельный тест сгорания не тронул партии
+	signup := backdateCoinLots(t, conn, aliceID, "1 month")
+
+	require.NoError(t, repo.TransferCoins(ctx, aliceID, bobID, 30, "", domain.TransferGuard{}))
+
+	lots, err := repo.ListCoinLots(ctx, bobID)
+	require.NoError(t, err)
+	require.Len(t, lots, 1)
+	assert.Equal(t, domain.LotOpening, lots[0].Source)
+	assert.EqualValues(t, 30, lots[0].Remaining)
+	assert.True(t, signup.Equal(lots[0].CreatedAt))
+
+	// Перевод обратно не продлевает срок
+	require.NoError(t, repo.TransferCoins(ctx, bobID, aliceID, 30, "", domain.TransferGuard{}))
+
+	lots, err = repo.ListCoinLots(ctx, bobID)
+	require.NoError(t, err)
+	assert.Empty(t, lots)
+
+	lots, err = repo.ListCoinLots(ctx, aliceID)
+	require.NoError(t, err)
+	assert.Equal(t, domain.ExpiryPreview{
+		Enabled:  true,
+		Total:    100,
+		Expiring: []domain.ExpiringCoins{{Amount: 100, ExpiresAt: signup.AddDate(0, 6, 0)}},
+	}, domain.PreviewExpiry(lots, 6))
+}
+
+func TestRepository_PendingTransfer_HoldsLots(t *testing.T) {
+	t.Parallel()
+
+	repo, _ := testRepository(t)
+	ctx := context.Background()
+
+	aliceID, _ := createTestUser(t, repo, 100)
+	bobID, _ := createTestUser(t, repo, 0)
+	adminID, _ := createTestUser(t, repo, 0)
+
+	transfer, err := repo.CreatePendingTransfer(ctx, domain.PendingTransfer{
+		FromUserID: aliceID,
+		ToUserID:   bobID,
+		Amount:     40,
+		ExpiresAt:  time.Now().UTC().Add(time.Hour),
+	}, domain.TransferGuard{})
+	require.NoError(t, err)
+
+	// Удержанные партии не тратятся и не видны ни отправителю, ни получателю
+	lots, err := repo.ListCoinLots(ctx, aliceID)
+	require.NoError(t, err)
+	require.Len(t, lots, 1)
+	assert.EqualValues(t, 60, lots[0].Remaining)
+
+	lots, err = repo.ListCoinLots(ctx, bobID)
+	require.NoError(t, err)
+	assert.Empty(t, lots)
+
+	_, err = repo.DecidePendingTransfer(ctx, domain.TransferDecision{
+		TransferID: transfer.ID,
+		ApproverID: adminID,
+		Role:       domain.RoleAdmin,
+		Approve:    true,
+	})
+	require.NoError(t, err)
+
+	lots, err = repo.ListCoinLots(ctx, bobID)
+	require.NoError(t, err)
+	require.Len(t, lots, 1)
+	assert.Equal(t, domain.LotOpening, lots[0].Source)
+	assert.EqualValues(t, 40, lots[0].Remaining)
+}
+
+func TestRepository_ExpireCoinLots(t *testing.T) {
+	t.Parallel()
+
+	repo, conn := testRepository(t)
+	ctx := context.Background()
+
+	aliceID, alice := createTestUser(t, repo, 100)
+	bobID, _ := createTestUser(t, repo, 0)
+
+	// Стартовая партия старше срока, начисление — свежее
+	backdateCoinLots(t, conn, aliceID, "1 year")
+	require.NoError(t, repo.GrantCoins(ctx, []domain.GrantLine{{Username: alice, Amount: 50}}, "хакатон", false))
+
+	// Перевод тратит старую партию и передаёт её срок получателю
+	require.NoError(t, repo.TransferCoins(ctx, aliceID, bobID, 30, "", domain.TransferGuard{}))
+
+	cutoff := time.Now().UTC().AddDate(0, -6, 0)
+
+	users, total, err := repo.ExpireCoinLots(ctx, cutoff, 1000)
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, users, 2)
+	assert.GreaterOrEqual(t, total, uint64(100))
+
+	// У Алисы сгорел остаток стартовой партии, у Боба — полученная от неё часть
+	assert.EqualValues(t, 50, userCoins(t, conn, aliceID))
+	assert.EqualValues(t, 0, userCoins(t, conn, bobID))
+	assert.EqualValues(t, 50, walletBalance(t, conn, aliceID))
+	assert.EqualValues(t, 0, walletBalance(t, conn, bobID))
+
+	lots, err := repo.ListCoinLots(ctx, aliceID)
+	require.NoError(t, err)
+	require.Len(t, lots, 1)
+	assert.Equal(t, domain.LotGrant, lots[0].Source)
+	assert.EqualValues(t, 50, lots[0].Remaining)
+
+	var expired uint64
+	err = conn.QueryRow(`
+		SELECT COALESCE(SUM(quantity), 0) FROM public.transactions
+		WHERE from_user_id = $1 AND kind = 'expiry'`, aliceID).Scan(&expired)
+	require.NoError(t, err)
+	assert.EqualValues(t, 70, expired)
+
+	// Повторный запуск ничего не сжигает второй раз
+	_, _, err = repo.ExpireCoinLots(ctx, cutoff, 1000)
+	require.NoError(t, err)
+	assert.EqualValues(t, 50, userCoins(t, conn, aliceID))
+}
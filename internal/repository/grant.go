@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
+)
+
+const (
+	getSystemUserID = `SELECT id FROM public.users WHERE username = $1`
+	lockGrantees    = `
+	SELECT id, username FROM public.users
+	WHERE username = ANY($1) AND username <> $2
+	ORDER BY id
+	FOR UPDATE`
+	creditCoins      = `UPDATE public.users SET coins = coins + $2 WHERE id = $1`
+	insertGrantEntry = `
+	INSERT INTO public.transactions (from_user_id, to_user_id, quantity, kind, memo)
+	VALUES ($1, $2, $3, 'grant', $4)
+	RETURNING id`
+)
+
+// GrantCoins зачисляет монеты получателям в одной транзакции. Если кого-то из
+// получателей нет, ничего не зачисляется и возвращается *usecase.GrantError.
+// Пробный прогон ключ идемпотентности не занимает: иначе его ответ повторился бы
+// на настоящее начисление с тем же ключом.
+func (r *Repository) GrantCoins(ctx context.Context, lines []domain.GrantLine, reason string, dryRun bool) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		if !dryRun {
+			if err := claimIdempotencyKey(ctx, tx); err != nil {
+				return err
+			}
+		}
+
+		systemID, err := systemUserID(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		usernames := make([]string, 0, len(lines))
+		for _, line := range lines {
+			usernames = append(usernames, line.Username)
+		}
+
+		rows, err := tx.QueryContext(ctx, lockGrantees, usernames, domain.SystemUsername)
+		if err != nil {
+			return fmt.Errorf("ошибка блокировки получателей: %w", err)
+		}
+		defer rows.Close()
+
+		ids := make(map[string]uint64, len(lines))
+		for rows.Next() {
+			var (
+				id       uint64
+				username string
+			)
+			if err = rows.Scan(&id, &username); err != nil {
+				return fmt.Errorf("ошибка обработки строки: %w", err)
+			}
+			ids[username] = id
+		}
+		if err = rows.Err(); err != nil {
+			return fmt.Errorf("ошибка чтения получателей: %w", err)
+		}
+
+		var failures []domain.GrantLineError
+		for _, line := range lines {
+			if _, ok := ids[line.Username]; !ok {
+				failures = append(failures, domain.GrantLineError{Username: line.Username, Reason: domain.LineNotFound})
+			}
+		}
+		if len(failures) > 0 {
+			return &usecase.GrantError{Lines: failures}
+		}
+
+		if dryRun {
+			return nil
+		}
+
+		for _, line := range lines {
+			userID := ids[line.Username]
+
+			if _, err = tx.ExecContext(ctx, creditCoins, userID, line.Amount); err != nil {
+				return fmt.Errorf("ошибка зачисления монет: %w", err)
+			}
+
+			var transactionID uint64
+			err = tx.QueryRowContext(ctx, insertGrantEntry, systemID, userID, line.Amount, reason).Scan(&transactionID)
+			if err != nil {
+				return fmt.Errorf("ошибка записи начисления: %w", err)
+			}
+
+			entry := domain.NewMovement(domain.EntryGrant, domain.MintAccount, domain.WalletAccount(userID), line.Amount)
+			entry.TransactionID = transactionID
+
+			if err = postLedgerEntry(ctx, tx, entry); err != nil {
+				return err
+			}
+
+			if err = createCoinLot(ctx, tx, userID, domain.LotGrant, line.Amount); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/idempotency"
+	"merch-shop/internal/usecase"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_GrantCoins_DryRunDoesNotClaimKey(t *testing.T) {
+	t.Parallel()
+
+	repo, conn := testRepository(t)
+	ctx := context.Background()
+
+	adminID, _ := createTestUser(t, repo, 0)
+	userID, username := createTestUser(t, repo, 0)
+
+	key := domain.IdempotencyKey{
+		Key:         "grant-" + username,
+		UserID:      adminID,
+		RequestHash: "dry-run",
+		ExpiresAt:   time.Now().Add(time.Hour),
+	}
+	lines := []domain.GrantLine{{Username: username, Amount: 100}}
+
+	require.NoError(t, repo.GrantCoins(idempotency.WithKey(ctx, key), lines, "хакатон", true))
+
+	// Middleware пытается сохранить ответ пробного прогона — ключ не занят, сохранять некуда
+	require.NoError(t, repo.SaveIdempotentResponse(ctx, adminID, key.Key, http.StatusOK, []byte("{}")))
+	_, err := repo.GetIdempotentResponse(ctx, adminID, key.Key)
+	assert.ErrorIs(t, err, usecase.ErrNotFound)
+	assert.Zero(t, userCoins(t, conn, userID))
+
+	key.RequestHash = "grant"
+	require.NoError(t, repo.GrantCoins(idempotency.WithKey(ctx, key), lines, "хакатон", false))
+	assert.EqualValues(t, 100, userCoins(t, conn, userID))
+
+	// Повтор настоящего начисления с тем же ключом уже не проходит
+	err = repo.GrantCoins(idempotency.WithKey(ctx, key), lines, "хакатон", false)
+	assert.ErrorIs(t, err, usecase.ErrIdempotencyConflict)
+	assert.EqualValues(t, 100, userCoins(t, conn, userID))
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"merch-shop/internal/domain"
+)
+
+// Фильтры по направлению и контрагенту повторяют правило historyEntry: операция исходящая,
+// только если пользователь её отправитель
+const listTransactions = `
+	SELECT t.id, t.from_user_id, COALESCE(u1.username, ''), COALESCE(u2.username, ''),
+		t.quantity, t.kind, COALESCE(t.memo, ''), t.created_at
+	FROM public.transactions t
+	LEFT JOIN public.users u1 ON t.from_user_id = u1.id
+	LEFT JOIN public.users u2 ON t.to_user_id = u2.id
+	WHERE (t.from_user_id = $1 OR t.to_user_id = $1)
+		AND ($2 = 0 OR t.id < $2)
+		AND ($3 = ''
+			OR ($3 = 'sent' AND t.from_user_id = $1)
+			OR ($3 = 'received' AND t.from_user_id IS DISTINCT FROM $1))
+		AND ($4 = ''
+			OR (t.from_user_id = $1 AND u2.username = $4)
+			OR (t.from_user_id IS DISTINCT FROM $1 AND u1.username = $4))
+		AND ($5::timestamp IS NULL OR t.created_at >= $5)
+		AND ($6::timestamp IS NULL OR t.created_at < $6)
+		AND ($7 = 0 OR t.quantity >= $7)
+		AND ($8 = 0 OR t.quantity <= $8)
+	ORDER BY t.id DESC
+	LIMIT NULLIF($9, 0)`
+
+// transactionRow — строка listTransactions до пересчёта относительно пользователя
+type transactionRow struct {
+	entry      domain.HistoryEntry
+	fromUserID sql.NullInt64
+	fromUser   string
+	toUser     string
+}
+
+// historyEntry определяет направление и контрагента с точки зрения пользователя.
+// Отправитель бывает пустым у операций удалённых пользователей, такие операции входящие
+func historyEntry(userID uint64, row transactionRow) domain.HistoryEntry {
+	entry := row.entry
+
+	if row.fromUserID.Valid && uint64(row.fromUserID.Int64) == userID {
+		entry.Direction = domain.DirectionSent
+		entry.Counterparty = row.toUser
+	} else {
+		entry.Direction = domain.DirectionReceived
+		entry.Counterparty = row.fromUser
+	}
+
+	return entry
+}
+
+// ListTransactions отдаёт операции пользователя от новых к старым; Limit = 0 — без ограничения
+func (r *Repository) ListTransactions(ctx context.Context, userID uint64, filter domain.HistoryFilter) ([]domain.HistoryEntry, error) {
+	rows, err := r.db.QueryContext(ctx, listTransactions,
+		userID, filter.BeforeID, filter.Direction, filter.Counterparty,
+		filter.From, filter.To, filter.MinAmount, filter.MaxAmount, filter.Limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения истории транзакций: %w", err)
+	}
+	defer rows.Close()
+
+	entries := make([]domain.HistoryEntry, 0, filter.Limit)
+	for rows.Next() {
+		var row transactionRow
+		if err := rows.Scan(
+			&row.entry.ID, &row.fromUserID, &row.fromUser, &row.toUser, &row.entry.Amount,
+			&row.entry.Kind, &row.entry.Memo, &row.entry.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка обработки строки: %w", err)
+		}
+		entries = append(entries, historyEntry(userID, row))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения истории транзакций: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"merch-shop/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeDB отдаёт заранее заданные строки на любой запрос и запоминает аргументы —
+// так проверяется разбор результата без настоящего PostgreSQL
+type fakeDB struct {
+	columns []string
+	rows    [][]driver.Value
+	args    []driver.NamedValue
+}
+
+func (f *fakeDB) Connect(context.Context) (driver.Conn, error) { return &fakeConn{db: f}, nil }
+func (f *fakeDB) Driver() driver.Driver                        { return fakeDriver{} }
+
+type fakeDriver struct{}
+
+func (fakeDriver) Open(string) (driver.Conn, error) { return nil, errors.New("not supported") }
+
+type fakeConn struct {
+	db *fakeDB
+}
+
+func (c *fakeConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeConn) Close() error                        { return nil }
+func (c *fakeConn) Begin() (driver.Tx, error)           { return nil, errors.New("not supported") }
+
+func (c *fakeConn) QueryContext(_ context.Context, _ string, args []driver.NamedValue) (driver.Rows, error) {
+	c.db.args = args
+	return &fakeRows{columns: c.db.columns, rows: c.db.rows}, nil
+}
+
+type fakeRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *fakeRows) Columns() []string { return r.columns }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func TestHistoryEntry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name               string
+		row                transactionRow
+		expectDirection    string
+		expectCounterparty string
+	}{
+		{
+			name:               "sent",
+			row:                transactionRow{fromUserID: sql.NullInt64{Int64: 1, Valid: true}, fromUser: "alice", toUser: "bob"},
+			expectDirection:    domain.DirectionSent,
+			expectCounterparty: "bob",
+		},
+		{
+			name:               "received",
+			row:                transactionRow{fromUserID: sql.NullInt64{Int64: 2, Valid: true}, fromUser: "bob", toUser: "alice"},
+			expectDirection:    domain.DirectionReceived,
+			expectCounterparty: "bob",
+		},
+		{
+			name:               "sent to deleted user",
+			row:                transactionRow{fromUserID: sql.NullInt64{Int64: 1, Valid: true}, fromUser: "alice"},
+			expectDirection:    domain.DirectionSent,
+			expectCounterparty: "",
+		},
+		{
+			name:               "received from deleted user",
+			row:                transactionRow{toUser: "alice"},
+			expectDirection:    domain.DirectionReceived,
+			expectCounterparty: "",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			entry := historyEntry(1, tt.row)
+			assert.Equal(t, tt.expectDirection, entry.Direction)
+			assert.Equal(t, tt.expectCounterparty, entry.Counterparty)
+		})
+	}
+}
+
+func TestRepository_GetUserTransactions(t *testing.T) {
+	t.Parallel()
+
+	createdAt := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// Строки listTransactions для пользователя 1: направление считается уже в Go
+	fake := &fakeDB{
+		columns: []string{"id", "from_user_id", "from_user", "to_user", "amount", "kind", "memo", "created_at"},
+		rows: [][]driver.Value{
+			{int64(5), nil, "", "alice", int64(5), "transfer", "", createdAt},
+			{int64(4), int64(99), "system", "alice", int64(80), "refund", "", createdAt},
+			{int64(3), int64(1), "alice", "carol", int64(15), "transfer", "", createdAt},
+			{int64(2), int64(2), "bob", "alice", int64(30), "transfer", "за пиццу", createdAt},
+			{int64(1), int64(1), "alice", "bob", int64(10), "transfer", "", createdAt},
+		},
+	}
+
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	history, err := New(db).GetUserTransactions(context.Background(), 1, 20)
+	require.NoError(t, err)
+
+	assert.Equal(t, domain.CoinHistory{
+		Received: []domain.CoinTransaction{
+			{ID: 5, Amount: 5, CreatedAt: createdAt},
+			{ID: 4, FromUser: domain.SystemUsername, Amount: 80, Kind: domain.TransactionRefund, CreatedAt: createdAt},
+			{ID: 2, FromUser: "bob", Amount: 30, Memo: "за пиццу", CreatedAt: createdAt},
+		},
+		Sent: []domain.CoinTransaction{
+			{ID: 3, ToUser: "carol", Amount: 15, CreatedAt: createdAt},
+			{ID: 1, ToUser: "bob", Amount: 10, CreatedAt: createdAt},
+		},
+	}, history)
+
+	require.Len(t, fake.args, 9)
+	assert.EqualValues(t, 1, fake.args[0].Value)
+	assert.EqualValues(t, 20, fake.args[8].Value)
+}
+
+func TestRepository_GetUserTransactions_Empty(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeDB{
+		columns: []string{"id", "from_user_id", "from_user", "to_user", "amount", "kind", "memo", "created_at"},
+	}
+
+	db := sql.OpenDB(fake)
+	defer db.Close()
+
+	history, err := New(db).GetUserTransactions(context.Background(), 1, 0)
+	require.NoError(t, err)
+
+	// Пустые списки, а не null — клиенты API на это рассчитывают
+	assert.NotNil(t, history.Received)
+	assert.NotNil(t, history.Sent)
+	assert.Empty(t, history.Received)
+	assert.Empty(t, history.Sent)
+}
+
+func TestRepository_ListTransactions_Filters(t *testing.T) {
+	t.Parallel()
+
+	repo, _ := testRepository(t)
+	ctx := context.Background()
+
+	aliceID, alice := createTestUser(t, repo, 100)
+	bobID, bob := createTestUser(t, repo, 100)
+	carolID, carol := createTestUser(t, repo, 100)
+
+	require.NoError(t, repo.TransferCoins(ctx, aliceID, bobID, 10, "", domain.TransferGuard{}))
+	require.NoError(t, repo.TransferCoins(ctx, bobID, aliceID, 30, "", domain.TransferGuard{}))
+	require.NoError(t, repo.TransferCoins(ctx, aliceID, carolID, 15, "", domain.TransferGuard{}))
+
+	list := func(filter domain.HistoryFilter) []domain.HistoryEntry {
+		entries, err := repo.ListTransactions(ctx, aliceID, filter)
+		require.NoError(t, err)
+		return entries
+	}
+
+	entries := list(domain.HistoryFilter{})
+	require.Len(t, entries, 3)
+	assert.Equal(t, domain.DirectionSent, entries[0].Direction)
+	assert.Equal(t, carol, entries[0].Counterparty)
+	assert.Equal(t, domain.DirectionReceived, entries[1].Direction)
+	assert.Equal(t, bob, entries[1].Counterparty)
+
+	entries = list(domain.HistoryFilter{Direction: domain.DirectionSent})
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, domain.DirectionSent, entry.Direction)
+	}
+
+	// Контрагент ищется с обеих сторон перевода
+	entries = list(domain.HistoryFilter{Counterparty: bob})
+	require.Len(t, entries, 2)
+
+	entries = list(domain.HistoryFilter{Direction: domain.DirectionReceived, Counterparty: bob})
+	require.Len(t, entries, 1)
+	assert.EqualValues(t, 30, entries[0].Amount)
+
+	// Собственное имя контрагентом не считается
+	assert.Empty(t, list(domain.HistoryFilter{Counterparty: alice}))
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/idempotency"
+	"merch-shop/internal/usecase"
+)
+
+const (
+	deleteExpiredKeys = `DELETE FROM public.idempotency_keys WHERE user_id = $1 AND expires_at < NOW()`
+	claimKey          = `
+	INSERT INTO public.idempotency_keys (user_id, key, request_hash, expires_at)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (user_id, key) DO NOTHING`
+)
+
+// claimIdempotencyKey резервирует ключ из контекста внутри транзакции операции:
+// при откате операции ключ освобождается, при фиксации — фиксируется вместе с ней
+func claimIdempotencyKey(ctx context.Context, tx *sql.Tx) error {
+	key, ok := idempotency.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	if _, err := tx.ExecContext(ctx, deleteExpiredKeys, key.UserID); err != nil {
+		return fmt.Errorf("ошибка очистки ключей идемпотентности: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, claimKey, key.UserID, key.Key, key.RequestHash, key.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения ключа идемпотентности: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке обновления: %w", err)
+	}
+
+	// Ключ уже занят параллельным запросом
+	if rowsAffected == 0 {
+		return usecase.ErrIdempotencyConflict
+	}
+
+	setIdempotencyClaim(ctx, true)
+
+	return nil
+}
+
+// setIdempotencyClaim отмечает, зарезервировал ли запрос ключ. withTx снимает отметку
+// при откате: вместе с транзакцией пропадает и резерв
+func setIdempotencyClaim(ctx context.Context, claimed bool) {
+	if key, ok := idempotency.FromContext(ctx); ok && key.Claimed != nil {
+		*key.Claimed = claimed
+	}
+}
+
+const getIdempotentResponse = `
+	SELECT request_hash, COALESCE(response_status, 0), response_body
+	FROM public.idempotency_keys
+	WHERE user_id = $1 AND key = $2 AND expires_at > NOW()`
+
+func (r *Repository) GetIdempotentResponse(ctx context.Context, userID uint64, key string) (domain.IdempotentResponse, error) {
+	var response domain.IdempotentResponse
+
+	err := r.db.QueryRowContext(ctx, getIdempotentResponse, userID, key).
+		Scan(&response.RequestHash, &response.Status, &response.Body)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.IdempotentResponse{}, usecase.ErrNotFound
+		}
+		return domain.IdempotentResponse{}, fmt.Errorf("ошибка получения ключа идемпотентности: %w", err)
+	}
+
+	return response, nil
+}
+
+// Ответ сохраняет только запрос, зарезервировавший ключ, поэтому чужой ответ
+// не может оказаться записан поверх
+const saveIdempotentResponse = `
+	UPDATE public.idempotency_keys
+	SET response_status = $3, response_body = $4
+	WHERE user_id = $1 AND key = $2 AND response_status IS NULL`
+
+func (r *Repository) SaveIdempotentResponse(ctx context.Context, userID uint64, key string, status int, body []byte) error {
+	if _, err := r.db.ExecContext(ctx, saveIdempotentResponse, userID, key, status, body); err != nil {
+		return fmt.Errorf("ошибка сохранения ответа: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/idempotency"
+	"merch-shop/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository_ClaimIdempotencyKey_MarksClaim(t *testing.T) {
+	t.Parallel()
+
+	repo, _ := testRepository(t)
+	ctx := context.Background()
+
+	aliceID, alice := createTestUser(t, repo, 100)
+	bobID, _ := createTestUser(t, repo, 0)
+
+	transfer := func(amount uint64) (bool, error) {
+		var claimed bool
+		key := domain.IdempotencyKey{
+			Key:         "transfer-" + alice,
+			UserID:      aliceID,
+			RequestHash: "transfer",
+			ExpiresAt:   time.Now().Add(time.Hour),
+			Claimed:     &claimed,
+		}
+
+		err := repo.TransferCoins(idempotency.WithKey(ctx, key), aliceID, bobID, amount, "", domain.TransferGuard{})
+		return claimed, err
+	}
+
+	// Резерв в откаченной транзакции не считается: ключ остаётся свободным
+	claimed, err := transfer(1000)
+	assert.ErrorIs(t, err, usecase.ErrNoCoins)
+	assert.False(t, claimed)
+
+	claimed, err = transfer(10)
+	require.NoError(t, err)
+	assert.True(t, claimed)
+
+	// Повтор ключ не получает и не должен сохранять свой ответ
+	claimed, err = transfer(10)
+	assert.ErrorIs(t, err, usecase.ErrIdempotencyConflict)
+	assert.False(t, claimed)
+}
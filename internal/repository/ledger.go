@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+)
+
+const (
+	// Кошелёк заводится при первой операции, если его ещё нет
+	upsertWalletAccount = `
+	INSERT INTO public.ledger_accounts (kind, user_id) VALUES ('wallet', $1)
+	ON CONFLICT (user_id) DO UPDATE SET user_id = EXCLUDED.user_id
+	RETURNING id`
+	getLedgerAccount  = `SELECT id FROM public.ledger_accounts WHERE kind = $1`
+	insertLedgerEntry = `
+	INSERT INTO public.ledger_entries (kind, transaction_id, order_id)
+	VALUES ($1, NULLIF($2::bigint, 0), NULLIF($3::bigint, 0))
+	RETURNING id`
+	insertPosting = `INSERT INTO public.ledger_postings (entry_id, account_id, amount) VALUES ($1, $2, $3)`
+)
+
+// postLedgerEntry записывает операцию в главную книгу внутри транзакции, которая
+// меняет балансы. Несбалансированная запись отклоняется здесь и триггером в базе.
+func postLedgerEntry(ctx context.Context, tx *sql.Tx, entry domain.LedgerEntry) error {
+	if !entry.Balanced() {
+		return fmt.Errorf("запись главной книги %q не сбалансирована", entry.Kind)
+	}
+
+	var entryID uint64
+	err := tx.QueryRowContext(ctx, insertLedgerEntry, entry.Kind, entry.TransactionID, entry.OrderID).Scan(&entryID)
+	if err != nil {
+		return fmt.Errorf("ошибка создания записи главной книги: %w", err)
+	}
+
+	for _, posting := range entry.Postings {
+		accountID, err := ledgerAccountID(ctx, tx, posting.Account)
+		if err != nil {
+			return err
+		}
+
+		if _, err = tx.ExecContext(ctx, insertPosting, entryID, accountID, posting.Amount); err != nil {
+			return fmt.Errorf("ошибка создания проводки: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func ledgerAccountID(ctx context.Context, tx *sql.Tx, account domain.LedgerAccount) (uint64, error) {
+	var id uint64
+
+	if account.Kind == domain.AccountWallet {
+		if err := tx.QueryRowContext(ctx, upsertWalletAccount, account.UserID).Scan(&id); err != nil {
+			return 0, fmt.Errorf("ошибка получения кошелька: %w", err)
+		}
+		return id, nil
+	}
+
+	if err := tx.QueryRowContext(ctx, getLedgerAccount, account.Kind).Scan(&id); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, fmt.Errorf("счёт %q не найден", account.Kind)
+		}
+		return 0, fmt.Errorf("ошибка получения счёта: %w", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type attempt struct {
+	failures    int
+	windowStart time.Time
+	lockedUntil time.Time
+}
+
+// LoginAttempts хранит счётчики неудачных входов в памяти процесса.
+// Подходит только для развёртывания в один экземпляр.
+type LoginAttempts struct {
+	mu       sync.Mutex
+	attempts map[string]*attempt
+}
+
+func NewLoginAttempts() *LoginAttempts {
+	return &LoginAttempts{
+		attempts: make(map[string]*attempt),
+	}
+}
+
+func (s *LoginAttempts) LockedUntil(_ context.Context, key string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.attempts[key]
+	if !ok {
+		return time.Time{}, nil
+	}
+
+	return a.lockedUntil, nil
+}
+
+func (s *LoginAttempts) RegisterFailure(_ context.Context, key string, window time.Duration) (int, error) {
+	now := time.Now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.evictStale(now, window)
+
+	a, ok := s.attempts[key]
+	if !ok {
+		a = &attempt{}
+		s.attempts[key] = a
+	}
+
+	if now.Sub(a.windowStart) > window {
+		a.failures = 0
+		a.windowStart = now
+	}
+	a.failures++
+
+	return a.failures, nil
+}
+
+func (s *LoginAttempts) Lock(_ context.Context, key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	a, ok := s.attempts[key]
+	if !ok {
+		a = &attempt{windowStart: time.Now()}
+		s.attempts[key] = a
+	}
+	a.lockedUntil = until
+
+	return nil
+}
+
+func (s *LoginAttempts) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.attempts, key)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *LoginAttempts) evictStale(now time.Time, window time.Duration) {
+	for key, a := range s.attempts {
+		if now.Sub(a.windowStart) > window && now.After(a.lockedUntil) {
+			delete(s.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoginAttempts(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := NewLoginAttempts()
+
+	for i := 1; i <= 3; i++ {
+		failures, err := store.RegisterFailure(ctx, "user:bob", time.Minute)
+		assert.NoError(t, err)
+		assert.Equal(t, i, failures)
+	}
+
+	until := time.Now().Add(time.Minute)
+	assert.NoError(t, store.Lock(ctx, "user:bob", until))
+
+	lockedUntil, err := store.LockedUntil(ctx, "user:bob")
+	assert.NoError(t, err)
+	assert.Equal(t, until, lockedUntil)
+
+	lockedUntil, err = store.LockedUntil(ctx, "user:alice")
+	assert.NoError(t, err)
+	assert.True(t, lockedUntil.IsZero())
+
+	assert.NoError(t, store.Reset(ctx, "user:bob"))
+	failures, err := store.RegisterFailure(ctx, "user:bob", time.Minute)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failures)
+
+	// Неудачи за пределами окна не учитываются
+	failures, err = store.RegisterFailure(ctx, "ip:127.0.0.1", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failures)
+	failures, err = store.RegisterFailure(ctx, "ip:127.0.0.1", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, failures)
+}
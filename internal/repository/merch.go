@@ -0,0 +1,221 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
+	"strings"
+	"time"
+)
+
+const (
+	insertMerch = `
+	INSERT INTO public.merch (name, category, stock, return_window)
+	VALUES ($1, $2, $3, make_interval(hours => $4))
+	RETURNING id`
+	insertMerchPrice = `INSERT INTO public.merch_prices (merch_id, price, effective_from) VALUES ($1, $2, $3)`
+)
+
+func (r *Repository) CreateMerch(ctx context.Context, item domain.Merch) (uint64, error) {
+	var merchID uint64
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, insertMerch, item.Name, item.Category, item.Stock, item.ReturnWindowHours).Scan(&merchID); err != nil {
+			if isUniqueViolation(err) {
+				return usecase.ErrMerchExists
+			}
+			return fmt.Errorf("ошибка создания товара: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, insertMerchPrice, merchID, item.Price, time.Now().UTC()); err != nil {
+			return fmt.Errorf("ошибка сохранения цены: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return merchID, nil
+}
+
+const (
+	lockActiveMerch  = `SELECT id FROM public.merch WHERE name = $1 AND retired_at IS NULL FOR UPDATE`
+	renameMerch      = `UPDATE public.merch SET name = $2 WHERE id = $1`
+	setMerchCategory = `UPDATE public.merch SET category = $2 WHERE id = $1`
+	setReturnWindow  = `UPDATE public.merch SET return_window = make_interval(hours => $2) WHERE id = $1`
+)
+
+func (r *Repository) UpdateMerch(ctx context.Context, name string, req domain.UpdateMerchRequest) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		var merchID uint64
+		if err := tx.QueryRowContext(ctx, lockActiveMerch, name).Scan(&merchID); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return usecase.ErrNotFound
+			}
+			return fmt.Errorf("ошибка получения товара: %w", err)
+		}
+
+		if req.Name != "" && req.Name != name {
+			if _, err := tx.ExecContext(ctx, renameMerch, merchID, req.Name); err != nil {
+				if isUniqueViolation(err) {
+					return usecase.ErrMerchExists
+				}
+				return fmt.Errorf("ошибка переименования товара: %w", err)
+			}
+		}
+
+		if req.Category != "" {
+			if _, err := tx.ExecContext(ctx, setMerchCategory, merchID, req.Category); err != nil {
+				return fmt.Errorf("ошибка изменения категории: %w", err)
+			}
+		}
+
+		if req.ReturnWindowHours != nil {
+			if _, err := tx.ExecContext(ctx, setReturnWindow, merchID, *req.ReturnWindowHours); err != nil {
+				return fmt.Errorf("ошибка изменения срока возврата: %w", err)
+			}
+		}
+
+		if req.Price > 0 {
+			// Дата из запроса уже приведена к UTC в usecase.UpdateMerch
+			effectiveFrom := time.Now().UTC()
+			if req.EffectiveFrom != nil {
+				effectiveFrom = *req.EffectiveFrom
+			}
+
+			if _, err := tx.ExecContext(ctx, insertMerchPrice, merchID, req.Price, effectiveFrom); err != nil {
+				return fmt.Errorf("ошибка сохранения цены: %w", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Товар не удаляется физически: на него ссылаются записи inventory
+const retireMerch = `UPDATE public.merch SET retired_at = NOW() WHERE name = $1 AND retired_at IS NULL`
+
+func (r *Repository) RetireMerch(ctx context.Context, name string) error {
+	result, err := r.db.ExecContext(ctx, retireMerch, name)
+	if err != nil {
+		return fmt.Errorf("ошибка снятия товара с продажи: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке обновления: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return usecase.ErrNotFound
+	}
+
+	return nil
+}
+
+// Для товара без ограничения остатка (stock = NULL) пополнение ничего не меняет
+const restockMerch = `
+	UPDATE public.merch
+	SET stock = stock + $2
+	WHERE name = $1 AND retired_at IS NULL
+	RETURNING stock`
+
+func (r *Repository) RestockMerch(ctx context.Context, name string, quantity uint64) (*uint64, error) {
+	var stock *uint64
+	if err := r.db.QueryRowContext(ctx, restockMerch, name, quantity).Scan(&stock); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, usecase.ErrNotFound
+		}
+		return nil, fmt.Errorf("ошибка пополнения остатка: %w", err)
+	}
+
+	return stock, nil
+}
+
+const setMerchStock = `UPDATE public.merch SET stock = $2 WHERE name = $1 AND retired_at IS NULL`
+
+func (r *Repository) SetMerchStock(ctx context.Context, name string, stock *uint64) error {
+	result, err := r.db.ExecContext(ctx, setMerchStock, name, stock)
+	if err != nil {
+		return fmt.Errorf("ошибка изменения остатка: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке обновления: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return usecase.ErrNotFound
+	}
+
+	return nil
+}
+
+const listMerch = `
+	SELECT m.name, m.category, p.price, m.stock, m.stock IS NULL OR m.stock > 0 AS available
+	FROM public.merch m
+	JOIN public.merch_current_prices p ON p.merch_id = m.id
+	WHERE m.retired_at IS NULL
+		AND ($1 = '' OR m.category = $1)
+		AND ($2 = 0 OR p.price >= $2)
+		AND ($3 = 0 OR p.price <= $3)`
+
+// Для каждой сортировки: условие продолжения после курсора и порядок.
+// Имя уникально, поэтому служит вторым ключом и делает порядок однозначным.
+var merchSorts = map[string]struct {
+	after   string
+	byPrice bool
+	orderBy string
+}{
+	"name":   {after: `m.name > $5`, orderBy: `m.name`},
+	"-name":  {after: `m.name < $5`, orderBy: `m.name DESC`},
+	"price":  {after: `(p.price, m.name) > ($5, $6)`, byPrice: true, orderBy: `p.price, m.name`},
+	"-price": {after: `(p.price, m.name) < ($5, $6)`, byPrice: true, orderBy: `p.price DESC, m.name DESC`},
+}
+
+func (r *Repository) ListMerch(ctx context.Context, filter domain.CatalogFilter) ([]domain.CatalogItem, error) {
+	sort, ok := merchSorts[filter.Sort]
+	if !ok {
+		return nil, fmt.Errorf("неизвестная сортировка: %s", filter.Sort)
+	}
+
+	var query strings.Builder
+	args := []any{filter.Category, filter.MinPrice, filter.MaxPrice, filter.Limit}
+
+	query.WriteString(listMerch)
+	if filter.After != nil {
+		query.WriteString(" AND " + sort.after)
+		if sort.byPrice {
+			args = append(args, filter.After.Price)
+		}
+		args = append(args, filter.After.Name)
+	}
+	query.WriteString(" ORDER BY " + sort.orderBy + " LIMIT $4")
+
+	rows, err := r.db.QueryContext(ctx, query.String(), args...)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения каталога: %w", err)
+	}
+	defer rows.Close()
+
+	items := make([]domain.CatalogItem, 0, filter.Limit)
+	for rows.Next() {
+		var item domain.CatalogItem
+		if err := rows.Scan(&item.Name, &item.Category, &item.Price, &item.Stock, &item.Available); err != nil {
+			return nil, fmt.Errorf("ошибка обработки строки: %w", err)
+		}
+		items = append(items, item)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения каталога: %w", err)
+	}
+
+	return items, nil
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"merch-shop/internal/domain"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func currentMerchPrice(t *testing.T, conn *sql.DB, merchID uint64) uint64 {
+	t.Helper()
+
+	var price uint64
+	require.NoError(t, conn.QueryRow(`SELECT price FROM public.merch_current_prices WHERE merch_id = $1`, merchID).Scan(&price))
+
+	return price
+}
+
+func TestRepository_UpdateMerch_Price(t *testing.T) {
+	t.Parallel()
+
+	repo, conn := testRepository(t)
+	ctx := context.Background()
+
+	name := fmt.Sprintf("test-merch-%d", time.Now().UnixNano())
+	merchID, err := repo.CreateMerch(ctx, domain.Merch{Name: name, Category: "test", Price: 10})
+	require.NoError(t, err)
+	assert.EqualValues(t, 10, currentMerchPrice(t, conn, merchID))
+
+	// Дата в прошлом действует сразу
+	effectiveFrom := time.Now().UTC().Add(-time.Minute)
+	require.NoError(t, repo.UpdateMerch(ctx, name, domain.UpdateMerchRequest{Price: 20, EffectiveFrom: &effectiveFrom}))
+	assert.EqualValues(t, 20, currentMerchPrice(t, conn, merchID))
+
+	// Цена без даты действует сразу
+	require.NoError(t, repo.UpdateMerch(ctx, name, domain.UpdateMerchRequest{Price: 30}))
+	assert.EqualValues(t, 30, currentMerchPrice(t, conn, merchID))
+}
@@ -0,0 +1,369 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
+	"time"
+)
+
+// Строки товаров блокируются в порядке id до блокировки пользователя —
+// в том же порядке, что и в buyMerchQuery, чтобы не было взаимоблокировок
+const lockOrderMerch = `
+	SELECT m.id, m.name, p.price, m.stock
+	FROM public.merch m
+	JOIN public.merch_current_prices p ON p.merch_id = m.id
+	WHERE m.name = ANY($1) AND m.retired_at IS NULL
+	ORDER BY m.id
+	FOR UPDATE OF m`
+
+const lockUserCoins = `SELECT coins FROM public.users WHERE id = $1 FOR UPDATE`
+
+const (
+	insertOrder      = `INSERT INTO public.orders (user_id, total) VALUES ($1, $2) RETURNING id, created_at`
+	deductCoins      = `UPDATE public.users SET coins = coins - $2 WHERE id = $1`
+	decrementStock   = `UPDATE public.merch SET stock = stock - $2 WHERE id = $1`
+	addInventoryItem = `
+	INSERT INTO public.inventory (user_id, merch_id, quantity)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (user_id, merch_id)
+	DO UPDATE SET quantity = inventory.quantity + EXCLUDED.quantity`
+	insertPurchase = `
+	INSERT INTO public.purchases (order_id, user_id, merch_id, quantity, unit_price)
+	VALUES ($1, $2, $3, $4, $5)`
+)
+
+// CreateOrder оформляет заказ в одной транзакции. Если хотя бы одна строка не может
+// быть выполнена, ничего не списывается и возвращается *usecase.OrderError.
+func (r *Repository) CreateOrder(ctx context.Context, userID uint64, lines []domain.OrderLine) (domain.Order, error) {
+	var order domain.Order
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := claimIdempotencyKey(ctx, tx); err != nil {
+			return err
+		}
+
+		merch, err := lockMerch(ctx, tx, lines)
+		if err != nil {
+			return err
+		}
+
+		var balance uint64
+		if err = tx.QueryRowContext(ctx, lockUserCoins, userID).Scan(&balance); err != nil {
+			return fmt.Errorf("ошибка получения баланса: %w", err)
+		}
+
+		var failures []domain.OrderLineError
+		order, failures = domain.PriceOrder(lines, merch, balance)
+		if len(failures) > 0 {
+			return &usecase.OrderError{Lines: failures}
+		}
+
+		if err = tx.QueryRowContext(ctx, insertOrder, userID, order.Total).Scan(&order.ID, &order.CreatedAt); err != nil {
+			return fmt.Errorf("ошибка создания заказа: %w", err)
+		}
+
+		if _, err = tx.ExecContext(ctx, deductCoins, userID, order.Total); err != nil {
+			return fmt.Errorf("ошибка списания монет: %w", err)
+		}
+
+		if err = consumeCoinLots(ctx, tx, userID, order.Total); err != nil {
+			return err
+		}
+
+		for _, item := range order.Items {
+			if _, err = tx.ExecContext(ctx, decrementStock, item.MerchID, item.Quantity); err != nil {
+				return fmt.Errorf("ошибка списания остатка: %w", err)
+			}
+
+			if _, err = tx.ExecContext(ctx, addInventoryItem, userID, item.MerchID, item.Quantity); err != nil {
+				return fmt.Errorf("ошибка добавления в инвентарь: %w", err)
+			}
+
+			if _, err = tx.ExecContext(ctx, insertPurchase, order.ID, userID, item.MerchID, item.Quantity, item.UnitPrice); err != nil {
+				return fmt.Errorf("ошибка сохранения покупки: %w", err)
+			}
+		}
+
+		entry := domain.NewMovement(domain.EntryPurchase, domain.WalletAccount(userID), domain.RevenueAccount, order.Total)
+		entry.OrderID = order.ID
+
+		return postLedgerEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		return domain.Order{}, err
+	}
+
+	return order, nil
+}
+
+func lockMerch(ctx context.Context, tx *sql.Tx, lines []domain.OrderLine) (map[string]domain.MerchSnapshot, error) {
+	names := make([]string, 0, len(lines))
+	for _, line := range lines {
+		names = append(names, line.Item)
+	}
+
+	rows, err := tx.QueryContext(ctx, lockOrderMerch, names)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения товаров: %w", err)
+	}
+	defer rows.Close()
+
+	merch := make(map[string]domain.MerchSnapshot, len(names))
+	for rows.Next() {
+		var item domain.MerchSnapshot
+		if err := rows.Scan(&item.ID, &item.Name, &item.Price, &item.Stock); err != nil {
+			return nil, fmt.Errorf("ошибка обработки строки: %w", err)
+		}
+		merch[item.Name] = item
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения товаров: %w", err)
+	}
+
+	return merch, nil
+}
+
+const listOrders = `
+	SELECT id, total, created_at
+	FROM public.orders
+	WHERE user_id = $1 AND ($2 = 0 OR id < $2)
+	ORDER BY id DESC
+	LIMIT $3`
+
+const listOrderItems = `
+	SELECT p.order_id, m.id, m.name, p.quantity, p.unit_price
+	FROM public.purchases p
+	JOIN public.merch m ON m.id = p.merch_id
+	WHERE p.order_id = ANY($1)
+	ORDER BY p.id`
+
+func (r *Repository) ListOrders(ctx context.Context, userID uint64, filter domain.OrderFilter) ([]domain.Order, error) {
+	rows, err := r.db.QueryContext(ctx, listOrders, userID, filter.BeforeID, filter.Limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения заказов: %w", err)
+	}
+	defer rows.Close()
+
+	orders := make([]domain.Order, 0, filter.Limit)
+	index := make(map[uint64]int, filter.Limit)
+	ids := make([]int64, 0, filter.Limit)
+
+	for rows.Next() {
+		order := domain.Order{Items: make([]domain.OrderItem, 0)}
+		if err := rows.Scan(&order.ID, &order.Total, &order.CreatedAt); err != nil {
+			return nil, fmt.Errorf("ошибка обработки строки: %w", err)
+		}
+		index[order.ID] = len(orders)
+		ids = append(ids, int64(order.ID))
+		orders = append(orders, order)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения заказов: %w", err)
+	}
+
+	if len(orders) == 0 {
+		return orders, nil
+	}
+
+	itemRows, err := r.db.QueryContext(ctx, listOrderItems, ids)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения позиций заказов: %w", err)
+	}
+	defer itemRows.Close()
+
+	for itemRows.Next() {
+		var (
+			orderID uint64
+			item    domain.OrderItem
+		)
+		if err := itemRows.Scan(&orderID, &item.MerchID, &item.Item, &item.Quantity, &item.UnitPrice); err != nil {
+			return nil, fmt.Errorf("ошибка обработки строки: %w", err)
+		}
+
+		i := index[orderID]
+		orders[i].Items = append(orders[i].Items, item)
+	}
+
+	if err = itemRows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения позиций заказов: %w", err)
+	}
+
+	return orders, nil
+}
+
+const lockUserOrder = `SELECT id FROM public.orders WHERE id = $1 AND user_id = $2 FOR UPDATE`
+
+const lockOrderPurchases = `
+	SELECT p.id, p.merch_id, m.name, p.quantity, p.unit_price,
+		p.created_at + COALESCE(m.return_window, make_interval(secs => $2)) >= NOW() AS returnable
+	FROM public.purchases p
+	JOIN public.merch m ON m.id = p.merch_id
+	WHERE p.order_id = $1 AND p.returned_at IS NULL
+	ORDER BY p.id
+	FOR UPDATE OF p`
+
+const (
+	takeFromInventory = `
+	UPDATE public.inventory
+	SET quantity = quantity - $3
+	WHERE user_id = $1 AND merch_id = $2 AND quantity >= $3`
+	dropEmptyInventory = `DELETE FROM public.inventory WHERE user_id = $1 AND merch_id = $2 AND quantity = 0`
+	markReturned       = `UPDATE public.purchases SET returned_at = NOW() WHERE id = $1`
+	restockReturned    = `UPDATE public.merch SET stock = stock + $2 WHERE id = $1`
+	refundCoins        = `UPDATE public.users SET coins = coins + $2 WHERE id = $1`
+	insertRefund       = `
+	INSERT INTO public.transactions (from_user_id, to_user_id, quantity, kind)
+	VALUES ($1, $2, $3, 'refund')
+	RETURNING id`
+)
+
+type returnLine struct {
+	purchaseID uint64
+	item       domain.OrderItem
+	returnable bool
+}
+
+// ReturnOrder возвращает позиции заказа по цене покупки. Позиции без указанных
+// названий возвращаются все; при любой ошибке заказ остаётся без изменений.
+func (r *Repository) ReturnOrder(
+	ctx context.Context,
+	userID, orderID uint64,
+	items []string,
+	defaultWindow time.Duration,
+) (domain.Refund, error) {
+	refund := domain.Refund{OrderID: orderID, Items: make([]domain.OrderItem, 0)}
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		var id uint64
+		if err := tx.QueryRowContext(ctx, lockUserOrder, orderID, userID).Scan(&id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return usecase.ErrOrderNotFound
+			}
+			return fmt.Errorf("ошибка получения заказа: %w", err)
+		}
+
+		lines, err := lockReturnLines(ctx, tx, orderID, items, defaultWindow)
+		if err != nil {
+			return err
+		}
+
+		for _, line := range lines {
+			if !line.returnable {
+				return usecase.ErrReturnWindowExpired
+			}
+
+			result, err := tx.ExecContext(ctx, takeFromInventory, userID, line.item.MerchID, line.item.Quantity)
+			if err != nil {
+				return fmt.Errorf("ошибка списания из инвентаря: %w", err)
+			}
+
+			rowsAffected, err := result.RowsAffected()
+			if err != nil {
+				return fmt.Errorf("ошибка при проверке обновления: %w", err)
+			}
+
+			if rowsAffected == 0 {
+				return usecase.ErrItemNotOwned
+			}
+
+			if _, err = tx.ExecContext(ctx, dropEmptyInventory, userID, line.item.MerchID); err != nil {
+				return fmt.Errorf("ошибка очистки инвентаря: %w", err)
+			}
+
+			if _, err = tx.ExecContext(ctx, markReturned, line.purchaseID); err != nil {
+				return fmt.Errorf("ошибка отметки возврата: %w", err)
+			}
+
+			if _, err = tx.ExecContext(ctx, restockReturned, line.item.MerchID, line.item.Quantity); err != nil {
+				return fmt.Errorf("ошибка возврата на склад: %w", err)
+			}
+
+			refund.Amount += line.item.UnitPrice * line.item.Quantity
+			refund.Items = append(refund.Items, line.item)
+		}
+
+		if _, err = tx.ExecContext(ctx, refundCoins, userID, refund.Amount); err != nil {
+			return fmt.Errorf("ошибка начисления возврата: %w", err)
+		}
+
+		systemID, err := systemUserID(ctx, tx)
+		if err != nil {
+			return err
+		}
+
+		var transactionID uint64
+		if err = tx.QueryRowContext(ctx, insertRefund, systemID, userID, refund.Amount).Scan(&transactionID); err != nil {
+			return fmt.Errorf("ошибка сохранения возврата: %w", err)
+		}
+
+		entry := domain.NewMovement(domain.EntryRefund, domain.RevenueAccount, domain.WalletAccount(userID), refund.Amount)
+		entry.TransactionID = transactionID
+		entry.OrderID = orderID
+
+		return postLedgerEntry(ctx, tx, entry)
+	})
+	if err != nil {
+		return domain.Refund{}, err
+	}
+
+	return refund, nil
+}
+
+func lockReturnLines(
+	ctx context.Context,
+	tx *sql.Tx,
+	orderID uint64,
+	items []string,
+	defaultWindow time.Duration,
+) ([]returnLine, error) {
+	rows, err := tx.QueryContext(ctx, lockOrderPurchases, orderID, defaultWindow.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения позиций заказа: %w", err)
+	}
+	defer rows.Close()
+
+	wanted := make(map[string]bool, len(items))
+	for _, item := range items {
+		wanted[item] = false
+	}
+
+	lines := make([]returnLine, 0)
+	for rows.Next() {
+		var line returnLine
+		if err := rows.Scan(
+			&line.purchaseID, &line.item.MerchID, &line.item.Item,
+			&line.item.Quantity, &line.item.UnitPrice, &line.returnable,
+		); err != nil {
+			return nil, fmt.Errorf("ошибка обработки строки: %w", err)
+		}
+
+		if _, ok := wanted[line.item.Item]; len(items) > 0 && !ok {
+			continue
+		}
+		wanted[line.item.Item] = true
+		lines = append(lines, line)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения позиций заказа: %w", err)
+	}
+
+	// Запрошены позиции, которых нет в заказе или которые уже возвращены
+	for _, found := range wanted {
+		if !found {
+			return nil, usecase.ErrNothingToReturn
+		}
+	}
+
+	if len(lines) == 0 {
+		return nil, usecase.ErrNothingToReturn
+	}
+
+	return lines, nil
+}
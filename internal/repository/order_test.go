@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testReturnWindow = 14 * 24 * time.Hour
+
+func createTestOrder(t *testing.T, repo *Repository, userID uint64) domain.Order {
+	t.Helper()
+
+	order, err := repo.CreateOrder(context.Background(), userID, []domain.OrderLine{
+		{Item: "pen", Quantity: 2},
+		{Item: "cup", Quantity: 1},
+	})
+	require.NoError(t, err)
+
+	return order
+}
+
+func inventoryQuantity(t *testing.T, conn *sql.DB, userID uint64, item string) uint64 {
+	t.Helper()
+
+	var quantity uint64
+	err := conn.QueryRow(`
+		SELECT COALESCE(SUM(i.quantity), 0)
+		FROM public.inventory i
+		JOIN public.merch m ON m.id = i.merch_id
+		WHERE i.user_id = $1 AND m.name = $2`, userID, item).Scan(&quantity)
+	require.NoError(t, err)
+
+	return quantity
+}
+
+func TestRepository_ReturnOrder(t *testing.T) {
+	t.Parallel()
+
+	repo, conn := testRepository(t)
+	ctx := context.Background()
+
+	userID, _ := createTestUser(t, repo, 100)
+	order := createTestOrder(t, repo, userID)
+	require.EqualValues(t, 40, order.Total)
+	require.EqualValues(t, 60, userCoins(t, conn, userID))
+
+	// Частичный возврат не трогает остальные позиции
+	refund, err := repo.ReturnOrder(ctx, userID, order.ID, []string{"pen"}, testReturnWindow)
+	require.NoError(t, err)
+	assert.EqualValues(t, 20, refund.Amount)
+	require.Len(t, refund.Items, 1)
+	assert.Equal(t, "pen", refund.Items[0].Item)
+	assert.EqualValues(t, 80, userCoins(t, conn, userID))
+	assert.Zero(t, inventoryQuantity(t, conn, userID, "pen"))
+	assert.EqualValues(t, 1, inventoryQuantity(t, conn, userID, "cup"))
+
+	// Повторный возврат той же позиции невозможен
+	_, err = repo.ReturnOrder(ctx, userID, order.ID, []string{"pen"}, testReturnWindow)
+	assert.ErrorIs(t, err, usecase.ErrNothingToReturn)
+	assert.EqualValues(t, 80, userCoins(t, conn, userID))
+
+	// Без списка позиций возвращается всё, что осталось
+	refund, err = repo.ReturnOrder(ctx, userID, order.ID, nil, testReturnWindow)
+	require.NoError(t, err)
+	assert.EqualValues(t, 20, refund.Amount)
+	assert.EqualValues(t, 100, userCoins(t, conn, userID))
+	assert.Zero(t, inventoryQuantity(t, conn, userID, "cup"))
+
+	_, err = repo.ReturnOrder(ctx, userID, order.ID, nil, testReturnWindow)
+	assert.ErrorIs(t, err, usecase.ErrNothingToReturn)
+
+	// Возвраты приходят от системного пользователя, как и начисления
+	var senders []string
+	rows, err := conn.Query(`
+		SELECT COALESCE(u.username, '')
+		FROM public.transactions t
+		LEFT JOIN public.users u ON u.id = t.from_user_id
+		WHERE t.kind = 'refund' AND t.to_user_id = $1`, userID)
+	require.NoError(t, err)
+	defer rows.Close()
+
+	for rows.Next() {
+		var sender string
+		require.NoError(t, rows.Scan(&sender))
+		senders = append(senders, sender)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{domain.SystemUsername, domain.SystemUsername}, senders)
+	assert.EqualValues(t, 100, walletBalance(t, conn, userID))
+}
+
+func TestRepository_ReturnOrder_Rejected(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		prepare func(t *testing.T, conn *sql.DB, userID, orderID uint64)
+		// otherUser возвращает заказ от имени другого пользователя
+		otherUser bool
+		items     []string
+		expectErr error
+	}{
+		{
+			name:      "order of another user",
+			otherUser: true,
+			expectErr: usecase.ErrOrderNotFound,
+		},
+		{
+			name:      "item not in order",
+			items:     []string{"book"},
+			expectErr: usecase.ErrNothingToReturn,
+		},
+		{
+			name: "return window expired",
+			prepare: func(t *testing.T, conn *sql.DB, _, orderID uint64) {
+				_, err := conn.Exec(`
+					UPDATE public.purchases SET created_at = created_at - interval '30 days'
+					WHERE order_id = $1`, orderID)
+				require.NoError(t, err)
+			},
+			expectErr: usecase.ErrReturnWindowExpired,
+		},
+		{
+			name: "item no longer owned",
+			prepare: func(t *testing.T, conn *sql.DB, userID, _ uint64) {
+				_, err := conn.Exec(`DELETE FROM public.inventory WHERE user_id = $1`, userID)
+				require.NoError(t, err)
+			},
+			expectErr: usecase.ErrItemNotOwned,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo, conn := testRepository(t)
+			ctx := context.Background()
+
+			userID, _ := createTestUser(t, repo, 100)
+			order := createTestOrder(t, repo, userID)
+
+			if tt.prepare != nil {
+				tt.prepare(t, conn, userID, order.ID)
+			}
+
+			returnerID := userID
+			if tt.otherUser {
+				returnerID, _ = createTestUser(t, repo, 100)
+			}
+
+			_, err := repo.ReturnOrder(ctx, returnerID, order.ID, tt.items, testReturnWindow)
+			assert.ErrorIs(t, err, tt.expectErr)
+
+			// Отклонённый возврат ничего не меняет
+			assert.EqualValues(t, 60, userCoins(t, conn, userID))
+			if tt.otherUser {
+				assert.EqualValues(t, 100, userCoins(t, conn, returnerID))
+			}
+		})
+	}
+}
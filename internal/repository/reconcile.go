@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"merch-shop/internal/domain"
+	"time"
+)
+
+// Баланс по истории: стартовый баланс из главной книги, плюс все входящие
+// (переводы, начисления, пособия, возвраты), минус исходящие переводы, сгоревшие монеты,
+// оплаченные заказы и суммы, удержанные под неодобренные переводы
+const reconcileWallets = `
+	WITH ledger AS (
+		SELECT a.user_id, SUM(p.amount) AS balance
+		FROM public.ledger_accounts a
+		JOIN public.ledger_postings p ON p.account_id = a.id
+		WHERE a.kind = 'wallet'
+		GROUP BY a.user_id
+	),
+	opening AS (
+		SELECT a.user_id, SUM(p.amount) AS amount
+		FROM public.ledger_entries e
+		JOIN public.ledger_postings p ON p.entry_id = e.id
+		JOIN public.ledger_accounts a ON a.id = p.account_id
+		WHERE e.kind = 'opening' AND a.kind = 'wallet'
+		GROUP BY a.user_id
+	),
+	received AS (
+		SELECT to_user_id AS user_id, SUM(quantity) AS amount
+		FROM public.transactions
+		WHERE to_user_id IS NOT NULL
+		GROUP BY to_user_id
+	),
+	sent AS (
+		SELECT from_user_id AS user_id, SUM(quantity) AS amount
+		FROM public.transactions
+		WHERE from_user_id IS NOT NULL AND kind IN ('transfer', 'expiry')
+		GROUP BY from_user_id
+	),
+	held AS (
+		SELECT from_user_id AS user_id, SUM(amount) AS amount
+		FROM public.pending_transfers
+		WHERE status = 'pending'
+		GROUP BY from_user_id
+	),
+	spent AS (
+		SELECT user_id, SUM(total) AS amount
+		FROM public.orders
+		WHERE user_id IS NOT NULL
+		GROUP BY user_id
+	)
+	SELECT u.id, u.username, u.coins,
+		COALESCE(l.balance, 0),
+		COALESCE(o.amount, 0) + COALESCE(r.amount, 0) - COALESCE(s.amount, 0) - COALESCE(sp.amount, 0) - COALESCE(h.amount, 0)
+	FROM public.users u
+	LEFT JOIN ledger l ON l.user_id = u.id
+	LEFT JOIN opening o ON o.user_id = u.id
+	LEFT JOIN received r ON r.user_id = u.id
+	LEFT JOIN sent s ON s.user_id = u.id
+	LEFT JOIN spent sp ON sp.user_id = u.id
+	LEFT JOIN held h ON h.user_id = u.id
+	WHERE u.username <> $1
+	ORDER BY u.id`
+
+const listUnbalancedEntries = `
+	SELECT entry_id
+	FROM public.ledger_postings
+	GROUP BY entry_id
+	HAVING SUM(amount) <> 0
+	ORDER BY entry_id`
+
+const openAnomaly = `
+	INSERT INTO public.anomalies (kind, ref, details)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (kind, ref) WHERE resolved_at IS NULL DO NOTHING`
+
+// ReconcileBalances сверяет кэш баланса каждого кошелька с главной книгой и с
+// пересчётом по истории операций. Читает согласованный снимок в одной транзакции.
+func (r *Repository) ReconcileBalances(ctx context.Context) (domain.ReconciliationReport, error) {
+	report := domain.ReconciliationReport{
+		Mismatches:        []domain.BalanceMismatch{},
+		UnbalancedEntries: []uint64{},
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return report, fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+	defer tx.Rollback()
+
+	report.CheckedAt = time.Now().UTC()
+
+	rows, err := tx.QueryContext(ctx, reconcileWallets, domain.SystemUsername)
+	if err != nil {
+		return report, fmt.Errorf("ошибка сверки балансов: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m domain.BalanceMismatch
+		if err = rows.Scan(&m.UserID, &m.Username, &m.Cached, &m.Ledger, &m.History); err != nil {
+			return report, fmt.Errorf("ошибка обработки строки: %w", err)
+		}
+
+		report.Wallets++
+		if m.Cached != m.Ledger || m.Cached != m.History {
+			report.Mismatches = append(report.Mismatches, m)
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return report, fmt.Errorf("ошибка чтения балансов: %w", err)
+	}
+
+	entries, err := tx.QueryContext(ctx, listUnbalancedEntries)
+	if err != nil {
+		return report, fmt.Errorf("ошибка проверки главной книги: %w", err)
+	}
+	defer entries.Close()
+
+	for entries.Next() {
+		var id uint64
+		if err = entries.Scan(&id); err != nil {
+			return report, fmt.Errorf("ошибка обработки строки: %w", err)
+		}
+		report.UnbalancedEntries = append(report.UnbalancedEntries, id)
+	}
+
+	if err = entries.Err(); err != nil {
+		return report, fmt.Errorf("ошибка чтения главной книги: %w", err)
+	}
+
+	return report, nil
+}
+
+// OpenAnomaly записывает аномалию, если по тому же объекту нет открытой; сообщает, создана ли новая
+func (r *Repository) OpenAnomaly(ctx context.Context, anomaly domain.Anomaly) (bool, error) {
+	details, err := json.Marshal(anomaly.Details)
+	if err != nil {
+		return false, fmt.Errorf("ошибка сериализации аномалии: %w", err)
+	}
+
+	result, err := r.db.ExecContext(ctx, openAnomaly, anomaly.Kind, anomaly.Ref, string(details))
+	if err != nil {
+		return false, fmt.Errorf("ошибка сохранения аномалии: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("ошибка при проверке обновления: %w", err)
+	}
+
+	return rowsAffected > 0, nil
+}
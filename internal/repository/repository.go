@@ -1,9 +1,15 @@
 package repository
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+const uniqueViolationCode = "23505"
+
 type Repository struct {
 	db *sql.DB
 }
@@ -13,3 +19,28 @@ func New(db *sql.DB) *Repository {
 		db: db,
 	}
 }
+
+func (r *Repository) withTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("ошибка начала транзакции: %w", err)
+	}
+
+	if err = fn(tx); err != nil {
+		_ = tx.Rollback()
+		setIdempotencyClaim(ctx, false)
+		return err
+	}
+
+	if err = tx.Commit(); err != nil {
+		setIdempotencyClaim(ctx, false)
+		return fmt.Errorf("ошибка фиксации транзакции: %w", err)
+	}
+
+	return nil
+}
+
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode
+}
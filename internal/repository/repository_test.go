@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/repository/db"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Тесты, которым нужен настоящий PostgreSQL, подключаются к базе из TEST_DATABASE_URL
+// со схемой docker/migrations/init.sql (например, к базе из docker-compose) и
+// пропускаются, если переменная не задана. Тесты создают пользователей и запускают
+// сгорание монет, поэтому рабочая база для них не подходит
+const testDatabaseEnv = "TEST_DATABASE_URL"
+
+func testRepository(t *testing.T) (*Repository, *sql.DB) {
+	t.Helper()
+
+	dsn := os.Getenv(testDatabaseEnv)
+	if dsn == "" {
+		t.Skipf("%s не задан", testDatabaseEnv)
+	}
+
+	conn, err := db.Connect(dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return New(conn), conn
+}
+
+var testUserSeq atomic.Uint64
+
+// createTestUser создаёт пользователя с уникальным именем, чтобы тесты не мешали друг другу
+func createTestUser(t *testing.T, repo *Repository, coins uint64) (uint64, string) {
+	t.Helper()
+
+	username := fmt.Sprintf("test-%d-%d", time.Now().UnixNano(), testUserSeq.Add(1))
+
+	userID, err := repo.CreateUser(context.Background(), domain.Credentials{Username: username, Password: "*"}, coins, "")
+	require.NoError(t, err)
+
+	return userID, username
+}
+
+func userCoins(t *testing.T, conn *sql.DB, userID uint64) uint64 {
+	t.Helper()
+
+	var coins uint64
+	require.NoError(t, conn.QueryRow(`SELECT coins FROM public.users WHERE id = $1`, userID).Scan(&coins))
+
+	return coins
+}
@@ -0,0 +1,193 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
+	"time"
+)
+
+const (
+	scheduledTransferColumns = `
+	s.id, s.owner_id, s.to_user_id, t.username, s.amount, COALESCE(s.memo, ''), s.repeat,
+		s.next_run_at, s.retry_at, s.active, s.failures, COALESCE(s.last_error, ''), s.last_run_at, s.created_at`
+	insertScheduledTransfer = `
+	INSERT INTO public.scheduled_transfers (owner_id, to_user_id, amount, memo, repeat, next_run_at, active)
+	VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6, $7)
+	RETURNING id, created_at`
+	listScheduledTransfers = `SELECT` + scheduledTransferColumns + `
+	FROM public.scheduled_transfers s
+	JOIN public.users t ON t.id = s.to_user_id
+	WHERE s.owner_id = $1
+	ORDER BY s.id`
+	updateScheduledTransfer = `
+	UPDATE public.scheduled_transfers
+	SET to_user_id = $3, amount = $4, memo = NULLIF($5, ''), repeat = $6, next_run_at = $7, active = $8,
+		retry_at = NULL, failures = 0, last_error = NULL
+	WHERE id = $1 AND owner_id = $2
+	RETURNING created_at, last_run_at`
+	deleteScheduledTransfer = `DELETE FROM public.scheduled_transfers WHERE id = $1 AND owner_id = $2`
+	// Срочные переводы занимаются на время аренды: retry_at сдвигается на $2,
+	// и другие реплики их не видят, пока аренда не истечёт
+	claimDueTransfers = `
+	WITH due AS (
+		SELECT id
+		FROM public.scheduled_transfers
+		WHERE active AND COALESCE(retry_at, next_run_at) <= $1
+		ORDER BY COALESCE(retry_at, next_run_at)
+		LIMIT $3
+		FOR UPDATE SKIP LOCKED
+	)
+	UPDATE public.scheduled_transfers s
+	SET retry_at = $2
+	FROM due, public.users t
+	WHERE s.id = due.id AND t.id = s.to_user_id
+	RETURNING` + scheduledTransferColumns
+	// Итог записывается, только если перевод не изменили, пока он был занят
+	completeScheduledRun = `
+	UPDATE public.scheduled_transfers
+	SET next_run_at = $3, retry_at = $4, active = $5, failures = $6, last_error = NULLIF($7, ''), last_run_at = $8
+	WHERE id = $1 AND retry_at = $2`
+)
+
+func (r *Repository) CreateScheduledTransfer(ctx context.Context, transfer domain.ScheduledTransfer) (domain.ScheduledTransfer, error) {
+	err := r.db.QueryRowContext(ctx, insertScheduledTransfer,
+		transfer.OwnerID, transfer.ToUserID, transfer.Amount, transfer.Memo, transfer.Repeat,
+		transfer.NextRunAt, transfer.Active,
+	).Scan(&transfer.ID, &transfer.CreatedAt)
+	if err != nil {
+		return domain.ScheduledTransfer{}, fmt.Errorf("ошибка создания запланированного перевода: %w", err)
+	}
+
+	return transfer, nil
+}
+
+func (r *Repository) ListScheduledTransfers(ctx context.Context, ownerID uint64) ([]domain.ScheduledTransfer, error) {
+	rows, err := r.db.QueryContext(ctx, listScheduledTransfers, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения запланированных переводов: %w", err)
+	}
+	defer rows.Close()
+
+	transfers := make([]domain.ScheduledTransfer, 0)
+	for rows.Next() {
+		transfer, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения запланированных переводов: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// UpdateScheduledTransfer заменяет параметры перевода владельца и сбрасывает счётчик неудач
+func (r *Repository) UpdateScheduledTransfer(ctx context.Context, transfer domain.ScheduledTransfer) (domain.ScheduledTransfer, error) {
+	var lastRunAt sql.NullTime
+
+	err := r.db.QueryRowContext(ctx, updateScheduledTransfer,
+		transfer.ID, transfer.OwnerID, transfer.ToUserID, transfer.Amount, transfer.Memo, transfer.Repeat,
+		transfer.NextRunAt, transfer.Active,
+	).Scan(&transfer.CreatedAt, &lastRunAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.ScheduledTransfer{}, usecase.ErrScheduleNotFound
+		}
+		return domain.ScheduledTransfer{}, fmt.Errorf("ошибка обновления запланированного перевода: %w", err)
+	}
+
+	transfer.Failures = 0
+	transfer.LastError = ""
+	if lastRunAt.Valid {
+		transfer.LastRunAt = &lastRunAt.Time
+	}
+
+	return transfer, nil
+}
+
+func (r *Repository) DeleteScheduledTransfer(ctx context.Context, ownerID, transferID uint64) error {
+	result, err := r.db.ExecContext(ctx, deleteScheduledTransfer, transferID, ownerID)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления запланированного перевода: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке удаления: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return usecase.ErrScheduleNotFound
+	}
+
+	return nil
+}
+
+// ClaimDueTransfers занимает до limit переводов со сроком не позже now до leaseUntil
+func (r *Repository) ClaimDueTransfers(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.ScheduledTransfer, error) {
+	rows, err := r.db.QueryContext(ctx, claimDueTransfers, now, leaseUntil, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения срочных переводов: %w", err)
+	}
+	defer rows.Close()
+
+	var transfers []domain.ScheduledTransfer
+	for rows.Next() {
+		transfer, err := scanScheduledTransfer(rows)
+		if err != nil {
+			return nil, err
+		}
+		transfers = append(transfers, transfer)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка чтения срочных переводов: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// CompleteScheduledRun записывает итог выполнения. Если владелец изменил перевод,
+// пока тот был занят, итог отбрасывается — действуют новые параметры.
+func (r *Repository) CompleteScheduledRun(ctx context.Context, run domain.ScheduledRun) error {
+	retryAt := sql.NullTime{Time: run.RetryAt, Valid: !run.RetryAt.IsZero()}
+
+	_, err := r.db.ExecContext(ctx, completeScheduledRun,
+		run.ID, run.LeaseUntil, run.NextRunAt, retryAt, run.Active, run.Failures, run.LastError, run.RanAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения итога запланированного перевода: %w", err)
+	}
+
+	return nil
+}
+
+func scanScheduledTransfer(row rowScanner) (domain.ScheduledTransfer, error) {
+	var (
+		t                  domain.ScheduledTransfer
+		retryAt, lastRunAt sql.NullTime
+	)
+
+	err := row.Scan(
+		&t.ID, &t.OwnerID, &t.ToUserID, &t.ToUser, &t.Amount, &t.Memo, &t.Repeat,
+		&t.NextRunAt, &retryAt, &t.Active, &t.Failures, &t.LastError, &lastRunAt, &t.CreatedAt,
+	)
+	if err != nil {
+		return domain.ScheduledTransfer{}, fmt.Errorf("ошибка обработки запланированного перевода: %w", err)
+	}
+
+	if retryAt.Valid {
+		t.RetryAt = &retryAt.Time
+	}
+	if lastRunAt.Valid {
+		t.LastRunAt = &lastRunAt.Time
+	}
+
+	return t, nil
+}
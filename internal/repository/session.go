@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
+)
+
+// Заодно вычищаем записи, срок жизни токенов которых уже истёк
+const revokeAccessToken = `
+	WITH cleanup AS (
+		DELETE FROM public.revoked_tokens WHERE expires_at < NOW()
+	)
+	INSERT INTO public.revoked_tokens (jti, user_id, expires_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (jti) DO NOTHING`
+
+func (r *Repository) RevokeAccessToken(ctx context.Context, session domain.Session) error {
+	_, err := r.db.ExecContext(ctx, revokeAccessToken, session.TokenID, session.UserID, session.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("ошибка отзыва access-токена: %w", err)
+	}
+
+	return nil
+}
+
+const isAccessTokenRevoked = `SELECT EXISTS (SELECT 1 FROM public.revoked_tokens WHERE jti = $1)`
+
+func (r *Repository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	var revoked bool
+	if err := r.db.QueryRowContext(ctx, isAccessTokenRevoked, tokenID).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("ошибка проверки отзыва токена: %w", err)
+	}
+
+	return revoked, nil
+}
+
+const getTokenGeneration = `SELECT token_generation FROM public.users WHERE id = $1`
+
+func (r *Repository) GetTokenGeneration(ctx context.Context, userID uint64) (uint64, error) {
+	var generation uint64
+	if err := r.db.QueryRowContext(ctx, getTokenGeneration, userID).Scan(&generation); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, usecase.ErrNotFound
+		}
+		return 0, fmt.Errorf("ошибка получения поколения токенов: %w", err)
+	}
+
+	return generation, nil
+}
+
+const bumpTokenGeneration = `
+	UPDATE public.users
+	SET token_generation = token_generation + 1
+	WHERE id = $1
+	RETURNING token_generation`
+
+const revokeUserRefreshTokens = `
+	UPDATE public.refresh_tokens
+	SET revoked_at = NOW()
+	WHERE user_id = $1 AND revoked_at IS NULL`
+
+func (r *Repository) RevokeUserSessions(ctx context.Context, userID uint64) (uint64, error) {
+	var generation uint64
+
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		if err := tx.QueryRowContext(ctx, bumpTokenGeneration, userID).Scan(&generation); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return usecase.ErrNotFound
+			}
+			return fmt.Errorf("ошибка обновления поколения токенов: %w", err)
+		}
+
+		if _, err := tx.ExecContext(ctx, revokeUserRefreshTokens, userID); err != nil {
+			return fmt.Errorf("ошибка отзыва refresh-токенов: %w", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return generation, nil
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase"
+)
+
+const createRefreshToken = `
+	INSERT INTO public.refresh_tokens (user_id, token_hash, family_id, expires_at)
+	VALUES ($1, $2, $3, $4)`
+
+func (r *Repository) CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	_, err := r.db.ExecContext(ctx, createRefreshToken, token.UserID, token.TokenHash, token.FamilyID, token.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения refresh-токена: %w", err)
+	}
+
+	return nil
+}
+
+const getRefreshToken = `
+	SELECT id, user_id, token_hash, family_id, expires_at, revoked_at
+	FROM public.refresh_tokens
+	WHERE token_hash = $1`
+
+func (r *Repository) GetRefreshToken(ctx context.Context, tokenHash string) (domain.RefreshToken, error) {
+	var (
+		token     domain.RefreshToken
+		revokedAt sql.NullTime
+	)
+
+	err := r.db.QueryRowContext(ctx, getRefreshToken, tokenHash).Scan(
+		&token.ID,
+		&token.UserID,
+		&token.TokenHash,
+		&token.FamilyID,
+		&token.ExpiresAt,
+		&revokedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.RefreshToken{}, usecase.ErrNotFound
+		}
+		return domain.RefreshToken{}, fmt.Errorf("ошибка получения refresh-токена: %w", err)
+	}
+
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+
+	return token, nil
+}
+
+const revokeRefreshToken = `
+	UPDATE public.refresh_tokens
+	SET revoked_at = NOW()
+	WHERE id = $1 AND revoked_at IS NULL`
+
+func (r *Repository) RotateRefreshToken(ctx context.Context, oldTokenID uint64, next domain.RefreshToken) error {
+	return r.withTx(ctx, func(tx *sql.Tx) error {
+		result, err := tx.ExecContext(ctx, revokeRefreshToken, oldTokenID)
+		if err != nil {
+			return fmt.Errorf("ошибка отзыва refresh-токена: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("ошибка при проверке обновления: %w", err)
+		}
+
+		// Токен уже был использован параллельным запросом
+		if rowsAffected == 0 {
+			return usecase.ErrRefreshTokenReused
+		}
+
+		_, err = tx.ExecContext(ctx, createRefreshToken, next.UserID, next.TokenHash, next.FamilyID, next.ExpiresAt)
+		if err != nil {
+			return fmt.Errorf("ошибка сохранения refresh-токена: %w", err)
+		}
+
+		return nil
+	})
+}
+
+const revokeRefreshTokenFamily = `
+	UPDATE public.refresh_tokens
+	SET revoked_at = NOW()
+	WHERE family_id = $1 AND revoked_at IS NULL`
+
+func (r *Repository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	if _, err := r.db.ExecContext(ctx, revokeRefreshTokenFamily, familyID); err != nil {
+		return fmt.Errorf("ошибка отзыва семейства refresh-токенов: %w", err)
+	}
+
+	return nil
+}
@@ -9,17 +9,63 @@ import (
 	"merch-shop/internal/usecase"
 )
 
-const createUser = `INSERT INTO public.users (username, password) VALUES ($1, $2) RETURNING id`
+const createUser = `INSERT INTO public.users (username, password, coins) VALUES ($1, $2, $3) RETURNING id`
 
-func (r *Repository) CreateUser(ctx context.Context, creds domain.Credentials) (uint64, error) {
+const redeemInvite = `
+	UPDATE public.invite_codes
+	SET used_by = $2, used_at = NOW()
+	WHERE code = $1 AND used_at IS NULL AND expires_at > NOW()`
+
+func (r *Repository) CreateUser(ctx context.Context, creds domain.Credentials, coins uint64, inviteCode string) (uint64, error) {
 	var userID uint64
 
-	err := r.db.QueryRowContext(
-		ctx,
-		createUser,
-		creds.Username,
-		creds.Password,
-	).Scan(&userID)
+	err := r.withTx(ctx, func(tx *sql.Tx) error {
+		err := tx.QueryRowContext(
+			ctx,
+			createUser,
+			creds.Username,
+			creds.Password,
+			coins,
+		).Scan(&userID)
+		if err != nil {
+			if isUniqueViolation(err) {
+				return usecase.ErrUserExists
+			}
+			return fmt.Errorf("ошибка создания пользователя: %w", err)
+		}
+
+		// Стартовый баланс выпускается со счёта эмиссии
+		if coins > 0 {
+			entry := domain.NewMovement(domain.EntryOpening, domain.MintAccount, domain.WalletAccount(userID), coins)
+			if err = postLedgerEntry(ctx, tx, entry); err != nil {
+				return err
+			}
+
+			if err = createCoinLot(ctx, tx, userID, domain.LotOpening, coins); err != nil {
+				return err
+			}
+		}
+
+		if inviteCode == "" {
+			return nil
+		}
+
+		result, err := tx.ExecContext(ctx, redeemInvite, inviteCode, userID)
+		if err != nil {
+			return fmt.Errorf("ошибка погашения приглашения: %w", err)
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("ошибка при проверке обновления: %w", err)
+		}
+
+		if rowsAffected == 0 {
+			return usecase.ErrInvalidInvite
+		}
+
+		return nil
+	})
 	if err != nil {
 		return 0, err
 	}
@@ -27,7 +73,17 @@ func (r *Repository) CreateUser(ctx context.Context, creds domain.Credentials) (
 	return userID, nil
 }
 
-const getUserByUsername = `SELECT id, username, password  FROM public.users WHERE username = $1`
+const createInvite = `INSERT INTO public.invite_codes (code, created_by, expires_at) VALUES ($1, $2, $3)`
+
+func (r *Repository) CreateInvite(ctx context.Context, invite domain.Invite) error {
+	if _, err := r.db.ExecContext(ctx, createInvite, invite.Code, invite.CreatedBy, invite.ExpiresAt); err != nil {
+		return fmt.Errorf("ошибка создания приглашения: %w", err)
+	}
+
+	return nil
+}
+
+const getUserByUsername = `SELECT id, username, password, role, token_generation FROM public.users WHERE username = $1`
 
 func (r *Repository) GetUserByUsername(ctx context.Context, username string) (domain.User, error) {
 	var (
@@ -35,7 +91,7 @@ func (r *Repository) GetUserByUsername(ctx context.Context, username string) (do
 		storedPassword string
 	)
 
-	if err := r.db.QueryRowContext(ctx, getUserByUsername, username).Scan(&result.ID, &result.Credentials.Username, &storedPassword); err != nil {
+	if err := r.db.QueryRowContext(ctx, getUserByUsername, username).Scan(&result.ID, &result.Credentials.Username, &storedPassword, &result.Role, &result.TokenGeneration); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.User{}, usecase.ErrNotFound
 		}
@@ -47,14 +103,45 @@ func (r *Repository) GetUserByUsername(ctx context.Context, username string) (do
 	return result, nil
 }
 
-const getUserByID = `SELECT coins, username FROM public.users WHERE id = $1`
+const updatePassword = `UPDATE public.users SET password = $2 WHERE id = $1`
+
+func (r *Repository) UpdatePassword(ctx context.Context, userID uint64, passwordHash string) error {
+	if _, err := r.db.ExecContext(ctx, updatePassword, userID, passwordHash); err != nil {
+		return fmt.Errorf("ошибка обновления пароля: %w", err)
+	}
+
+	return nil
+}
+
+const setUserRole = `UPDATE public.users SET role = $2 WHERE id = $1`
+
+func (r *Repository) SetUserRole(ctx context.Context, userID uint64, role domain.Role) error {
+	result, err := r.db.ExecContext(ctx, setUserRole, userID, role)
+	if err != nil {
+		return fmt.Errorf("ошибка изменения роли: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при проверке обновления: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return usecase.ErrNotFound
+	}
+
+	return nil
+}
+
+const getUserByID = `
+	SELECT id, coins, username, role, token_generation, COALESCE(created_at, 'epoch')
+	FROM public.users
+	WHERE id = $1`
 
 func (r *Repository) GetUserByID(ctx context.Context, userID uint64) (domain.User, error) {
 	var result domain.User
 
-	fmt.Println(userID)
-
-	if err := r.db.QueryRowContext(ctx, getUserByID, userID).Scan(&result.Coins, &result.Credentials.Username); err != nil {
+	if err := r.db.QueryRowContext(ctx, getUserByID, userID).Scan(&result.ID, &result.Coins, &result.Credentials.Username, &result.Role, &result.TokenGeneration, &result.CreatedAt); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return domain.User{}, usecase.ErrNotFound
 		}
@@ -92,49 +179,21 @@ func (r *Repository) GetUserInventory(ctx context.Context, userID uint64) ([]dom
 	return inventory, nil
 }
 
-const getUserTransactions = `
-	SELECT u1.username AS from_user, u2.username AS to_user, t.quantity
-	FROM public.transactions t
-	LEFT JOIN public.users u1 ON t.from_user_id = u1.id
-	LEFT JOIN public.users u2 ON t.to_user_id = u2.id
-	WHERE t.from_user_id = $1 OR t.to_user_id = $1`
-
-func (r *Repository) GetUserTransactions(ctx context.Context, userID uint64) (domain.CoinHistory, error) {
-	rows, err := r.db.QueryContext(ctx, getUserTransactions, userID)
+// История для /api/info строится тем же запросом, что и /api/history,
+// чтобы направление и контрагент определялись в одном месте
+func (r *Repository) GetUserTransactions(ctx context.Context, userID uint64, limit int) (domain.CoinHistory, error) {
+	entries, err := r.ListTransactions(ctx, userID, domain.HistoryFilter{Limit: limit})
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return domain.CoinHistory{}, nil
-		}
-		return domain.CoinHistory{}, fmt.Errorf("ошибка получения истории транзакций: %w", err)
+		return domain.CoinHistory{}, err
 	}
-	defer rows.Close()
 
 	history := domain.CoinHistory{
 		Received: []domain.CoinTransaction{},
 		Sent:     []domain.CoinTransaction{},
 	}
 
-	for rows.Next() {
-		var (
-			fromUser, toUser sql.NullString
-			amount           int
-		)
-
-		if err := rows.Scan(&fromUser, &toUser, &amount); err != nil {
-			return domain.CoinHistory{}, fmt.Errorf("ошибка обработки строки: %w", err)
-		}
-
-		if fromUser.Valid && fromUser.String != "" && toUser.String == "" {
-			history.Sent = append(history.Sent, domain.CoinTransaction{
-				UserName: fromUser.String,
-				Amount:   amount,
-			})
-		} else if toUser.Valid && toUser.String != "" && fromUser.String == "" {
-			history.Received = append(history.Received, domain.CoinTransaction{
-				UserName: toUser.String,
-				Amount:   amount,
-			})
-		}
+	for _, entry := range entries {
+		history.Add(entry)
 	}
 
 	return history, nil
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+)
+
+// SetUserRole меняет роль пользователя. Роль зашита в access-токен, поэтому
+// после смены все сессии пользователя завершаются.
+func (u *UseCase) SetUserRole(ctx context.Context, username string, role domain.Role) error {
+	if !role.Valid() {
+		return ErrInvalidRole
+	}
+
+	user, err := u.userByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	if user.Role == role {
+		return nil
+	}
+
+	if err = u.repo.SetUserRole(ctx, user.ID, role); err != nil {
+		return fmt.Errorf("repo.SetUserRole: %w", err)
+	}
+
+	return u.RevokeUserSessions(ctx, user.ID)
+}
+
+func (u *UseCase) RevokeSessionsByUsername(ctx context.Context, username string) error {
+	user, err := u.userByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	return u.RevokeUserSessions(ctx, user.ID)
+}
+
+func (u *UseCase) userByUsername(ctx context.Context, username string) (domain.User, error) {
+	user, err := u.repo.GetUserByUsername(ctx, username)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return domain.User{}, ErrUserNotFound
+		}
+		return domain.User{}, fmt.Errorf("repo.GetUserByUsername: %w", err)
+	}
+
+	return user, nil
+}
+
+// SetUserManager назначает руководителя, который одобряет крупные переводы пользователя.
+// Пустое имя руководителя снимает назначение.
+func (u *UseCase) SetUserManager(ctx context.Context, username, managerUsername string) error {
+	user, err := u.userByUsername(ctx, username)
+	if err != nil {
+		return err
+	}
+
+	var managerID uint64
+	if managerUsername != "" {
+		manager, err := u.userByUsername(ctx, managerUsername)
+		if err != nil {
+			return err
+		}
+
+		if manager.ID == user.ID {
+			return ErrInvalidManager
+		}
+		managerID = manager.ID
+	}
+
+	if err = u.repo.SetUserManager(ctx, user.ID, managerID); err != nil {
+		return fmt.Errorf("repo.SetUserManager: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseCase_SetUserRole(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name         string
+		role         domain.Role
+		currentRole  domain.Role
+		lookupErr    error
+		expectUpdate bool
+		expectErr    error
+	}{
+		{
+			name:         "Promote to admin",
+			role:         domain.RoleAdmin,
+			currentRole:  domain.RoleUser,
+			expectUpdate: true,
+		},
+		{
+			name:        "Same role",
+			role:        domain.RoleAuditor,
+			currentRole: domain.RoleAuditor,
+		},
+		{
+			name:      "Unknown role",
+			role:      "owner",
+			expectErr: ErrInvalidRole,
+		},
+		{
+			name:      "User not found",
+			role:      domain.RoleAdmin,
+			lookupErr: ErrNotFound,
+			expectErr: ErrUserNotFound,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, Config{SessionCacheTTL: time.Minute})
+
+			if tt.role.Valid() {
+				mockRepo.On("GetUserByUsername", ctx, "testuser").
+					Return(domain.User{ID: 1, Role: tt.currentRole}, tt.lookupErr).Once()
+			}
+
+			if tt.expectUpdate {
+				mockRepo.On("SetUserRole", ctx, uint64(1), tt.role).Return(nil).Once()
+				mockRepo.On("RevokeUserSessions", ctx, uint64(1)).Return(uint64(3), nil).Once()
+			}
+
+			err := useCase.SetUserRole(ctx, "testuser", tt.role)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
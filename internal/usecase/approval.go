@@ -0,0 +1,64 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"merch-shop/internal/domain"
+	"time"
+)
+
+// expireBatchSize ограничивает число переводов, освобождаемых за одну транзакцию
+const expireBatchSize = 100
+
+func (u *UseCase) holdTransfer(ctx context.Context, fromUser, toUser domain.User, req domain.SendCoinRequest, guard domain.TransferGuard) (*domain.PendingTransfer, error) {
+	transfer, err := u.repo.CreatePendingTransfer(ctx, domain.PendingTransfer{
+		FromUserID: fromUser.ID,
+		FromUser:   fromUser.Username,
+		ToUserID:   toUser.ID,
+		ToUser:     toUser.Username,
+		Amount:     req.Amount,
+		Memo:       req.Memo,
+		ExpiresAt:  time.Now().UTC().Add(u.cfg.ApprovalTTL),
+	}, guard)
+	if err != nil {
+		return nil, fmt.Errorf("repo.CreatePendingTransfer: %w", err)
+	}
+
+	return &transfer, nil
+}
+
+// ListApprovals отдаёт переводы, ожидающие решения пользователя
+func (u *UseCase) ListApprovals(ctx context.Context, userID uint64, role domain.Role) ([]domain.PendingTransfer, error) {
+	transfers, err := u.repo.ListPendingApprovals(ctx, userID, role)
+	if err != nil {
+		return nil, fmt.Errorf("repo.ListPendingApprovals: %w", err)
+	}
+
+	return transfers, nil
+}
+
+func (u *UseCase) DecideTransfer(ctx context.Context, decision domain.TransferDecision) (domain.PendingTransfer, error) {
+	transfer, err := u.repo.DecidePendingTransfer(ctx, decision)
+	if err != nil {
+		return domain.PendingTransfer{}, fmt.Errorf("repo.DecidePendingTransfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+// ExpirePendingTransfers освобождает удержания по всем истёкшим переводам
+func (u *UseCase) ExpirePendingTransfers(ctx context.Context) (int, error) {
+	var total int
+
+	for {
+		expired, err := u.repo.ExpirePendingTransfers(ctx, time.Now().UTC(), expireBatchSize)
+		if err != nil {
+			return total, fmt.Errorf("repo.ExpirePendingTransfers: %w", err)
+		}
+
+		total += expired
+		if expired < expireBatchSize {
+			return total, nil
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package usecase
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUseCase_SendCoinApproval(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	from := domain.User{ID: 1, Coins: 1000, Credentials: domain.Credentials{Username: "petrov"}}
+	to := domain.User{ID: 2, Credentials: domain.Credentials{Username: "ivanov"}}
+
+	for _, tt := range []struct {
+		name          string
+		amount        uint64
+		expectPending bool
+	}{
+		{
+			name:   "At threshold is sent immediately",
+			amount: 500,
+		},
+		{
+			name:          "Above threshold waits for approval",
+			amount:        501,
+			expectPending: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, Config{ApprovalThreshold: 500, ApprovalTTL: 72 * time.Hour})
+
+			req := domain.SendCoinRequest{ToUser: "ivanov", Amount: tt.amount, Memo: "за проект"}
+
+			mockRepo.On("GetUserByID", ctx, from.ID).Return(from, nil).Once()
+			mockRepo.On("GetUserByUsername", ctx, "ivanov").Return(to, nil).Once()
+
+			if tt.expectPending {
+				mockRepo.On("CreatePendingTransfer", ctx, mock.MatchedBy(func(p domain.PendingTransfer) bool {
+					ttl := time.Until(p.ExpiresAt)
+					return p.FromUserID == 1 && p.ToUserID == 2 && p.Amount == tt.amount &&
+						p.Memo == req.Memo && ttl > 71*time.Hour && ttl <= 72*time.Hour
+				}), domain.TransferGuard{}).Return(domain.PendingTransfer{ID: 7, Amount: tt.amount, Status: domain.TransferPending}, nil).Once()
+			} else {
+				mockRepo.On("TransferCoins", ctx, from.ID, to.ID, tt.amount, req.Memo, domain.TransferGuard{}).Return(nil).Once()
+			}
+
+			pending, err := useCase.SendCoin(ctx, from.ID, req)
+			assert.NoError(t, err)
+
+			if tt.expectPending {
+				if assert.NotNil(t, pending) {
+					assert.Equal(t, uint64(7), pending.ID)
+					assert.Equal(t, domain.TransferPending, pending.Status)
+				}
+			} else {
+				assert.Nil(t, pending)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUseCase_ExpirePendingTransfers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{})
+
+	mockRepo.On("ExpirePendingTransfers", ctx, mock.Anything, expireBatchSize).Return(expireBatchSize, nil).Once()
+	mockRepo.On("ExpirePendingTransfers", ctx, mock.Anything, expireBatchSize).Return(3, nil).Once()
+
+	expired, err := useCase.ExpirePendingTransfers(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, expireBatchSize+3, expired)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUseCase_SetUserManager(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name         string
+		manager      string
+		managerID    uint64
+		expectUpdate bool
+		expectErr    error
+	}{
+		{
+			name:         "Assign manager",
+			manager:      "boss",
+			managerID:    2,
+			expectUpdate: true,
+		},
+		{
+			name:         "Clear manager",
+			expectUpdate: true,
+		},
+		{
+			name:      "Self as manager",
+			manager:   "worker",
+			managerID: 1,
+			expectErr: ErrInvalidManager,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, Config{})
+
+			lookups := 1
+			if tt.manager == "worker" {
+				lookups = 2
+			} else if tt.manager != "" {
+				mockRepo.On("GetUserByUsername", ctx, tt.manager).Return(domain.User{ID: tt.managerID}, nil).Once()
+			}
+			mockRepo.On("GetUserByUsername", ctx, "worker").Return(domain.User{ID: 1}, nil).Times(lookups)
+
+			if tt.expectUpdate {
+				mockRepo.On("SetUserManager", ctx, uint64(1), tt.managerID).Return(nil).Once()
+			}
+
+			err := useCase.SetUserManager(ctx, "worker", tt.manager)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
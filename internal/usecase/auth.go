@@ -2,53 +2,273 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log/slog"
 	"merch-shop/internal/domain"
 	"regexp"
+	"time"
 )
 
-func (u *UseCase) Login(ctx context.Context, creds domain.Credentials) (string, error) {
+func (u *UseCase) Login(ctx context.Context, creds domain.Credentials, clientIP string) (domain.Tokens, error) {
 	if !validationUsername(creds.Username) {
-		return "", UsernameNotValid
+		return domain.Tokens{}, UsernameNotValid
 	}
 
 	if !validationPassword(creds.Password) {
-		return "", PasswordNotValid
+		return domain.Tokens{}, PasswordNotValid
+	}
+
+	if err := u.checkLockout(ctx, creds.Username, clientIP); err != nil {
+		return domain.Tokens{}, err
+	}
+
+	user, err := u.authenticate(ctx, creds)
+	if errors.Is(err, ErrNotFound) && u.autoRegistrationEnabled() {
+		user = domain.User{Role: domain.RoleUser}
+		user.ID, err = u.createUser(ctx, creds, "")
+	} else if errors.Is(err, ErrNotFound) {
+		err = ErrUnauthorized
 	}
 
-	userID, err := u.CheckCredentials(ctx, creds)
 	if err != nil {
-		if !errors.Is(err, ErrNotFound) {
-			return "", err
+		if errors.Is(err, ErrUnauthorized) {
+			if lockErr := u.registerFailedLogin(ctx, creds.Username, clientIP); lockErr != nil {
+				slog.Error("registerFailedLogin", "error", lockErr)
+			}
 		}
+		return domain.Tokens{}, err
+	}
+
+	// Счётчик по IP не сбрасываем, иначе перебор можно перемежать входами в свой аккаунт
+	if err = u.attempts.Reset(ctx, userLoginKey(creds.Username)); err != nil {
+		slog.Error("attempts.Reset", "error", err)
+	}
+
+	return u.issueTokens(ctx, user)
+}
+
+// Register явно создаёт учётную запись. Если включены приглашения, нужен
+// действующий одноразовый код; переданный код проверяется в любом случае.
+func (u *UseCase) Register(ctx context.Context, req domain.RegisterRequest) (domain.Tokens, error) {
+	if !validationUsername(req.Username) {
+		return domain.Tokens{}, UsernameNotValid
+	}
+
+	if !validationPassword(req.Password) {
+		return domain.Tokens{}, PasswordNotValid
+	}
+
+	if u.cfg.InviteRequired && req.InviteCode == "" {
+		return domain.Tokens{}, ErrInviteRequired
+	}
+
+	userID, err := u.createUser(ctx, req.Credentials, req.InviteCode)
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	return u.issueTokens(ctx, domain.User{ID: userID, Role: domain.RoleUser})
+}
+
+func (u *UseCase) CreateInvite(ctx context.Context, userID uint64) (domain.Invite, error) {
+	code, err := randomCode()
+	if err != nil {
+		return domain.Invite{}, err
+	}
+
+	invite := domain.Invite{
+		Code:      code,
+		CreatedBy: userID,
+		ExpiresAt: time.Now().Add(u.cfg.InviteTTL),
+	}
 
-		creds.Password = creds.Password.Secure()
+	if err = u.repo.CreateInvite(ctx, invite); err != nil {
+		return domain.Invite{}, fmt.Errorf("repo.CreateInvite: %w", err)
+	}
+
+	return invite, nil
+}
+
+// Автоматическая регистрация через /api/auth невозможна, если нужны приглашения
+func (u *UseCase) autoRegistrationEnabled() bool {
+	return u.cfg.AutoRegistration && !u.cfg.InviteRequired
+}
+
+func (u *UseCase) createUser(ctx context.Context, creds domain.Credentials, inviteCode string) (uint64, error) {
+	passwordHash, err := u.hasher.Hash(creds.Password.String())
+	if err != nil {
+		return 0, fmt.Errorf("hasher.Hash: %w", err)
+	}
+	creds.Password = domain.Password(passwordHash)
+
+	userID, err := u.repo.CreateUser(ctx, creds, u.cfg.StartingBalance, inviteCode)
+	if err != nil {
+		return 0, fmt.Errorf("repo.CreateUser: %w", err)
+	}
+
+	return userID, nil
+}
+
+func (u *UseCase) issueTokens(ctx context.Context, user domain.User) (domain.Tokens, error) {
+	accessToken, err := u.auth.NewAccessToken(user)
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	refreshToken, err := u.auth.NewRefreshToken()
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	// Первый токен сессии открывает новое семейство, последующие его наследуют
+	tokenHash := hashToken(refreshToken)
+	err = u.repo.CreateRefreshToken(ctx, domain.RefreshToken{
+		UserID:    user.ID,
+		TokenHash: tokenHash,
+		FamilyID:  tokenHash,
+		ExpiresAt: time.Now().Add(u.cfg.RefreshTokenTTL),
+	})
+	if err != nil {
+		return domain.Tokens{}, fmt.Errorf("repo.CreateRefreshToken: %w", err)
+	}
+
+	return domain.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+	}, nil
+}
 
-		userID, err = u.repo.CreateUser(ctx, creds)
-		if err != nil {
-			return "", err
+// Refresh обменивает refresh-токен на новую пару токенов. Каждый refresh-токен
+// одноразовый: повторное предъявление уже использованного отзывает всё семейство.
+func (u *UseCase) Refresh(ctx context.Context, refreshToken string) (domain.Tokens, error) {
+	stored, err := u.repo.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return domain.Tokens{}, ErrInvalidRefreshToken
+		}
+		return domain.Tokens{}, fmt.Errorf("repo.GetRefreshToken: %w", err)
+	}
+
+	if stored.Revoked() {
+		return domain.Tokens{}, u.revokeFamily(ctx, stored.FamilyID)
+	}
+
+	if stored.Expired(time.Now()) {
+		return domain.Tokens{}, ErrInvalidRefreshToken
+	}
+
+	user, err := u.repo.GetUserByID(ctx, stored.UserID)
+	if err != nil {
+		return domain.Tokens{}, fmt.Errorf("repo.GetUserByID: %w", err)
+	}
+
+	accessToken, err := u.auth.NewAccessToken(user)
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	nextToken, err := u.auth.NewRefreshToken()
+	if err != nil {
+		return domain.Tokens{}, err
+	}
+
+	err = u.repo.RotateRefreshToken(ctx, stored.ID, domain.RefreshToken{
+		UserID:    stored.UserID,
+		TokenHash: hashToken(nextToken),
+		FamilyID:  stored.FamilyID,
+		ExpiresAt: time.Now().Add(u.cfg.RefreshTokenTTL),
+	})
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenReused) {
+			return domain.Tokens{}, u.revokeFamily(ctx, stored.FamilyID)
 		}
+		return domain.Tokens{}, fmt.Errorf("repo.RotateRefreshToken: %w", err)
+	}
+
+	return domain.Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: nextToken,
+	}, nil
+}
+
+func (u *UseCase) revokeFamily(ctx context.Context, familyID string) error {
+	slog.Warn("Refresh token reuse detected", "family", familyID)
+
+	if err := u.repo.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+		return fmt.Errorf("repo.RevokeRefreshTokenFamily: %w", err)
 	}
 
-	return u.auth.NewAccessToken(userID)
+	return ErrRefreshTokenReused
+}
+
+func randomCode() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate code: %w", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	h := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(h[:])
 }
 
 func (u *UseCase) CheckCredentials(ctx context.Context, creds domain.Credentials) (uint64, error) {
-	fmt.Println(creds.Username)
+	user, err := u.authenticate(ctx, creds)
+	if err != nil {
+		return 0, err
+	}
+
+	return user.ID, nil
+}
+
+func (u *UseCase) authenticate(ctx context.Context, creds domain.Credentials) (domain.User, error) {
+	if creds.Username == domain.SystemUsername {
+		return domain.User{}, ErrUnauthorized
+	}
+
 	userInfo, err := u.repo.GetUserByUsername(ctx, creds.Username)
 	if err != nil {
 		if errors.Is(err, ErrNotFound) {
-			return 0, ErrNotFound
+			return domain.User{}, ErrNotFound
 		}
-		return 0, fmt.Errorf("repo.GetUserByUsername error: %w", err)
+		return domain.User{}, fmt.Errorf("repo.GetUserByUsername error: %w", err)
+	}
+
+	ok, needsRehash, err := u.hasher.Verify(creds.Password.String(), userInfo.Password.String())
+	if err != nil {
+		return domain.User{}, fmt.Errorf("hasher.Verify: %w", err)
+	}
+
+	if !ok {
+		return domain.User{}, ErrUnauthorized
 	}
 
-	if userInfo.Password.Verify(creds.Password) {
-		return 0, ErrUnauthorized
+	if needsRehash {
+		u.rehashPassword(ctx, userInfo.ID, creds.Password)
 	}
 
-	return userInfo.ID, nil
+	return userInfo, nil
+}
+
+// rehashPassword переводит хеш на текущий алгоритм. Ошибка не мешает входу:
+// пароль будет перехеширован при следующей успешной попытке.
+func (u *UseCase) rehashPassword(ctx context.Context, userID uint64, password domain.Password) {
+	passwordHash, err := u.hasher.Hash(password.String())
+	if err != nil {
+		slog.Error("hasher.Hash", "error", err)
+		return
+	}
+
+	if err = u.repo.UpdatePassword(ctx, userID, passwordHash); err != nil {
+		slog.Error("repo.UpdatePassword", "error", err)
+	}
 }
 
 func validationPassword(password domain.Password) bool {
@@ -6,6 +6,7 @@ import (
 	"merch-shop/internal/domain"
 	"merch-shop/internal/usecase/mocks"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -16,6 +17,7 @@ func TestUseCase_Login(t *testing.T) {
 
 	ctx := context.Background()
 	expectedToken := "mocked_token"
+	expectedRefreshToken := "mocked_refresh_token"
 
 	for _, tt := range []struct {
 		name          string
@@ -79,34 +81,324 @@ func TestUseCase_Login(t *testing.T) {
 
 			mockRepo := new(mocks.Repository)
 			mockAuth := new(mocks.Auth)
-			useCase := &UseCase{repo: mockRepo, auth: mockAuth}
+			mockHasher := new(mocks.PasswordHasher)
+			mockAttempts := new(mocks.LoginAttempts)
+			useCase := &UseCase{
+				repo:     mockRepo,
+				auth:     mockAuth,
+				hasher:   mockHasher,
+				attempts: mockAttempts,
+				cfg:      Config{AutoRegistration: true, StartingBalance: 1000},
+			}
 
 			mockRepo.Test(t)
 			mockAuth.Test(t)
+			mockHasher.Test(t)
+			mockAttempts.Test(t)
+
+			mockAttempts.On("LockedUntil", ctx, mock.Anything).Return(time.Time{}, nil).Twice()
 
 			mockRepo.On("GetUserByUsername", ctx, tt.creds.Username).
 				Return(tt.mockUser, tt.mockUserErr).Once()
 
+			if tt.mockUserErr == nil {
+				mockHasher.On("Verify", tt.creds.Password.String(), tt.mockUser.Password.String()).
+					Return(true, false, nil).Once()
+			}
+
 			if errors.Is(tt.mockUserErr, ErrNotFound) {
-				mockRepo.On("CreateUser", ctx, mock.Anything).
-					Return(tt.mockUserID, tt.mockUserIDErr).Once()
+				mockHasher.On("Hash", tt.creds.Password.String()).
+					Return("hashed_password", nil).Once()
+				mockRepo.On("CreateUser", ctx, domain.Credentials{
+					Username: tt.creds.Username,
+					Password: "hashed_password",
+				}, uint64(1000), "").Return(tt.mockUserID, tt.mockUserIDErr).Once()
 			}
 
 			if tt.mockUserIDErr == nil {
-				mockAuth.On("NewAccessToken", tt.mockUserID).
-					Return(expectedToken, nil).Once()
+				mockAttempts.On("Reset", ctx, "user:"+tt.creds.Username).Return(nil).Once()
+				mockAuth.On("NewAccessToken", mock.MatchedBy(func(user domain.User) bool {
+					return user.ID == tt.mockUserID
+				})).Return(expectedToken, nil).Once()
+				mockAuth.On("NewRefreshToken").
+					Return(expectedRefreshToken, nil).Once()
+				mockRepo.On("CreateRefreshToken", ctx, mock.MatchedBy(func(token domain.RefreshToken) bool {
+					return token.UserID == tt.mockUserID && token.TokenHash == hashToken(expectedRefreshToken)
+				})).Return(nil).Once()
 			}
 
-			token, err := useCase.Login(ctx, tt.creds)
+			tokens, err := useCase.Login(ctx, tt.creds, "10.0.0.1")
 
 			if tt.expectErr {
 				assert.Error(t, err)
-				assert.Empty(t, token)
+				assert.Empty(t, tokens)
 				return
 			}
 
 			assert.NoError(t, err)
-			assert.Equal(t, tt.expectToken, token)
+			assert.Equal(t, tt.expectToken, tokens.AccessToken)
+			assert.Equal(t, expectedRefreshToken, tokens.RefreshToken)
+		})
+	}
+}
+
+func TestUseCase_LoginWithoutAutoRegistration(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	creds := domain.Credentials{Username: "typo", Password: "TestPassword1"}
+
+	for _, tt := range []struct {
+		name string
+		cfg  Config
+	}{
+		{
+			name: "Auto-registration disabled",
+			cfg:  Config{AutoRegistration: false, LoginMaxFailures: 5},
+		},
+		{
+			name: "Invites required",
+			cfg:  Config{AutoRegistration: true, InviteRequired: true, LoginMaxFailures: 5},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			mockAttempts := new(mocks.LoginAttempts)
+			useCase := &UseCase{repo: mockRepo, attempts: mockAttempts, cfg: tt.cfg}
+
+			mockAttempts.On("LockedUntil", ctx, mock.Anything).Return(time.Time{}, nil).Twice()
+			mockRepo.On("GetUserByUsername", ctx, creds.Username).Return(domain.User{}, ErrNotFound).Once()
+			mockRepo.On("SaveFailedLogin", ctx, mock.Anything).Return(nil).Once()
+			mockAttempts.On("RegisterFailure", ctx, mock.Anything, mock.Anything).Return(1, nil).Twice()
+
+			tokens, err := useCase.Login(ctx, creds, "10.0.0.1")
+
+			assert.ErrorIs(t, err, ErrUnauthorized)
+			assert.Empty(t, tokens)
+
+			mockRepo.AssertExpectations(t)
+			mockAttempts.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUseCase_Register(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name        string
+		req         domain.RegisterRequest
+		cfg         Config
+		createErr   error
+		expectCalls bool
+		expectErr   error
+	}{
+		{
+			name: "Registration without invite",
+			req: domain.RegisterRequest{
+				Credentials: domain.Credentials{Username: "newuser", Password: "NewPass123"},
+			},
+			cfg:         Config{StartingBalance: 500},
+			expectCalls: true,
+		},
+		{
+			name: "Registration with invite",
+			req: domain.RegisterRequest{
+				Credentials: domain.Credentials{Username: "newuser", Password: "NewPass123"},
+				InviteCode:  "code",
+			},
+			cfg:         Config{StartingBalance: 500, InviteRequired: true},
+			expectCalls: true,
+		},
+		{
+			name: "Invite required but missing",
+			req: domain.RegisterRequest{
+				Credentials: domain.Credentials{Username: "newuser", Password: "NewPass123"},
+			},
+			cfg:       Config{InviteRequired: true},
+			expectErr: ErrInviteRequired,
+		},
+		{
+			name: "Invalid invite",
+			req: domain.RegisterRequest{
+				Credentials: domain.Credentials{Username: "newuser", Password: "NewPass123"},
+				InviteCode:  "used",
+			},
+			cfg:         Config{StartingBalance: 500},
+			createErr:   ErrInvalidInvite,
+			expectCalls: true,
+			expectErr:   ErrInvalidInvite,
+		},
+		{
+			name: "Username taken",
+			req: domain.RegisterRequest{
+				Credentials: domain.Credentials{Username: "olduser", Password: "NewPass123"},
+			},
+			cfg:         Config{StartingBalance: 500},
+			createErr:   ErrUserExists,
+			expectCalls: true,
+			expectErr:   ErrUserExists,
+		},
+		{
+			name: "Weak password",
+			req: domain.RegisterRequest{
+				Credentials: domain.Credentials{Username: "newuser", Password: "weak"},
+			},
+			expectErr: PasswordNotValid,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			mockAuth := new(mocks.Auth)
+			mockHasher := new(mocks.PasswordHasher)
+			useCase := &UseCase{repo: mockRepo, auth: mockAuth, hasher: mockHasher, cfg: tt.cfg}
+
+			if tt.expectCalls {
+				mockHasher.On("Hash", tt.req.Password.String()).Return("hashed", nil).Once()
+				mockRepo.On("CreateUser", ctx, domain.Credentials{Username: tt.req.Username, Password: "hashed"}, tt.cfg.StartingBalance, tt.req.InviteCode).
+					Return(uint64(5), tt.createErr).Once()
+			}
+
+			if tt.expectErr == nil {
+				mockAuth.On("NewAccessToken", domain.User{ID: 5, Role: domain.RoleUser}).Return("access", nil).Once()
+				mockAuth.On("NewRefreshToken").Return("refresh", nil).Once()
+				mockRepo.On("CreateRefreshToken", ctx, mock.Anything).Return(nil).Once()
+			}
+
+			tokens, err := useCase.Register(ctx, tt.req)
+
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				assert.Empty(t, tokens)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, domain.Tokens{AccessToken: "access", RefreshToken: "refresh"}, tokens)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockAuth.AssertExpectations(t)
+			mockHasher.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUseCase_LoginLockout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	creds := domain.Credentials{Username: "testuser", Password: "TestPassword1"}
+	stored := domain.User{ID: 1, Credentials: domain.Credentials{Username: "testuser", Password: "hash"}}
+	cfg := Config{
+		LoginMaxFailures:   3,
+		LoginFailureWindow: time.Minute,
+		LoginLockoutBase:   time.Minute,
+		LoginLockoutMax:    time.Hour,
+	}
+
+	for _, tt := range []struct {
+		name           string
+		userLocked     time.Time
+		ipLocked       time.Time
+		failures       int
+		expectLock     time.Duration
+		expectErr      error
+		expectRetryMin time.Duration
+	}{
+		{
+			name:           "Locked user is rejected without password check",
+			userLocked:     time.Now().Add(10 * time.Minute),
+			expectErr:      ErrTooManyAttempts,
+			expectRetryMin: 9 * time.Minute,
+		},
+		{
+			name:           "Locked IP is rejected without password check",
+			ipLocked:       time.Now().Add(time.Minute),
+			expectErr:      ErrTooManyAttempts,
+			expectRetryMin: 50 * time.Second,
+		},
+		{
+			name:      "Failure below threshold only counts",
+			failures:  2,
+			expectErr: ErrUnauthorized,
+		},
+		{
+			name:       "Failure at threshold locks for base duration",
+			failures:   3,
+			expectLock: time.Minute,
+			expectErr:  ErrUnauthorized,
+		},
+		{
+			name:       "Further failures double the lockout",
+			failures:   5,
+			expectLock: 4 * time.Minute,
+			expectErr:  ErrUnauthorized,
+		},
+		{
+			name:       "Lockout is capped",
+			failures:   20,
+			expectLock: time.Hour,
+			expectErr:  ErrUnauthorized,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			mockHasher := new(mocks.PasswordHasher)
+			mockAttempts := new(mocks.LoginAttempts)
+			useCase := &UseCase{repo: mockRepo, hasher: mockHasher, attempts: mockAttempts, cfg: cfg}
+
+			mockAttempts.On("LockedUntil", ctx, "user:testuser").Return(tt.userLocked, nil).Once()
+			mockAttempts.On("LockedUntil", ctx, "ip:10.0.0.1").Return(tt.ipLocked, nil).Once()
+
+			if tt.expectRetryMin > 0 {
+				mockRepo.On("SaveFailedLogin", ctx, domain.FailedLogin{
+					Username: "testuser",
+					IP:       "10.0.0.1",
+					Reason:   failedLoginLocked,
+				}).Return(nil).Once()
+			} else {
+				mockRepo.On("GetUserByUsername", ctx, creds.Username).Return(stored, nil).Once()
+				mockHasher.On("Verify", creds.Password.String(), "hash").Return(false, false, nil).Once()
+				mockRepo.On("SaveFailedLogin", ctx, domain.FailedLogin{
+					Username: "testuser",
+					IP:       "10.0.0.1",
+					Reason:   failedLoginInvalidPassword,
+				}).Return(nil).Once()
+
+				for _, key := range []string{"user:testuser", "ip:10.0.0.1"} {
+					mockAttempts.On("RegisterFailure", ctx, key, cfg.LoginFailureWindow).Return(tt.failures, nil).Once()
+					if tt.expectLock > 0 {
+						mockAttempts.On("Lock", ctx, key, mock.MatchedBy(func(until time.Time) bool {
+							return time.Until(until) > tt.expectLock-time.Second && time.Until(until) <= tt.expectLock
+						})).Return(nil).Once()
+					}
+				}
+			}
+
+			tokens, err := useCase.Login(ctx, creds, "10.0.0.1")
+
+			assert.ErrorIs(t, err, tt.expectErr)
+			assert.Empty(t, tokens)
+
+			var lockErr *LockedError
+			if tt.expectRetryMin > 0 {
+				assert.ErrorAs(t, err, &lockErr)
+				assert.Greater(t, lockErr.RetryAfter, tt.expectRetryMin)
+			}
+
+			mockRepo.AssertExpectations(t)
+			mockHasher.AssertExpectations(t)
+			mockAttempts.AssertExpectations(t)
 		})
 	}
 }
@@ -115,12 +407,15 @@ func TestUseCase_CheckCredentials(t *testing.T) {
 	t.Parallel()
 
 	for _, tt := range []struct {
-		name       string
-		creds      domain.Credentials
-		mockReturn domain.User
-		mockError  error
-		expectedID uint64
-		expectErr  bool
+		name         string
+		creds        domain.Credentials
+		mockReturn   domain.User
+		mockError    error
+		verifyOK     bool
+		needsRehash  bool
+		expectedID   uint64
+		expectErr    error
+		expectUpdate bool
 	}{
 		{
 			name: "Valid credentials",
@@ -132,11 +427,45 @@ func TestUseCase_CheckCredentials(t *testing.T) {
 				ID: 1,
 				Credentials: domain.Credentials{
 					Username: "testuser",
-					Password: "testpassword",
+					Password: "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA",
 				},
 			},
+			verifyOK:   true,
 			expectedID: 1,
-			expectErr:  false,
+		},
+		{
+			name: "Legacy hash is upgraded on login",
+			creds: domain.Credentials{
+				Username: "testuser",
+				Password: "testpassword",
+			},
+			mockReturn: domain.User{
+				ID: 1,
+				Credentials: domain.Credentials{
+					Username: "testuser",
+					Password: "n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=",
+				},
+			},
+			verifyOK:     true,
+			needsRehash:  true,
+			expectedID:   1,
+			expectUpdate: true,
+		},
+		{
+			name: "Wrong password",
+			creds: domain.Credentials{
+				Username: "testuser",
+				Password: "wrongpassword",
+			},
+			mockReturn: domain.User{
+				ID: 1,
+				Credentials: domain.Credentials{
+					Username: "testuser",
+					Password: "$argon2id$v=19$m=65536,t=3,p=2$c2FsdA$aGFzaA",
+				},
+			},
+			verifyOK:  false,
+			expectErr: ErrUnauthorized,
 		},
 		{
 			name: "User not found",
@@ -146,8 +475,7 @@ func TestUseCase_CheckCredentials(t *testing.T) {
 			},
 			mockReturn: domain.User{},
 			mockError:  ErrNotFound,
-			expectedID: 0,
-			expectErr:  true,
+			expectErr:  ErrNotFound,
 		},
 		{
 			name: "Database error",
@@ -157,8 +485,7 @@ func TestUseCase_CheckCredentials(t *testing.T) {
 			},
 			mockReturn: domain.User{},
 			mockError:  errors.New("DB error"),
-			expectedID: 0,
-			expectErr:  true,
+			expectErr:  errors.New("DB error"),
 		},
 	} {
 		tt := tt
@@ -166,26 +493,158 @@ func TestUseCase_CheckCredentials(t *testing.T) {
 			t.Parallel()
 
 			mockRepo := new(mocks.Repository)
-			useCase := &UseCase{repo: mockRepo}
+			mockHasher := new(mocks.PasswordHasher)
+			useCase := &UseCase{repo: mockRepo, hasher: mockHasher}
 
 			ctx := context.Background()
 
-			mockRepo.ExpectedCalls = nil
-
 			mockRepo.On("GetUserByUsername", ctx, tt.creds.Username).
 				Return(tt.mockReturn, tt.mockError).Once()
 
+			if tt.mockError == nil {
+				mockHasher.On("Verify", tt.creds.Password.String(), tt.mockReturn.Password.String()).
+					Return(tt.verifyOK, tt.needsRehash, nil).Once()
+			}
+
+			if tt.expectUpdate {
+				mockHasher.On("Hash", tt.creds.Password.String()).Return("new_hash", nil).Once()
+				mockRepo.On("UpdatePassword", ctx, tt.mockReturn.ID, "new_hash").Return(nil).Once()
+			}
+
 			userID, err := useCase.CheckCredentials(ctx, tt.creds)
 
-			if tt.expectErr {
-				assert.Error(t, err)
+			mockRepo.AssertExpectations(t)
+			mockHasher.AssertExpectations(t)
+
+			if tt.expectErr != nil {
+				assert.ErrorContains(t, err, tt.expectErr.Error())
 				assert.Equal(t, uint64(0), userID)
 				return
 			}
 			assert.NoError(t, err)
 			assert.Equal(t, tt.expectedID, userID)
+		})
+	}
+}
+
+func TestUseCase_Refresh(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	revokedAt := time.Now().Add(-time.Minute)
+
+	for _, tt := range []struct {
+		name         string
+		stored       domain.RefreshToken
+		storedErr    error
+		rotateErr    error
+		expectRotate bool
+		expectRevoke bool
+		expectErr    error
+	}{
+		{
+			name: "Successful rotation",
+			stored: domain.RefreshToken{
+				ID:        1,
+				UserID:    7,
+				FamilyID:  "family",
+				ExpiresAt: time.Now().Add(time.Hour),
+			},
+			expectRotate: true,
+		},
+		{
+			name:      "Unknown token",
+			storedErr: ErrNotFound,
+			expectErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "Expired token",
+			stored: domain.RefreshToken{
+				ID:        1,
+				UserID:    7,
+				FamilyID:  "family",
+				ExpiresAt: time.Now().Add(-time.Hour),
+			},
+			expectErr: ErrInvalidRefreshToken,
+		},
+		{
+			name: "Reuse of rotated token revokes family",
+			stored: domain.RefreshToken{
+				ID:        1,
+				UserID:    7,
+				FamilyID:  "family",
+				ExpiresAt: time.Now().Add(time.Hour),
+				RevokedAt: &revokedAt,
+			},
+			expectRevoke: true,
+			expectErr:    ErrRefreshTokenReused,
+		},
+		{
+			name: "Concurrent rotation revokes family",
+			stored: domain.RefreshToken{
+				ID:        1,
+				UserID:    7,
+				FamilyID:  "family",
+				ExpiresAt: time.Now().Add(time.Hour),
+			},
+			rotateErr:    ErrRefreshTokenReused,
+			expectRotate: true,
+			expectRevoke: true,
+			expectErr:    ErrRefreshTokenReused,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			mockAuth := new(mocks.Auth)
+			useCase := &UseCase{repo: mockRepo, auth: mockAuth, cfg: Config{RefreshTokenTTL: time.Hour}}
+
+			mockRepo.On("GetRefreshToken", ctx, hashToken("old_token")).
+				Return(tt.stored, tt.storedErr).Once()
+
+			if tt.expectRotate {
+				user := domain.User{ID: tt.stored.UserID, TokenGeneration: 3}
+				mockRepo.On("GetUserByID", ctx, tt.stored.UserID).Return(user, nil).Once()
+				mockAuth.On("NewAccessToken", user).Return("access_token", nil).Once()
+				mockAuth.On("NewRefreshToken").Return("new_token", nil).Once()
+				mockRepo.On("RotateRefreshToken", ctx, tt.stored.ID, mock.MatchedBy(func(next domain.RefreshToken) bool {
+					return next.FamilyID == tt.stored.FamilyID && next.TokenHash == hashToken("new_token")
+				})).Return(tt.rotateErr).Once()
+			}
+
+			if tt.expectRevoke {
+				mockRepo.On("RevokeRefreshTokenFamily", ctx, tt.stored.FamilyID).Return(nil).Once()
+			}
+
+			tokens, err := useCase.Refresh(ctx, "old_token")
+
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+				assert.Empty(t, tokens)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, domain.Tokens{AccessToken: "access_token", RefreshToken: "new_token"}, tokens)
+			}
 
 			mockRepo.AssertExpectations(t)
+			mockAuth.AssertExpectations(t)
 		})
 	}
 }
+
+func TestUseCase_SystemUserCannotAuthenticate(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(mocks.Repository)
+	useCase := &UseCase{repo: mockRepo}
+
+	_, err := useCase.CheckCredentials(context.Background(), domain.Credentials{
+		Username: domain.SystemUsername,
+		Password: "TestPassword1",
+	})
+	assert.ErrorIs(t, err, ErrUnauthorized)
+
+	mockRepo.AssertExpectations(t)
+}
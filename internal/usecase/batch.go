@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"merch-shop/internal/domain"
+)
+
+// BatchSendCoin переводит монеты нескольким получателям в одной транзакции: пакет
+// выполняется целиком или отклоняется целиком с причинами по каждой строке
+func (u *UseCase) BatchSendCoin(ctx context.Context, fromUserID uint64, req domain.BatchSendCoinRequest) (domain.BatchResult, error) {
+	var (
+		result   domain.BatchResult
+		seen     = make(map[string]struct{}, len(req.Transfers))
+		failures []domain.BatchLineError
+		amounts  = make([]uint64, 0, len(req.Transfers))
+	)
+
+	for _, line := range req.Transfers {
+		_, duplicate := seen[line.ToUser]
+		seen[line.ToUser] = struct{}{}
+
+		reason := ""
+		switch {
+		case duplicate:
+			reason = domain.LineDuplicate
+		case line.ToUser == domain.SystemUsername:
+			reason = domain.LineSystemAccount
+		// Пакет выполняется сразу, поэтому переводы, которым нужно одобрение, в него не входят
+		case u.cfg.ApprovalThreshold > 0 && line.Amount > u.cfg.ApprovalThreshold:
+			reason = domain.LineApprovalRequired
+		}
+
+		if reason != "" {
+			failures = append(failures, domain.BatchLineError{ToUser: line.ToUser, Amount: line.Amount, Reason: reason})
+			continue
+		}
+
+		result.Recipients++
+		result.Total += line.Amount
+		amounts = append(amounts, line.Amount)
+	}
+
+	if len(failures) > 0 {
+		return domain.BatchResult{}, &BatchError{Lines: failures}
+	}
+
+	fromUser, err := u.repo.GetUserByID(ctx, fromUserID)
+	if err != nil {
+		return domain.BatchResult{}, fmt.Errorf("repo.GetUserByID: %w", err)
+	}
+
+	if err = u.repo.BatchTransferCoins(ctx, fromUserID, req.Transfers, u.transferGuard(fromUser, amounts...)); err != nil {
+		return domain.BatchResult{}, fmt.Errorf("repo.BatchTransferCoins: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,118 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUseCase_BatchSendCoin(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	sender := domain.User{ID: 1, Coins: 1000, Credentials: domain.Credentials{Username: "lead"}}
+
+	for _, tt := range []struct {
+		name           string
+		cfg            Config
+		transfers      []domain.SendCoinRequest
+		mockSetup      func(mockRepo *mocks.Repository)
+		expectedResult domain.BatchResult
+		expectedLines  []domain.BatchLineError
+		expectedErr    error
+	}{
+		{
+			name: "Success",
+			transfers: []domain.SendCoinRequest{
+				{ToUser: "ivanov", Amount: 30, Memo: "спасибо"},
+				{ToUser: "petrova", Amount: 20},
+			},
+			mockSetup: func(mockRepo *mocks.Repository) {
+				mockRepo.On("GetUserByID", ctx, sender.ID).Return(sender, nil).Once()
+				mockRepo.On("BatchTransferCoins", ctx, sender.ID, []domain.SendCoinRequest{
+					{ToUser: "ivanov", Amount: 30, Memo: "спасибо"},
+					{ToUser: "petrova", Amount: 20},
+				}, domain.TransferGuard{}).Return(nil).Once()
+			},
+			expectedResult: domain.BatchResult{Recipients: 2, Total: 50},
+		},
+		{
+			name: "Duplicates, system account and approval are reported per line",
+			cfg:  Config{ApprovalThreshold: 500},
+			transfers: []domain.SendCoinRequest{
+				{ToUser: "ivanov", Amount: 30},
+				{ToUser: "ivanov", Amount: 20},
+				{ToUser: domain.SystemUsername, Amount: 10},
+				{ToUser: "petrova", Amount: 600},
+			},
+			mockSetup: func(mockRepo *mocks.Repository) {},
+			expectedLines: []domain.BatchLineError{
+				{ToUser: "ivanov", Amount: 20, Reason: domain.LineDuplicate},
+				{ToUser: domain.SystemUsername, Amount: 10, Reason: domain.LineSystemAccount},
+				{ToUser: "petrova", Amount: 600, Reason: domain.LineApprovalRequired},
+			},
+			expectedErr: ErrBatchRejected,
+		},
+		{
+			name: "Rejected by repository",
+			transfers: []domain.SendCoinRequest{
+				{ToUser: "nobody", Amount: 30},
+			},
+			mockSetup: func(mockRepo *mocks.Repository) {
+				mockRepo.On("GetUserByID", ctx, sender.ID).Return(sender, nil).Once()
+				mockRepo.On("BatchTransferCoins", ctx, sender.ID, mock.Anything, domain.TransferGuard{}).Return(&BatchError{
+					Lines: []domain.BatchLineError{{ToUser: "nobody", Amount: 30, Reason: domain.LineNotFound}},
+				}).Once()
+			},
+			expectedLines: []domain.BatchLineError{{ToUser: "nobody", Amount: 30, Reason: domain.LineNotFound}},
+			expectedErr:   ErrBatchRejected,
+		},
+		{
+			name: "Hourly limit counts every line",
+			cfg:  Config{TransferLimits: TransferLimits{MaxPerHour: 3}},
+			transfers: []domain.SendCoinRequest{
+				{ToUser: "ivanov", Amount: 1},
+				{ToUser: "petrova", Amount: 1},
+			},
+			mockSetup: func(mockRepo *mocks.Repository) {
+				mockRepo.On("GetUserByID", ctx, sender.ID).Return(sender, nil).Once()
+				// Репозиторий передаёт в guard статистику, прочитанную в транзакции пакета
+				mockRepo.On("BatchTransferCoins", ctx, sender.ID, mock.Anything, mock.Anything).Return(
+					func(_ context.Context, _ uint64, _ []domain.SendCoinRequest, guard domain.TransferGuard) error {
+						return guard.Check(domain.TransferStats{TransfersLastHour: 2})
+					}).Once()
+			},
+			expectedErr: ErrTransferLimit,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, tt.cfg)
+
+			tt.mockSetup(mockRepo)
+
+			result, err := useCase.BatchSendCoin(ctx, sender.ID, domain.BatchSendCoinRequest{Transfers: tt.transfers})
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+
+				var batchErr *BatchError
+				if tt.expectedLines != nil && assert.True(t, errors.As(err, &batchErr)) {
+					assert.Equal(t, tt.expectedLines, batchErr.Lines)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectedResult, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"merch-shop/internal/domain"
+	"time"
+)
+
+const defaultCoinRequestsLimit = 20
+
+// CreateCoinRequest просит плательщика перевести монеты автору запроса
+func (u *UseCase) CreateCoinRequest(ctx context.Context, requesterID uint64, req domain.CreateCoinRequest) (domain.CoinRequest, error) {
+	if req.Payer == domain.SystemUsername {
+		return domain.CoinRequest{}, ErrSystemAccount
+	}
+
+	// Принятый запрос переводит монеты сразу, поэтому суммы, которым нужно одобрение, запрашивать нельзя
+	if u.cfg.ApprovalThreshold > 0 && req.Amount > u.cfg.ApprovalThreshold {
+		return domain.CoinRequest{}, &LimitError{
+			Limit:     LimitApproval,
+			Max:       u.cfg.ApprovalThreshold,
+			Remaining: u.cfg.ApprovalThreshold,
+		}
+	}
+
+	requester, err := u.repo.GetUserByID(ctx, requesterID)
+	if err != nil {
+		return domain.CoinRequest{}, fmt.Errorf("repo.GetUserByID: %w", err)
+	}
+
+	payer, err := u.userByUsername(ctx, req.Payer)
+	if err != nil {
+		return domain.CoinRequest{}, err
+	}
+
+	if payer.ID == requester.ID {
+		return domain.CoinRequest{}, ErrCoinRequestSelf
+	}
+
+	request, err := u.repo.CreateCoinRequest(ctx, domain.CoinRequest{
+		RequesterID: requester.ID,
+		Requester:   requester.Username,
+		PayerID:     payer.ID,
+		Payer:       payer.Username,
+		Amount:      req.Amount,
+		Memo:        req.Memo,
+		ExpiresAt:   time.Now().UTC().Add(u.cfg.CoinRequestTTL),
+	})
+	if err != nil {
+		return domain.CoinRequest{}, fmt.Errorf("repo.CreateCoinRequest: %w", err)
+	}
+
+	return request, nil
+}
+
+func (u *UseCase) ListCoinRequests(ctx context.Context, userID uint64, query domain.CoinRequestQuery) (domain.CoinRequestPage, error) {
+	filter := domain.CoinRequestFilter{Box: query.Box, Status: query.Status, Limit: query.Limit}
+	if filter.Limit == 0 {
+		filter.Limit = defaultCoinRequestsLimit
+	}
+
+	if query.Cursor != "" {
+		var cursor idCursor
+		if err := decodeCursor(query.Cursor, &cursor); err != nil || cursor.BeforeID == 0 {
+			return domain.CoinRequestPage{}, ErrInvalidCursor
+		}
+		filter.BeforeID = cursor.BeforeID
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+
+	requests, err := u.repo.ListCoinRequests(ctx, userID, filter)
+	if err != nil {
+		return domain.CoinRequestPage{}, fmt.Errorf("repo.ListCoinRequests: %w", err)
+	}
+
+	page := domain.CoinRequestPage{Requests: requests}
+	if len(requests) > limit {
+		page.Requests = requests[:limit]
+
+		page.NextCursor, err = encodeCursor(idCursor{BeforeID: page.Requests[limit-1].ID})
+		if err != nil {
+			return domain.CoinRequestPage{}, err
+		}
+	}
+
+	return page, nil
+}
+
+// AcceptCoinRequest оплачивает запрос. Перевод проходит те же проверки, что и sendCoin.
+func (u *UseCase) AcceptCoinRequest(ctx context.Context, payerID, requestID uint64) (domain.CoinRequest, error) {
+	request, err := u.repo.GetCoinRequest(ctx, requestID)
+	if err != nil {
+		return domain.CoinRequest{}, fmt.Errorf("repo.GetCoinRequest: %w", err)
+	}
+
+	if request.PayerID != payerID {
+		return domain.CoinRequest{}, ErrCoinRequestNotFound
+	}
+
+	switch request.Status {
+	case domain.CoinRequestPending:
+	case domain.CoinRequestExpired:
+		return domain.CoinRequest{}, ErrCoinRequestExpired
+	default:
+		return domain.CoinRequest{}, ErrCoinRequestDecided
+	}
+
+	payer, err := u.repo.GetUserByID(ctx, payerID)
+	if err != nil {
+		return domain.CoinRequest{}, fmt.Errorf("repo.GetUserByID: %w", err)
+	}
+
+	if payer.Coins < request.Amount {
+		return domain.CoinRequest{}, ErrNoCoins
+	}
+
+	request, err = u.repo.AcceptCoinRequest(ctx, requestID, payerID, u.transferGuard(payer, request.Amount))
+	if err != nil {
+		return domain.CoinRequest{}, fmt.Errorf("repo.AcceptCoinRequest: %w", err)
+	}
+
+	return request, nil
+}
+
+func (u *UseCase) DeclineCoinRequest(ctx context.Context, payerID, requestID uint64) (domain.CoinRequest, error) {
+	request, err := u.repo.DeclineCoinRequest(ctx, requestID, payerID)
+	if err != nil {
+		return domain.CoinRequest{}, fmt.Errorf("repo.DeclineCoinRequest: %w", err)
+	}
+
+	return request, nil
+}
@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUseCase_CreateCoinRequest(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	requester := domain.User{ID: 1, Credentials: domain.Credentials{Username: "petrov"}}
+	payer := domain.User{ID: 2, Credentials: domain.Credentials{Username: "ivanov"}}
+
+	for _, tt := range []struct {
+		name        string
+		req         domain.CreateCoinRequest
+		mockSetup   func(mockRepo *mocks.Repository)
+		expectedErr error
+	}{
+		{
+			name: "Success",
+			req:  domain.CreateCoinRequest{Payer: "ivanov", Amount: 50, Memo: "за пиццу"},
+			mockSetup: func(mockRepo *mocks.Repository) {
+				mockRepo.On("GetUserByID", ctx, requester.ID).Return(requester, nil).Once()
+				mockRepo.On("GetUserByUsername", ctx, "ivanov").Return(payer, nil).Once()
+				mockRepo.On("CreateCoinRequest", ctx, mock.MatchedBy(func(c domain.CoinRequest) bool {
+					ttl := time.Until(c.ExpiresAt)
+					return c.RequesterID == 1 && c.PayerID == 2 && c.Amount == 50 && c.Memo == "за пиццу" &&
+						ttl > 23*time.Hour && ttl <= 24*time.Hour
+				})).Return(domain.CoinRequest{ID: 3, Status: domain.CoinRequestPending}, nil).Once()
+			},
+		},
+		{
+			name: "Request from yourself",
+			req:  domain.CreateCoinRequest{Payer: "petrov", Amount: 50},
+			mockSetup: func(mockRepo *mocks.Repository) {
+				mockRepo.On("GetUserByID", ctx, requester.ID).Return(requester, nil).Once()
+				mockRepo.On("GetUserByUsername", ctx, "petrov").Return(requester, nil).Once()
+			},
+			expectedErr: ErrCoinRequestSelf,
+		},
+		{
+			name: "Unknown payer",
+			req:  domain.CreateCoinRequest{Payer: "nobody", Amount: 50},
+			mockSetup: func(mockRepo *mocks.Repository) {
+				mockRepo.On("GetUserByID", ctx, requester.ID).Return(requester, nil).Once()
+				mockRepo.On("GetUserByUsername", ctx, "nobody").Return(domain.User{}, ErrNotFound).Once()
+			},
+			expectedErr: ErrUserNotFound,
+		},
+		{
+			name:        "System account",
+			req:         domain.CreateCoinRequest{Payer: domain.SystemUsername, Amount: 50},
+			mockSetup:   func(mockRepo *mocks.Repository) {},
+			expectedErr: ErrSystemAccount,
+		},
+		{
+			name:        "Above approval threshold",
+			req:         domain.CreateCoinRequest{Payer: "ivanov", Amount: 501},
+			mockSetup:   func(mockRepo *mocks.Repository) {},
+			expectedErr: ErrTransferLimit,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, Config{ApprovalThreshold: 500, CoinRequestTTL: 24 * time.Hour})
+
+			tt.mockSetup(mockRepo)
+
+			request, err := useCase.CreateCoinRequest(ctx, requester.ID, tt.req)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, uint64(3), request.ID)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUseCase_AcceptCoinRequest(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	payer := domain.User{ID: 2, Coins: 100, Credentials: domain.Credentials{Username: "ivanov"}}
+	pending := domain.CoinRequest{ID: 3, RequesterID: 1, PayerID: 2, Amount: 50, Status: domain.CoinRequestPending}
+
+	withStatus := func(status string) domain.CoinRequest {
+		c := pending
+		c.Status = status
+		return c
+	}
+
+	for _, tt := range []struct {
+		name        string
+		payerID     uint64
+		mockSetup   func(mockRepo *mocks.Repository)
+		expectedErr error
+	}{
+		{
+			name:    "Success",
+			payerID: 2,
+			mockSetup: func(mockRepo *mocks.Repository) {
+				mockRepo.On("GetCoinRequest", ctx, uint64(3)).Return(pending, nil).Once()
+				mockRepo.On("GetUserByID", ctx, payer.ID).Return(payer, nil).Once()
+				mockRepo.On("AcceptCoinRequest", ctx, uint64(3), payer.ID, domain.TransferGuard{}).
+					Return(withStatus(domain.CoinRequestAccepted), nil).Once()
+			},
+		},
+		{
+			name:    "Not addressed to user",
+			payerID: 5,
+			mockSetup: func(mockRepo *mocks.Repository) {
+				mockRepo.On("GetCoinRequest", ctx, uint64(3)).Return(pending, nil).Once()
+			},
+			expectedErr: ErrCoinRequestNotFound,
+		},
+		{
+			name:    "Expired",
+			payerID: 2,
+			mockSetup: func(mockRepo *mocks.Repository) {
+				mockRepo.On("GetCoinRequest", ctx, uint64(3)).Return(withStatus(domain.CoinRequestExpired), nil).Once()
+			},
+			expectedErr: ErrCoinRequestExpired,
+		},
+		{
+			name:    "Already declined",
+			payerID: 2,
+			mockSetup: func(mockRepo *mocks.Repository) {
+				mockRepo.On("GetCoinRequest", ctx, uint64(3)).Return(withStatus(domain.CoinRequestDeclined), nil).Once()
+			},
+			expectedErr: ErrCoinRequestDecided,
+		},
+		{
+			name:    "Not enough coins",
+			payerID: 2,
+			mockSetup: func(mockRepo *mocks.Repository) {
+				mockRepo.On("GetCoinRequest", ctx, uint64(3)).Return(pending, nil).Once()
+				mockRepo.On("GetUserByID", ctx, payer.ID).Return(domain.User{ID: 2, Coins: 10}, nil).Once()
+			},
+			expectedErr: ErrNoCoins,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, Config{})
+
+			tt.mockSetup(mockRepo)
+
+			request, err := useCase.AcceptCoinRequest(ctx, tt.payerID, 3)
+			if tt.expectedErr != nil {
+				assert.ErrorIs(t, err, tt.expectedErr)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, domain.CoinRequestAccepted, request.Status)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUseCase_AcceptCoinRequestLimits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{TransferLimits: TransferLimits{MaxAmount: 30}})
+
+	mockRepo.On("GetCoinRequest", ctx, uint64(3)).Return(domain.CoinRequest{
+		ID: 3, RequesterID: 1, PayerID: 2, Amount: 50, Status: domain.CoinRequestPending,
+	}, nil).Once()
+	mockRepo.On("GetUserByID", ctx, uint64(2)).Return(domain.User{ID: 2, Coins: 100}, nil).Once()
+	mockRepo.On("AcceptCoinRequest", ctx, uint64(3), uint64(2), mock.Anything).Return(domain.CoinRequest{},
+		func(_ context.Context, _, _ uint64, guard domain.TransferGuard) error {
+			return guard.Check(domain.TransferStats{})
+		}).Once()
+
+	_, err := useCase.AcceptCoinRequest(ctx, 2, 3)
+	assert.ErrorIs(t, err, ErrTransferLimit)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -2,32 +2,86 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"merch-shop/internal/domain"
+	"time"
 )
 
-func (u *UseCase) SendCoin(ctx context.Context, fromUserID uint64, req domain.SendCoinRequest) error {
+// SendCoin переводит монеты сразу или, если сумма больше порога одобрения,
+// заводит ожидающий перевод и возвращает его
+func (u *UseCase) SendCoin(ctx context.Context, fromUserID uint64, req domain.SendCoinRequest) (*domain.PendingTransfer, error) {
+	if req.ToUser == domain.SystemUsername {
+		return nil, ErrSystemAccount
+	}
+
 	fromUser, err := u.repo.GetUserByID(ctx, fromUserID)
 	if err != nil {
-		return fmt.Errorf("repo.GetUserByID %s: %w", req.ToUser, err)
+		return nil, fmt.Errorf("repo.GetUserByID %s: %w", req.ToUser, err)
 	}
 
 	if fromUser.Coins < req.Amount {
-		return ErrNoCoins
+		return nil, ErrNoCoins
 	}
 
 	toUser, err := u.repo.GetUserByUsername(ctx, req.ToUser)
 	if err != nil {
-		return fmt.Errorf("repo.GetUserByUsername %s: %w", req.ToUser, err)
+		return nil, fmt.Errorf("repo.GetUserByUsername %s: %w", req.ToUser, err)
 	}
 
 	if fromUserID == toUser.ID {
-		return ErrSendCoin
+		return nil, ErrSendCoin
 	}
 
-	err = u.repo.TransferCoins(ctx, fromUserID, toUser.ID, req.Amount)
+	guard := u.transferGuard(fromUser, req.Amount)
+
+	if u.cfg.ApprovalThreshold > 0 && req.Amount > u.cfg.ApprovalThreshold {
+		return u.holdTransfer(ctx, fromUser, toUser, req, guard)
+	}
+
+	err = u.repo.TransferCoins(ctx, fromUserID, toUser.ID, req.Amount, req.Memo, guard)
 	if err != nil {
-		return fmt.Errorf("repo.TransferCoins: %w", err)
+		return nil, fmt.Errorf("repo.TransferCoins: %w", err)
+	}
+
+	return nil, nil
+}
+
+// transferGuard проверяет переводы на суммы amounts, выполняемые подряд:
+// каждый учитывается в лимитах следующих
+func (u *UseCase) transferGuard(fromUser domain.User, amounts ...uint64) domain.TransferGuard {
+	if len(u.transferPolicies) == 0 {
+		return domain.TransferGuard{}
+	}
+
+	now := time.Now().UTC()
+
+	return domain.TransferGuard{
+		DaySince:  now.Add(-24 * time.Hour),
+		HourSince: now.Add(-time.Hour),
+		Check: func(stats domain.TransferStats) error {
+			return u.checkTransferPolicies(fromUser, now, stats, amounts...)
+		},
+	}
+}
+
+func (u *UseCase) checkTransferPolicies(fromUser domain.User, now time.Time, stats domain.TransferStats, amounts ...uint64) error {
+	for _, amount := range amounts {
+		err := u.transferPolicies.Check(TransferAttempt{
+			From:   fromUser,
+			Amount: amount,
+			Stats:  stats,
+			Now:    now,
+		})
+		if err != nil {
+			return err
+		}
+
+		stats.SentLastDay += amount
+		stats.TransfersLastHour++
+		if stats.FirstInHour.IsZero() {
+			stats.FirstInHour = now
+		}
 	}
 
 	return nil
@@ -36,6 +90,9 @@ func (u *UseCase) SendCoin(ctx context.Context, fromUserID uint64, req domain.Se
 func (u *UseCase) BuyMerch(ctx context.Context, userID uint64, itemName string) error {
 	itemPrice, err := u.repo.GetMerchPrice(ctx, itemName)
 	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrMerchNotFound
+		}
 		return fmt.Errorf("repo.GetMerchPrice: %w", err)
 	}
 
@@ -49,6 +106,9 @@ func (u *UseCase) BuyMerch(ctx context.Context, userID uint64, itemName string)
 	}
 
 	if err = u.repo.BuyMerch(ctx, userID, itemName, itemPrice); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrMerchNotFound
+		}
 		return fmt.Errorf("repo.BuyMerch: %w", err)
 	}
 
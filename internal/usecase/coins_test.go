@@ -90,7 +90,7 @@ func TestUseCase_SendCoin(t *testing.T) {
 				Return(tt.fromUser, tt.mockFromErr).Once()
 
 			if tt.mockFromErr != nil {
-				err := useCase.SendCoin(ctx, tt.fromUser.ID, tt.req)
+				_, err := useCase.SendCoin(ctx, tt.fromUser.ID, tt.req)
 				assert.ErrorContains(t, err, tt.expectErr.Error())
 				mockRepo.AssertExpectations(t)
 				return
@@ -100,18 +100,18 @@ func TestUseCase_SendCoin(t *testing.T) {
 				Return(tt.toUser, tt.mockToErr).Once()
 
 			if tt.mockToErr != nil {
-				err := useCase.SendCoin(ctx, tt.fromUser.ID, tt.req)
+				_, err := useCase.SendCoin(ctx, tt.fromUser.ID, tt.req)
 				assert.ErrorContains(t, err, tt.expectErr.Error())
 				mockRepo.AssertExpectations(t)
 				return
 			}
 
 			if tt.expectErr == nil || tt.expectErr == ErrSendCoin {
-				mockRepo.On("TransferCoins", ctx, tt.fromUser.ID, tt.toUser.ID, tt.req.Amount).
+				mockRepo.On("TransferCoins", ctx, tt.fromUser.ID, tt.toUser.ID, tt.req.Amount, tt.req.Memo, domain.TransferGuard{}).
 					Return(tt.mockTransErr).Once()
 			}
 
-			err := useCase.SendCoin(ctx, tt.fromUser.ID, tt.req)
+			_, err := useCase.SendCoin(ctx, tt.fromUser.ID, tt.req)
 
 			if tt.expectErr != nil {
 				assert.ErrorContains(t, err, tt.expectErr.Error())
@@ -123,3 +123,82 @@ func TestUseCase_SendCoin(t *testing.T) {
 		})
 	}
 }
+
+func TestUseCase_SendCoinToSystem(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(mocks.Repository)
+	useCase := &UseCase{repo: mockRepo}
+
+	_, err := useCase.SendCoin(context.Background(), 1, domain.SendCoinRequest{
+		ToUser: domain.SystemUsername,
+		Amount: 10,
+	})
+	assert.ErrorIs(t, err, ErrSystemAccount)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUseCase_BuyMerch(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name         string
+		mockPrice    uint64
+		mockPriceErr error
+		mockBuyErr   error
+		expectBuy    bool
+		expectErr    error
+	}{
+		{
+			name:      "Successful purchase",
+			mockPrice: 10,
+			expectBuy: true,
+		},
+		{
+			name:         "Unknown item",
+			mockPriceErr: ErrNotFound,
+			expectErr:    ErrMerchNotFound,
+		},
+		{
+			// Товар сняли с продажи между получением цены и покупкой
+			name:       "Item retired during purchase",
+			mockPrice:  10,
+			mockBuyErr: ErrNotFound,
+			expectBuy:  true,
+			expectErr:  ErrMerchNotFound,
+		},
+		{
+			name:       "Out of stock",
+			mockPrice:  10,
+			mockBuyErr: ErrOutOfStock,
+			expectBuy:  true,
+			expectErr:  ErrOutOfStock,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, Config{})
+
+			mockRepo.On("GetMerchPrice", ctx, "pen").Return(tt.mockPrice, tt.mockPriceErr).Once()
+			if tt.expectBuy {
+				mockRepo.On("GetUserByID", ctx, uint64(1)).Return(domain.User{ID: 1, Coins: 100}, nil).Once()
+				mockRepo.On("BuyMerch", ctx, uint64(1), "pen", tt.mockPrice).Return(tt.mockBuyErr).Once()
+			}
+
+			err := useCase.BuyMerch(ctx, 1, "pen")
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
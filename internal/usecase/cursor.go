@@ -0,0 +1,36 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+)
+
+// Курсоры пагинации непрозрачны для клиента: это base64 от JSON с позицией в выдаче
+
+// idCursor продолжает выдачу, упорядоченную по убыванию id
+type idCursor struct {
+	BeforeID uint64 `json:"b"`
+}
+
+func encodeCursor(v any) (string, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cursor: %w", err)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+func decodeCursor(s string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return ErrInvalidCursor
+	}
+
+	if err = json.Unmarshal(data, v); err != nil {
+		return ErrInvalidCursor
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"merch-shop/internal/domain"
+	"time"
+)
+
+// economyBatchSize ограничивает число пользователей, обрабатываемых за одну транзакцию
+const economyBatchSize = 100
+
+// PreviewExpiry показывает пользователю, сколько начисленных монет сгорит и когда
+func (u *UseCase) PreviewExpiry(ctx context.Context, userID uint64) (domain.ExpiryPreview, error) {
+	if u.cfg.Economy.ExpiryMonths == 0 {
+		return domain.PreviewExpiry(nil, 0), nil
+	}
+
+	lots, err := u.repo.ListCoinLots(ctx, userID)
+	if err != nil {
+		return domain.ExpiryPreview{}, fmt.Errorf("repo.ListCoinLots: %w", err)
+	}
+
+	return domain.PreviewExpiry(lots, u.cfg.Economy.ExpiryMonths), nil
+}
+
+// RunEconomyPolicy начисляет пособие за текущий месяц тем, кто его ещё не получил,
+// и сжигает остатки партий старше ExpiryMonths
+func (u *UseCase) RunEconomyPolicy(ctx context.Context) (domain.EconomyReport, error) {
+	var (
+		report domain.EconomyReport
+		policy = u.cfg.Economy
+		now    = time.Now().UTC()
+	)
+
+	if policy.AllowanceAmount > 0 {
+		period := domain.AllowancePeriod(now)
+		activeSince := now.Add(-policy.AllowanceActiveWithin)
+
+		for {
+			credited, err := u.repo.CreditAllowances(ctx, period, activeSince, policy.AllowanceAmount, economyBatchSize)
+			if err != nil {
+				return report, fmt.Errorf("repo.CreditAllowances: %w", err)
+			}
+
+			report.AllowancesCredited += credited
+			report.AllowanceTotal += uint64(credited) * policy.AllowanceAmount
+			if credited < economyBatchSize {
+				break
+			}
+		}
+	}
+
+	if policy.ExpiryMonths > 0 {
+		cutoff := now.AddDate(0, -policy.ExpiryMonths, 0)
+
+		for {
+			users, coins, err := u.repo.ExpireCoinLots(ctx, cutoff, economyBatchSize)
+			if err != nil {
+				return report, fmt.Errorf("repo.ExpireCoinLots: %w", err)
+			}
+
+			report.UsersExpired += users
+			report.CoinsExpired += coins
+			if users < economyBatchSize {
+				break
+			}
+		}
+	}
+
+	return report, nil
+}
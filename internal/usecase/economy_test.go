@@ -0,0 +1,95 @@
+package usecase
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUseCase_RunEconomyPolicy(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{Economy: EconomyPolicy{
+		AllowanceAmount:       50,
+		AllowanceActiveWithin: 30 * 24 * time.Hour,
+		ExpiryMonths:          6,
+	}})
+
+	period := domain.AllowancePeriod(time.Now())
+	activeSince := mock.MatchedBy(func(since time.Time) bool {
+		age := time.Since(since)
+		return age >= 30*24*time.Hour && age < 30*24*time.Hour+time.Minute
+	})
+	cutoff := mock.MatchedBy(func(cutoff time.Time) bool {
+		expected := time.Now().UTC().AddDate(0, -6, 0)
+		return expected.Sub(cutoff) >= 0 && expected.Sub(cutoff) < time.Minute
+	})
+
+	// Полная пачка — задача берёт следующую, неполная — последняя
+	mockRepo.On("CreditAllowances", ctx, period, activeSince, uint64(50), economyBatchSize).Return(economyBatchSize, nil).Once()
+	mockRepo.On("CreditAllowances", ctx, period, activeSince, uint64(50), economyBatchSize).Return(3, nil).Once()
+	mockRepo.On("ExpireCoinLots", ctx, cutoff, economyBatchSize).Return(2, uint64(730), nil).Once()
+
+	report, err := useCase.RunEconomyPolicy(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.EconomyReport{
+		AllowancesCredited: economyBatchSize + 3,
+		AllowanceTotal:     uint64(economyBatchSize+3) * 50,
+		UsersExpired:       2,
+		CoinsExpired:       730,
+	}, report)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUseCase_RunEconomyPolicyDisabled(t *testing.T) {
+	t.Parallel()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{})
+
+	report, err := useCase.RunEconomyPolicy(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, domain.EconomyReport{}, report)
+
+	preview, err := useCase.PreviewExpiry(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.False(t, preview.Enabled)
+	assert.Empty(t, preview.Expiring)
+
+	mockRepo.AssertNotCalled(t, "CreditAllowances", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "ExpireCoinLots", mock.Anything, mock.Anything, mock.Anything)
+	mockRepo.AssertNotCalled(t, "ListCoinLots", mock.Anything, mock.Anything)
+}
+
+func TestUseCase_PreviewExpiry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{Economy: EconomyPolicy{ExpiryMonths: 3}})
+
+	createdAt := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	mockRepo.On("ListCoinLots", ctx, uint64(1)).Return([]domain.CoinLot{
+		{ID: 1, Source: domain.LotOpening, Amount: 1000, Remaining: 250, CreatedAt: createdAt},
+	}, nil).Once()
+
+	preview, err := useCase.PreviewExpiry(ctx, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, domain.ExpiryPreview{
+		Enabled:  true,
+		Total:    250,
+		Expiring: []domain.ExpiringCoins{{Amount: 250, ExpiresAt: time.Date(2025, 4, 10, 12, 0, 0, 0, time.UTC)}},
+	}, preview)
+
+	mockRepo.AssertExpectations(t)
+}
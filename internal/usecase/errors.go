@@ -1,12 +1,129 @@
 package usecase
 
-import "errors"
+import (
+	"errors"
+	"merch-shop/internal/domain"
+	"time"
+)
 
 var (
-	ErrUnauthorized  = errors.New("invalid username or password")
-	ErrNotFound      = errors.New("item not found")
-	ErrNoCoins       = errors.New("have not coins")
-	ErrSendCoin      = errors.New("can't send coins to yourself")
-	PasswordNotValid = errors.New("password not valid")
-	UsernameNotValid = errors.New("username not valid")
+	ErrUnauthorized        = errors.New("invalid username or password")
+	ErrNotFound            = errors.New("item not found")
+	ErrNoCoins             = errors.New("have not coins")
+	ErrSendCoin            = errors.New("can't send coins to yourself")
+	PasswordNotValid       = errors.New("password not valid")
+	UsernameNotValid       = errors.New("username not valid")
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token has already been used")
+	ErrSessionRevoked      = errors.New("session has been revoked")
+	ErrTooManyAttempts     = errors.New("too many failed login attempts")
+	ErrUserExists          = errors.New("user already exists")
+	ErrInviteRequired      = errors.New("invite code required")
+	ErrInvalidInvite       = errors.New("invite code is invalid, expired or already used")
+	ErrInvalidRole         = errors.New("unknown role")
+	ErrUserNotFound        = errors.New("user not found")
+	ErrMerchNotFound       = errors.New("merch not found")
+	ErrMerchExists         = errors.New("merch with this name already exists")
+	ErrInvalidCursor       = errors.New("invalid pagination cursor")
+	ErrOutOfStock          = errors.New("merch is out of stock")
+	ErrOrderRejected       = errors.New("order cannot be fulfilled")
+	ErrOrderNotFound       = errors.New("order not found")
+	ErrNothingToReturn     = errors.New("order has no items left to return")
+	ErrReturnWindowExpired = errors.New("return window has expired")
+	ErrItemNotOwned        = errors.New("item is no longer in inventory")
+	ErrIdempotencyConflict = errors.New("request with this idempotency key is already in progress")
+	ErrIdempotencyMismatch = errors.New("idempotency key was already used with a different request")
+	ErrGrantRejected       = errors.New("grant cannot be applied")
+	ErrSystemAccount       = errors.New("system account cannot take part in transfers")
+	ErrTransferLimit       = errors.New("transfer limit exceeded")
+	ErrTransferNotFound    = errors.New("pending transfer not found")
+	ErrNotApprover         = errors.New("only the sender's manager or an admin can decide on this transfer")
+	ErrTransferDecided     = errors.New("transfer has already been decided")
+	ErrTransferExpired     = errors.New("pending transfer has expired")
+	ErrInvalidManager      = errors.New("user cannot be their own manager")
+	ErrCoinRequestSelf     = errors.New("can't request coins from yourself")
+	ErrCoinRequestNotFound = errors.New("coin request not found")
+	ErrCoinRequestDecided  = errors.New("coin request has already been answered")
+	ErrCoinRequestExpired  = errors.New("coin request has expired")
+	ErrScheduleNotFound    = errors.New("scheduled transfer not found")
+	ErrBatchRejected       = errors.New("batch transfer cannot be applied")
+)
+
+// LockedError возвращается, пока вход заблокирован после серии неудачных попыток
+type LockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LockedError) Error() string {
+	return ErrTooManyAttempts.Error()
+}
+
+func (e *LockedError) Unwrap() error {
+	return ErrTooManyAttempts
+}
+
+// OrderError перечисляет строки заказа, из-за которых он был отклонён целиком
+type OrderError struct {
+	Lines []domain.OrderLineError
+}
+
+func (e *OrderError) Error() string {
+	return ErrOrderRejected.Error()
+}
+
+func (e *OrderError) Unwrap() error {
+	return ErrOrderRejected
+}
+
+// Лимиты переводов
+const (
+	LimitMaxAmount     = "max_amount"
+	LimitDailyVolume   = "daily_volume"
+	LimitHourlyCount   = "hourly_count"
+	LimitMinAccountAge = "min_account_age"
+	// LimitApproval — сумма запроса монет больше порога одобрения переводов
+	LimitApproval = "approval_threshold"
 )
+
+// LimitError сообщает, какой лимит перевода нарушен, сколько ещё можно перевести
+// и когда лимит освободится (RetryAfter = 0, если ожидание не поможет)
+type LimitError struct {
+	Limit      string        `json:"limit"`
+	Max        uint64        `json:"max"`
+	Remaining  uint64        `json:"remaining"`
+	RetryAfter time.Duration `json:"-"`
+}
+
+func (e *LimitError) Error() string {
+	return ErrTransferLimit.Error()
+}
+
+func (e *LimitError) Unwrap() error {
+	return ErrTransferLimit
+}
+
+// GrantError перечисляет получателей, из-за которых начисление отклонено целиком
+type GrantError struct {
+	Lines []domain.GrantLineError
+}
+
+func (e *GrantError) Error() string {
+	return ErrGrantRejected.Error()
+}
+
+func (e *GrantError) Unwrap() error {
+	return ErrGrantRejected
+}
+
+// BatchError перечисляет строки пакетного перевода, из-за которых он отклонён целиком
+type BatchError struct {
+	Lines []domain.BatchLineError
+}
+
+func (e *BatchError) Error() string {
+	return ErrBatchRejected.Error()
+}
+
+func (e *BatchError) Unwrap() error {
+	return ErrBatchRejected
+}
@@ -0,0 +1,42 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"merch-shop/internal/domain"
+)
+
+// GrantCoins начисляет монеты от имени системного пользователя. Список применяется
+// целиком или не применяется вовсе; в режиме DryRun только проверяется и подсчитывается.
+func (u *UseCase) GrantCoins(ctx context.Context, req domain.GrantRequest) (domain.GrantResult, error) {
+	result := domain.GrantResult{
+		DryRun: req.DryRun,
+		Grants: req.Grants,
+	}
+
+	var (
+		seen     = make(map[string]struct{}, len(req.Grants))
+		failures []domain.GrantLineError
+	)
+
+	for _, line := range req.Grants {
+		if _, ok := seen[line.Username]; ok {
+			failures = append(failures, domain.GrantLineError{Username: line.Username, Reason: domain.LineDuplicate})
+			continue
+		}
+		seen[line.Username] = struct{}{}
+
+		result.Recipients++
+		result.Total += line.Amount
+	}
+
+	if len(failures) > 0 {
+		return domain.GrantResult{}, &GrantError{Lines: failures}
+	}
+
+	if err := u.repo.GrantCoins(ctx, req.Grants, req.Reason, req.DryRun); err != nil {
+		return domain.GrantResult{}, fmt.Errorf("repo.GrantCoins: %w", err)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,105 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseCase_GrantCoins(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name         string
+		req          domain.GrantRequest
+		repoErr      error
+		expectRepo   bool
+		expectResult domain.GrantResult
+		expectLines  []domain.GrantLineError
+		expectErr    error
+	}{
+		{
+			name: "Bulk grant",
+			req: domain.GrantRequest{
+				Reason: "hackathon",
+				Grants: []domain.GrantLine{{Username: "alice", Amount: 500}, {Username: "bob", Amount: 300}},
+			},
+			expectRepo: true,
+			expectResult: domain.GrantResult{
+				Recipients: 2,
+				Total:      800,
+				Grants:     []domain.GrantLine{{Username: "alice", Amount: 500}, {Username: "bob", Amount: 300}},
+			},
+		},
+		{
+			name: "Dry run",
+			req: domain.GrantRequest{
+				Reason: "birthday",
+				Grants: []domain.GrantLine{{Username: "alice", Amount: 100}},
+				DryRun: true,
+			},
+			expectRepo: true,
+			expectResult: domain.GrantResult{
+				DryRun:     true,
+				Recipients: 1,
+				Total:      100,
+				Grants:     []domain.GrantLine{{Username: "alice", Amount: 100}},
+			},
+		},
+		{
+			name: "Duplicate recipient",
+			req: domain.GrantRequest{
+				Reason: "birthday",
+				Grants: []domain.GrantLine{{Username: "alice", Amount: 100}, {Username: "alice", Amount: 50}},
+			},
+			expectLines: []domain.GrantLineError{{Username: "alice", Reason: domain.LineDuplicate}},
+			expectErr:   ErrGrantRejected,
+		},
+		{
+			name: "Unknown recipient",
+			req: domain.GrantRequest{
+				Reason: "birthday",
+				Grants: []domain.GrantLine{{Username: "ghost", Amount: 100}},
+			},
+			repoErr: &GrantError{
+				Lines: []domain.GrantLineError{{Username: "ghost", Reason: domain.LineNotFound}},
+			},
+			expectRepo:  true,
+			expectLines: []domain.GrantLineError{{Username: "ghost", Reason: domain.LineNotFound}},
+			expectErr:   ErrGrantRejected,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, Config{})
+
+			if tt.expectRepo {
+				mockRepo.On("GrantCoins", ctx, tt.req.Grants, tt.req.Reason, tt.req.DryRun).Return(tt.repoErr).Once()
+			}
+
+			result, err := useCase.GrantCoins(ctx, tt.req)
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+
+				var grantErr *GrantError
+				if assert.True(t, errors.As(err, &grantErr)) {
+					assert.Equal(t, tt.expectLines, grantErr.Lines)
+				}
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectResult, result)
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
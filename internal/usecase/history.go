@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"merch-shop/internal/domain"
+)
+
+const defaultHistoryLimit = 20
+
+// ListHistory отдаёт историю операций от новых к старым с курсорной пагинацией
+func (u *UseCase) ListHistory(ctx context.Context, userID uint64, query domain.HistoryQuery) (domain.HistoryPage, error) {
+	filter := domain.HistoryFilter{
+		Direction:    query.Direction,
+		Counterparty: query.Counterparty,
+		MinAmount:    query.MinAmount,
+		MaxAmount:    query.MaxAmount,
+		Limit:        query.Limit,
+	}
+
+	// Колонки хранят время без зоны в UTC
+	if query.From != nil {
+		from := query.From.UTC()
+		filter.From = &from
+	}
+
+	if query.To != nil {
+		to := query.To.UTC()
+		filter.To = &to
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultHistoryLimit
+	}
+
+	if query.Cursor != "" {
+		var cursor idCursor
+		if err := decodeCursor(query.Cursor, &cursor); err != nil || cursor.BeforeID == 0 {
+			return domain.HistoryPage{}, ErrInvalidCursor
+		}
+		filter.BeforeID = cursor.BeforeID
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+
+	entries, err := u.repo.ListTransactions(ctx, userID, filter)
+	if err != nil {
+		return domain.HistoryPage{}, fmt.Errorf("repo.ListTransactions: %w", err)
+	}
+
+	page := domain.HistoryPage{Items: entries}
+	if len(entries) > limit {
+		page.Items = entries[:limit]
+
+		page.NextCursor, err = encodeCursor(idCursor{BeforeID: page.Items[limit-1].ID})
+		if err != nil {
+			return domain.HistoryPage{}, err
+		}
+	}
+
+	return page, nil
+}
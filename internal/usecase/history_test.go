@@ -0,0 +1,55 @@
+package usecase
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUseCase_ListHistory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{})
+
+	entries := []domain.HistoryEntry{
+		{ID: 12, Direction: domain.DirectionSent, Counterparty: "bob", Amount: 10},
+		{ID: 10, Direction: domain.DirectionSent, Counterparty: "bob", Amount: 20},
+	}
+
+	from := time.Date(2025, 1, 1, 3, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+
+	mockRepo.On("ListTransactions", ctx, uint64(1), mock.MatchedBy(func(filter domain.HistoryFilter) bool {
+		// Граница интервала переводится в UTC, в котором хранятся даты
+		return filter.Limit == 2 && filter.BeforeID == 0 &&
+			filter.Direction == domain.DirectionSent && filter.Counterparty == "bob" &&
+			filter.From.Location() == time.UTC && filter.From.Equal(from)
+	})).Return(entries, nil).Once()
+
+	page, err := useCase.ListHistory(ctx, 1, domain.HistoryQuery{
+		Direction:    domain.DirectionSent,
+		Counterparty: "bob",
+		From:         &from,
+		Limit:        1,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, entries[:1], page.Items)
+
+	mockRepo.On("ListTransactions", ctx, uint64(1), mock.MatchedBy(func(filter domain.HistoryFilter) bool {
+		return filter.BeforeID == 12 && filter.Limit == 2
+	})).Return(entries[1:], nil).Once()
+
+	page, err = useCase.ListHistory(ctx, 1, domain.HistoryQuery{Limit: 1, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, entries[1:], page.Items)
+	assert.Empty(t, page.NextCursor)
+
+	mockRepo.AssertExpectations(t)
+}
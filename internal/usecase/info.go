@@ -5,7 +5,9 @@ import (
 	"merch-shop/internal/domain"
 )
 
-func (u *UseCase) GetInfo(ctx context.Context, userID uint64) (domain.Info, error) {
+// GetInfo возвращает баланс, инвентарь и историю операций. historyLimit
+// ограничивает историю последними записями; 0 — вся история.
+func (u *UseCase) GetInfo(ctx context.Context, userID uint64, historyLimit int) (domain.Info, error) {
 	user, err := u.repo.GetUserByID(ctx, userID)
 	if err != nil {
 		return domain.Info{}, err
@@ -16,7 +18,7 @@ func (u *UseCase) GetInfo(ctx context.Context, userID uint64) (domain.Info, erro
 		return domain.Info{}, err
 	}
 
-	history, err := u.repo.GetUserTransactions(ctx, userID)
+	history, err := u.repo.GetUserTransactions(ctx, userID, historyLimit)
 	if err != nil {
 		return domain.Info{}, err
 	}
@@ -36,10 +36,10 @@ func TestUseCase_GetInfo(t *testing.T) {
 			},
 			mockHistory: domain.CoinHistory{
 				Received: []domain.CoinTransaction{
-					{UserName: "bob", Amount: 10},
+					{FromUser: "bob", Amount: 10},
 				},
 				Sent: []domain.CoinTransaction{
-					{UserName: "bob", Amount: 5},
+					{ToUser: "bob", Amount: 5},
 				},
 			},
 			expectErr: false,
@@ -52,10 +52,10 @@ func TestUseCase_GetInfo(t *testing.T) {
 				},
 				CoinHistory: domain.CoinHistory{
 					Received: []domain.CoinTransaction{
-						{UserName: "bob", Amount: 10},
+						{FromUser: "bob", Amount: 10},
 					},
 					Sent: []domain.CoinTransaction{
-						{UserName: "bob", Amount: 5},
+						{ToUser: "bob", Amount: 5},
 					},
 				},
 			},
@@ -90,7 +90,7 @@ func TestUseCase_GetInfo(t *testing.T) {
 				Return(tt.mockUser, tt.mockUserErr).Once()
 
 			if tt.mockUserErr != nil {
-				info, err := useCase.GetInfo(ctx, userID)
+				info, err := useCase.GetInfo(ctx, userID, 0)
 				assert.ErrorContains(t, err, "DB error")
 				assert.Empty(t, info)
 				mockRepo.AssertExpectations(t)
@@ -101,25 +101,25 @@ func TestUseCase_GetInfo(t *testing.T) {
 				Return(tt.mockInventory, tt.mockInvErr).Once()
 
 			if tt.mockInvErr != nil {
-				info, err := useCase.GetInfo(ctx, userID)
+				info, err := useCase.GetInfo(ctx, userID, 0)
 				assert.ErrorContains(t, err, "DB error")
 				assert.Empty(t, info)
 				mockRepo.AssertExpectations(t)
 				return
 			}
 
-			mockRepo.On("GetUserTransactions", ctx, userID).
+			mockRepo.On("GetUserTransactions", ctx, userID, 0).
 				Return(tt.mockHistory, tt.mockHistErr).Once()
 
 			if tt.mockHistErr != nil {
-				info, err := useCase.GetInfo(ctx, userID)
+				info, err := useCase.GetInfo(ctx, userID, 0)
 				assert.ErrorContains(t, err, "DB error")
 				assert.Empty(t, info)
 				mockRepo.AssertExpectations(t)
 				return
 			}
 
-			info, err := useCase.GetInfo(ctx, userID)
+			info, err := useCase.GetInfo(ctx, userID, 0)
 
 			if tt.expectErr {
 				assert.Error(t, err)
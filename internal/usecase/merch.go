@@ -0,0 +1,147 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+)
+
+const (
+	defaultCatalogSort  = "name"
+	defaultCatalogLimit = 20
+)
+
+func (u *UseCase) CreateMerch(ctx context.Context, req domain.CreateMerchRequest) (domain.Merch, error) {
+	item := domain.Merch{
+		Name:     req.Name,
+		Category: req.Category,
+		Price:    req.Price,
+		Stock:    req.Stock,
+
+		ReturnWindowHours: req.ReturnWindowHours,
+	}
+
+	if item.Category == "" {
+		item.Category = domain.DefaultCategory
+	}
+
+	merchID, err := u.repo.CreateMerch(ctx, item)
+	if err != nil {
+		return domain.Merch{}, fmt.Errorf("repo.CreateMerch: %w", err)
+	}
+	item.ID = merchID
+
+	return item, nil
+}
+
+// UpdateMerch переименовывает товар и/или добавляет новую версию цены.
+// Старые версии цены сохраняются, покупки по ним уже совершены.
+func (u *UseCase) UpdateMerch(ctx context.Context, name string, req domain.UpdateMerchRequest) error {
+	if req.EffectiveFrom != nil {
+		// Колонки хранят время без зоны в UTC
+		effectiveFrom := req.EffectiveFrom.UTC()
+		req.EffectiveFrom = &effectiveFrom
+	}
+
+	if err := u.repo.UpdateMerch(ctx, name, req); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrMerchNotFound
+		}
+		return fmt.Errorf("repo.UpdateMerch: %w", err)
+	}
+
+	return nil
+}
+
+// RetireMerch снимает товар с продажи. Купленные ранее экземпляры остаются в инвентаре.
+func (u *UseCase) RetireMerch(ctx context.Context, name string) error {
+	if err := u.repo.RetireMerch(ctx, name); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrMerchNotFound
+		}
+		return fmt.Errorf("repo.RetireMerch: %w", err)
+	}
+
+	return nil
+}
+
+// RestockMerch пополняет остаток и возвращает новое значение; nil — остаток не ограничен
+func (u *UseCase) RestockMerch(ctx context.Context, name string, quantity uint64) (*uint64, error) {
+	stock, err := u.repo.RestockMerch(ctx, name, quantity)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, ErrMerchNotFound
+		}
+		return nil, fmt.Errorf("repo.RestockMerch: %w", err)
+	}
+
+	return stock, nil
+}
+
+func (u *UseCase) SetMerchStock(ctx context.Context, name string, stock *uint64) error {
+	if err := u.repo.SetMerchStock(ctx, name, stock); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return ErrMerchNotFound
+		}
+		return fmt.Errorf("repo.SetMerchStock: %w", err)
+	}
+
+	return nil
+}
+
+// catalogCursor запоминает последний элемент страницы и сортировку,
+// чтобы курсор нельзя было применить к выдаче с другим порядком
+type catalogCursor struct {
+	Sort  string `json:"s"`
+	Name  string `json:"n"`
+	Price uint64 `json:"p,omitempty"`
+}
+
+func (u *UseCase) ListMerch(ctx context.Context, query domain.CatalogQuery) (domain.CatalogPage, error) {
+	filter := domain.CatalogFilter{
+		Category: query.Category,
+		MinPrice: query.MinPrice,
+		MaxPrice: query.MaxPrice,
+		Sort:     query.Sort,
+		Limit:    query.Limit,
+	}
+
+	if filter.Sort == "" {
+		filter.Sort = defaultCatalogSort
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = defaultCatalogLimit
+	}
+
+	if query.Cursor != "" {
+		var cursor catalogCursor
+		if err := decodeCursor(query.Cursor, &cursor); err != nil || cursor.Sort != filter.Sort {
+			return domain.CatalogPage{}, ErrInvalidCursor
+		}
+		filter.After = &domain.CatalogItem{Name: cursor.Name, Price: cursor.Price}
+	}
+
+	// Лишний элемент показывает, есть ли следующая страница
+	limit := filter.Limit
+	filter.Limit++
+
+	items, err := u.repo.ListMerch(ctx, filter)
+	if err != nil {
+		return domain.CatalogPage{}, fmt.Errorf("repo.ListMerch: %w", err)
+	}
+
+	page := domain.CatalogPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+
+		last := page.Items[limit-1]
+		page.NextCursor, err = encodeCursor(catalogCursor{Sort: filter.Sort, Name: last.Name, Price: last.Price})
+		if err != nil {
+			return domain.CatalogPage{}, err
+		}
+	}
+
+	return page, nil
+}
@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUseCase_ListMerch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{})
+
+	firstPage := []domain.CatalogItem{
+		{Name: "pen", Price: 10},
+		{Name: "socks", Price: 10},
+		{Name: "cup", Price: 20},
+	}
+
+	mockRepo.On("ListMerch", ctx, mock.MatchedBy(func(filter domain.CatalogFilter) bool {
+		return filter.After == nil && filter.Limit == 3 && filter.Sort == "price"
+	})).Return(firstPage, nil).Once()
+
+	page, err := useCase.ListMerch(ctx, domain.CatalogQuery{Sort: "price", Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, firstPage[:2], page.Items)
+	assert.NotEmpty(t, page.NextCursor)
+
+	mockRepo.On("ListMerch", ctx, mock.MatchedBy(func(filter domain.CatalogFilter) bool {
+		return filter.After != nil && *filter.After == domain.CatalogItem{Name: "socks", Price: 10}
+	})).Return(firstPage[2:], nil).Once()
+
+	page, err = useCase.ListMerch(ctx, domain.CatalogQuery{Sort: "price", Limit: 2, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, firstPage[2:], page.Items)
+	assert.Empty(t, page.NextCursor)
+
+	// Курсор привязан к сортировке
+	cursor, err := encodeCursor(catalogCursor{Sort: "price", Name: "socks", Price: 10})
+	assert.NoError(t, err)
+
+	_, err = useCase.ListMerch(ctx, domain.CatalogQuery{Sort: "name", Cursor: cursor})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = useCase.ListMerch(ctx, domain.CatalogQuery{Cursor: "not a cursor"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUseCase_UpdateMerch_EffectiveFromUTC(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{})
+
+	// Колонка effective_from без часового пояса, поэтому в репозиторий попадает время в UTC
+	effectiveFrom := time.Date(2025, 3, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	mockRepo.On("UpdateMerch", ctx, "pen", mock.MatchedBy(func(req domain.UpdateMerchRequest) bool {
+		return req.EffectiveFrom != nil && req.EffectiveFrom.Location() == time.UTC &&
+			req.EffectiveFrom.Equal(effectiveFrom)
+	})).Return(nil).Once()
+
+	err := useCase.UpdateMerch(ctx, "pen", domain.UpdateMerchRequest{Price: 20, EffectiveFrom: &effectiveFrom})
+	assert.NoError(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -2,27 +2,52 @@
 
 package mocks
 
-import mock "github.com/stretchr/testify/mock"
+import (
+	domain "merch-shop/internal/domain"
+
+	mock "github.com/stretchr/testify/mock"
+)
 
 // Auth is an autogenerated mock type for the Auth type
 type Auth struct {
 	mock.Mock
 }
 
-// NewAccessToken provides a mock function with given fields: userID
-func (_m *Auth) NewAccessToken(userID uint64) (string, error) {
-	ret := _m.Called(userID)
+// NewAccessToken provides a mock function with given fields: user
+func (_m *Auth) NewAccessToken(user domain.User) (string, error) {
+	ret := _m.Called(user)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(domain.User) string); ok {
+		r0 = rf(user)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(domain.User) error); ok {
+		r1 = rf(user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRefreshToken provides a mock function with given fields:
+func (_m *Auth) NewRefreshToken() (string, error) {
+	ret := _m.Called()
 
 	var r0 string
-	if rf, ok := ret.Get(0).(func(uint64) string); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func() string); ok {
+		r0 = rf()
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(uint64) error); ok {
-		r1 = rf(userID)
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
 	} else {
 		r1 = ret.Error(1)
 	}
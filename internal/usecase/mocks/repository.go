@@ -5,6 +5,7 @@ package mocks
 import (
 	context "context"
 	domain "merch-shop/internal/domain"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
 )
@@ -14,6 +15,41 @@ type Repository struct {
 	mock.Mock
 }
 
+// AcceptCoinRequest provides a mock function with given fields: ctx, requestID, payerID, guard
+func (_m *Repository) AcceptCoinRequest(ctx context.Context, requestID uint64, payerID uint64, guard domain.TransferGuard) (domain.CoinRequest, error) {
+	ret := _m.Called(ctx, requestID, payerID, guard)
+
+	var r0 domain.CoinRequest
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64, domain.TransferGuard) domain.CoinRequest); ok {
+		r0 = rf(ctx, requestID, payerID, guard)
+	} else {
+		r0 = ret.Get(0).(domain.CoinRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64, domain.TransferGuard) error); ok {
+		r1 = rf(ctx, requestID, payerID, guard)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BatchTransferCoins provides a mock function with given fields: ctx, fromUserID, lines, guard
+func (_m *Repository) BatchTransferCoins(ctx context.Context, fromUserID uint64, lines []domain.SendCoinRequest, guard domain.TransferGuard) error {
+	ret := _m.Called(ctx, fromUserID, lines, guard)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, []domain.SendCoinRequest, domain.TransferGuard) error); ok {
+		r0 = rf(ctx, fromUserID, lines, guard)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // BuyMerch provides a mock function with given fields: ctx, userID, itemName, itemPrice
 func (_m *Repository) BuyMerch(ctx context.Context, userID uint64, itemName string, itemPrice uint64) error {
 	ret := _m.Called(ctx, userID, itemName, itemPrice)
@@ -28,20 +64,337 @@ func (_m *Repository) BuyMerch(ctx context.Context, userID uint64, itemName stri
 	return r0
 }
 
-// CreateUser provides a mock function with given fields: ctx, creds
-func (_m *Repository) CreateUser(ctx context.Context, creds domain.Credentials) (uint64, error) {
-	ret := _m.Called(ctx, creds)
+// ClaimDueTransfers provides a mock function with given fields: ctx, now, leaseUntil, limit
+func (_m *Repository) ClaimDueTransfers(ctx context.Context, now time.Time, leaseUntil time.Time, limit int) ([]domain.ScheduledTransfer, error) {
+	ret := _m.Called(ctx, now, leaseUntil, limit)
+
+	var r0 []domain.ScheduledTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, int) []domain.ScheduledTransfer); ok {
+		r0 = rf(ctx, now, leaseUntil, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ScheduledTransfer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time, int) error); ok {
+		r1 = rf(ctx, now, leaseUntil, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CompleteScheduledRun provides a mock function with given fields: ctx, run
+func (_m *Repository) CompleteScheduledRun(ctx context.Context, run domain.ScheduledRun) error {
+	ret := _m.Called(ctx, run)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ScheduledRun) error); ok {
+		r0 = rf(ctx, run)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateCoinRequest provides a mock function with given fields: ctx, request
+func (_m *Repository) CreateCoinRequest(ctx context.Context, request domain.CoinRequest) (domain.CoinRequest, error) {
+	ret := _m.Called(ctx, request)
+
+	var r0 domain.CoinRequest
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CoinRequest) domain.CoinRequest); ok {
+		r0 = rf(ctx, request)
+	} else {
+		r0 = ret.Get(0).(domain.CoinRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.CoinRequest) error); ok {
+		r1 = rf(ctx, request)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateInvite provides a mock function with given fields: ctx, invite
+func (_m *Repository) CreateInvite(ctx context.Context, invite domain.Invite) error {
+	ret := _m.Called(ctx, invite)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Invite) error); ok {
+		r0 = rf(ctx, invite)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateMerch provides a mock function with given fields: ctx, item
+func (_m *Repository) CreateMerch(ctx context.Context, item domain.Merch) (uint64, error) {
+	ret := _m.Called(ctx, item)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Merch) uint64); ok {
+		r0 = rf(ctx, item)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Merch) error); ok {
+		r1 = rf(ctx, item)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateOrder provides a mock function with given fields: ctx, userID, lines
+func (_m *Repository) CreateOrder(ctx context.Context, userID uint64, lines []domain.OrderLine) (domain.Order, error) {
+	ret := _m.Called(ctx, userID, lines)
+
+	var r0 domain.Order
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, []domain.OrderLine) domain.Order); ok {
+		r0 = rf(ctx, userID, lines)
+	} else {
+		r0 = ret.Get(0).(domain.Order)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, []domain.OrderLine) error); ok {
+		r1 = rf(ctx, userID, lines)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreatePendingTransfer provides a mock function with given fields: ctx, transfer, guard
+func (_m *Repository) CreatePendingTransfer(ctx context.Context, transfer domain.PendingTransfer, guard domain.TransferGuard) (domain.PendingTransfer, error) {
+	ret := _m.Called(ctx, transfer, guard)
+
+	var r0 domain.PendingTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, domain.PendingTransfer, domain.TransferGuard) domain.PendingTransfer); ok {
+		r0 = rf(ctx, transfer, guard)
+	} else {
+		r0 = ret.Get(0).(domain.PendingTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.PendingTransfer, domain.TransferGuard) error); ok {
+		r1 = rf(ctx, transfer, guard)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateRefreshToken provides a mock function with given fields: ctx, token
+func (_m *Repository) CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error {
+	ret := _m.Called(ctx, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.RefreshToken) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateScheduledTransfer provides a mock function with given fields: ctx, transfer
+func (_m *Repository) CreateScheduledTransfer(ctx context.Context, transfer domain.ScheduledTransfer) (domain.ScheduledTransfer, error) {
+	ret := _m.Called(ctx, transfer)
+
+	var r0 domain.ScheduledTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ScheduledTransfer) domain.ScheduledTransfer); ok {
+		r0 = rf(ctx, transfer)
+	} else {
+		r0 = ret.Get(0).(domain.ScheduledTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ScheduledTransfer) error); ok {
+		r1 = rf(ctx, transfer)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateUser provides a mock function with given fields: ctx, creds, coins, inviteCode
+func (_m *Repository) CreateUser(ctx context.Context, creds domain.Credentials, coins uint64, inviteCode string) (uint64, error) {
+	ret := _m.Called(ctx, creds, coins, inviteCode)
 
 	var r0 uint64
-	if rf, ok := ret.Get(0).(func(context.Context, domain.Credentials) uint64); ok {
-		r0 = rf(ctx, creds)
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Credentials, uint64, string) uint64); ok {
+		r0 = rf(ctx, creds, coins, inviteCode)
 	} else {
 		r0 = ret.Get(0).(uint64)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, domain.Credentials) error); ok {
-		r1 = rf(ctx, creds)
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Credentials, uint64, string) error); ok {
+		r1 = rf(ctx, creds, coins, inviteCode)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreditAllowances provides a mock function with given fields: ctx, period, activeSince, amount, limit
+func (_m *Repository) CreditAllowances(ctx context.Context, period time.Time, activeSince time.Time, amount uint64, limit int) (int, error) {
+	ret := _m.Called(ctx, period, activeSince, amount, limit)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, time.Time, uint64, int) int); ok {
+		r0 = rf(ctx, period, activeSince, amount, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, time.Time, uint64, int) error); ok {
+		r1 = rf(ctx, period, activeSince, amount, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DecidePendingTransfer provides a mock function with given fields: ctx, decision
+func (_m *Repository) DecidePendingTransfer(ctx context.Context, decision domain.TransferDecision) (domain.PendingTransfer, error) {
+	ret := _m.Called(ctx, decision)
+
+	var r0 domain.PendingTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, domain.TransferDecision) domain.PendingTransfer); ok {
+		r0 = rf(ctx, decision)
+	} else {
+		r0 = ret.Get(0).(domain.PendingTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.TransferDecision) error); ok {
+		r1 = rf(ctx, decision)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeclineCoinRequest provides a mock function with given fields: ctx, requestID, payerID
+func (_m *Repository) DeclineCoinRequest(ctx context.Context, requestID uint64, payerID uint64) (domain.CoinRequest, error) {
+	ret := _m.Called(ctx, requestID, payerID)
+
+	var r0 domain.CoinRequest
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) domain.CoinRequest); ok {
+		r0 = rf(ctx, requestID, payerID)
+	} else {
+		r0 = ret.Get(0).(domain.CoinRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64) error); ok {
+		r1 = rf(ctx, requestID, payerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteScheduledTransfer provides a mock function with given fields: ctx, ownerID, transferID
+func (_m *Repository) DeleteScheduledTransfer(ctx context.Context, ownerID uint64, transferID uint64) error {
+	ret := _m.Called(ctx, ownerID, transferID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) error); ok {
+		r0 = rf(ctx, ownerID, transferID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExpireCoinLots provides a mock function with given fields: ctx, cutoff, limit
+func (_m *Repository) ExpireCoinLots(ctx context.Context, cutoff time.Time, limit int) (int, uint64, error) {
+	ret := _m.Called(ctx, cutoff, limit)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) int); ok {
+		r0 = rf(ctx, cutoff, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 uint64
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) uint64); ok {
+		r1 = rf(ctx, cutoff, limit)
+	} else {
+		r1 = ret.Get(1).(uint64)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, time.Time, int) error); ok {
+		r2 = rf(ctx, cutoff, limit)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ExpirePendingTransfers provides a mock function with given fields: ctx, now, limit
+func (_m *Repository) ExpirePendingTransfers(ctx context.Context, now time.Time, limit int) (int, error) {
+	ret := _m.Called(ctx, now, limit)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) int); ok {
+		r0 = rf(ctx, now, limit)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, now, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetCoinRequest provides a mock function with given fields: ctx, requestID
+func (_m *Repository) GetCoinRequest(ctx context.Context, requestID uint64) (domain.CoinRequest, error) {
+	ret := _m.Called(ctx, requestID)
+
+	var r0 domain.CoinRequest
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) domain.CoinRequest); ok {
+		r0 = rf(ctx, requestID)
+	} else {
+		r0 = ret.Get(0).(domain.CoinRequest)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, requestID)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -70,6 +423,48 @@ func (_m *Repository) GetMerchPrice(ctx context.Context, itemName string) (uint6
 	return r0, r1
 }
 
+// GetRefreshToken provides a mock function with given fields: ctx, tokenHash
+func (_m *Repository) GetRefreshToken(ctx context.Context, tokenHash string) (domain.RefreshToken, error) {
+	ret := _m.Called(ctx, tokenHash)
+
+	var r0 domain.RefreshToken
+	if rf, ok := ret.Get(0).(func(context.Context, string) domain.RefreshToken); ok {
+		r0 = rf(ctx, tokenHash)
+	} else {
+		r0 = ret.Get(0).(domain.RefreshToken)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTokenGeneration provides a mock function with given fields: ctx, userID
+func (_m *Repository) GetTokenGeneration(ctx context.Context, userID uint64) (uint64, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) uint64); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetUserByID provides a mock function with given fields: ctx, userID
 func (_m *Repository) GetUserByID(ctx context.Context, userID uint64) (domain.User, error) {
 	ret := _m.Called(ctx, userID)
@@ -135,20 +530,20 @@ func (_m *Repository) GetUserInventory(ctx context.Context, userID uint64) ([]do
 	return r0, r1
 }
 
-// GetUserTransactions provides a mock function with given fields: ctx, userID
-func (_m *Repository) GetUserTransactions(ctx context.Context, userID uint64) (domain.CoinHistory, error) {
-	ret := _m.Called(ctx, userID)
+// GetUserTransactions provides a mock function with given fields: ctx, userID, limit
+func (_m *Repository) GetUserTransactions(ctx context.Context, userID uint64, limit int) (domain.CoinHistory, error) {
+	ret := _m.Called(ctx, userID, limit)
 
 	var r0 domain.CoinHistory
-	if rf, ok := ret.Get(0).(func(context.Context, uint64) domain.CoinHistory); ok {
-		r0 = rf(ctx, userID)
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, int) domain.CoinHistory); ok {
+		r0 = rf(ctx, userID, limit)
 	} else {
 		r0 = ret.Get(0).(domain.CoinHistory)
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
-		r1 = rf(ctx, userID)
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, int) error); ok {
+		r1 = rf(ctx, userID, limit)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -156,13 +551,13 @@ func (_m *Repository) GetUserTransactions(ctx context.Context, userID uint64) (d
 	return r0, r1
 }
 
-// TransferCoins provides a mock function with given fields: ctx, fromUserID, toUserID, amount
-func (_m *Repository) TransferCoins(ctx context.Context, fromUserID uint64, toUserID uint64, amount uint64) error {
-	ret := _m.Called(ctx, fromUserID, toUserID, amount)
+// GrantCoins provides a mock function with given fields: ctx, lines, reason, dryRun
+func (_m *Repository) GrantCoins(ctx context.Context, lines []domain.GrantLine, reason string, dryRun bool) error {
+	ret := _m.Called(ctx, lines, reason, dryRun)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64, uint64) error); ok {
-		r0 = rf(ctx, fromUserID, toUserID, amount)
+	if rf, ok := ret.Get(0).(func(context.Context, []domain.GrantLine, string, bool) error); ok {
+		r0 = rf(ctx, lines, reason, dryRun)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -170,6 +565,470 @@ func (_m *Repository) TransferCoins(ctx context.Context, fromUserID uint64, toUs
 	return r0
 }
 
+// IsAccessTokenRevoked provides a mock function with given fields: ctx, tokenID
+func (_m *Repository) IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error) {
+	ret := _m.Called(ctx, tokenID)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, tokenID)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCoinLots provides a mock function with given fields: ctx, userID
+func (_m *Repository) ListCoinLots(ctx context.Context, userID uint64) ([]domain.CoinLot, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 []domain.CoinLot
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) []domain.CoinLot); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.CoinLot)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListCoinRequests provides a mock function with given fields: ctx, userID, filter
+func (_m *Repository) ListCoinRequests(ctx context.Context, userID uint64, filter domain.CoinRequestFilter) ([]domain.CoinRequest, error) {
+	ret := _m.Called(ctx, userID, filter)
+
+	var r0 []domain.CoinRequest
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.CoinRequestFilter) []domain.CoinRequest); ok {
+		r0 = rf(ctx, userID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.CoinRequest)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.CoinRequestFilter) error); ok {
+		r1 = rf(ctx, userID, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListMerch provides a mock function with given fields: ctx, filter
+func (_m *Repository) ListMerch(ctx context.Context, filter domain.CatalogFilter) ([]domain.CatalogItem, error) {
+	ret := _m.Called(ctx, filter)
+
+	var r0 []domain.CatalogItem
+	if rf, ok := ret.Get(0).(func(context.Context, domain.CatalogFilter) []domain.CatalogItem); ok {
+		r0 = rf(ctx, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.CatalogItem)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.CatalogFilter) error); ok {
+		r1 = rf(ctx, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListOrders provides a mock function with given fields: ctx, userID, filter
+func (_m *Repository) ListOrders(ctx context.Context, userID uint64, filter domain.OrderFilter) ([]domain.Order, error) {
+	ret := _m.Called(ctx, userID, filter)
+
+	var r0 []domain.Order
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.OrderFilter) []domain.Order); ok {
+		r0 = rf(ctx, userID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.Order)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.OrderFilter) error); ok {
+		r1 = rf(ctx, userID, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListPendingApprovals provides a mock function with given fields: ctx, approverID, role
+func (_m *Repository) ListPendingApprovals(ctx context.Context, approverID uint64, role domain.Role) ([]domain.PendingTransfer, error) {
+	ret := _m.Called(ctx, approverID, role)
+
+	var r0 []domain.PendingTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.Role) []domain.PendingTransfer); ok {
+		r0 = rf(ctx, approverID, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.PendingTransfer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.Role) error); ok {
+		r1 = rf(ctx, approverID, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListScheduledTransfers provides a mock function with given fields: ctx, ownerID
+func (_m *Repository) ListScheduledTransfers(ctx context.Context, ownerID uint64) ([]domain.ScheduledTransfer, error) {
+	ret := _m.Called(ctx, ownerID)
+
+	var r0 []domain.ScheduledTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) []domain.ScheduledTransfer); ok {
+		r0 = rf(ctx, ownerID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.ScheduledTransfer)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, ownerID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListTransactions provides a mock function with given fields: ctx, userID, filter
+func (_m *Repository) ListTransactions(ctx context.Context, userID uint64, filter domain.HistoryFilter) ([]domain.HistoryEntry, error) {
+	ret := _m.Called(ctx, userID, filter)
+
+	var r0 []domain.HistoryEntry
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.HistoryFilter) []domain.HistoryEntry); ok {
+		r0 = rf(ctx, userID, filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]domain.HistoryEntry)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, domain.HistoryFilter) error); ok {
+		r1 = rf(ctx, userID, filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// OpenAnomaly provides a mock function with given fields: ctx, anomaly
+func (_m *Repository) OpenAnomaly(ctx context.Context, anomaly domain.Anomaly) (bool, error) {
+	ret := _m.Called(ctx, anomaly)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Anomaly) bool); ok {
+		r0 = rf(ctx, anomaly)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.Anomaly) error); ok {
+		r1 = rf(ctx, anomaly)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReconcileBalances provides a mock function with given fields: ctx
+func (_m *Repository) ReconcileBalances(ctx context.Context) (domain.ReconciliationReport, error) {
+	ret := _m.Called(ctx)
+
+	var r0 domain.ReconciliationReport
+	if rf, ok := ret.Get(0).(func(context.Context) domain.ReconciliationReport); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(domain.ReconciliationReport)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RestockMerch provides a mock function with given fields: ctx, name, quantity
+func (_m *Repository) RestockMerch(ctx context.Context, name string, quantity uint64) (*uint64, error) {
+	ret := _m.Called(ctx, name, quantity)
+
+	var r0 *uint64
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint64) *uint64); ok {
+		r0 = rf(ctx, name, quantity)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*uint64)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, string, uint64) error); ok {
+		r1 = rf(ctx, name, quantity)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RetireMerch provides a mock function with given fields: ctx, name
+func (_m *Repository) RetireMerch(ctx context.Context, name string) error {
+	ret := _m.Called(ctx, name)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, name)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReturnOrder provides a mock function with given fields: ctx, userID, orderID, items, defaultWindow
+func (_m *Repository) ReturnOrder(ctx context.Context, userID uint64, orderID uint64, items []string, defaultWindow time.Duration) (domain.Refund, error) {
+	ret := _m.Called(ctx, userID, orderID, items, defaultWindow)
+
+	var r0 domain.Refund
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64, []string, time.Duration) domain.Refund); ok {
+		r0 = rf(ctx, userID, orderID, items, defaultWindow)
+	} else {
+		r0 = ret.Get(0).(domain.Refund)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64, uint64, []string, time.Duration) error); ok {
+		r1 = rf(ctx, userID, orderID, items, defaultWindow)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeAccessToken provides a mock function with given fields: ctx, session
+func (_m *Repository) RevokeAccessToken(ctx context.Context, session domain.Session) error {
+	ret := _m.Called(ctx, session)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.Session) error); ok {
+		r0 = rf(ctx, session)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeRefreshTokenFamily provides a mock function with given fields: ctx, familyID
+func (_m *Repository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	ret := _m.Called(ctx, familyID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, familyID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeUserSessions provides a mock function with given fields: ctx, userID
+func (_m *Repository) RevokeUserSessions(ctx context.Context, userID uint64) (uint64, error) {
+	ret := _m.Called(ctx, userID)
+
+	var r0 uint64
+	if rf, ok := ret.Get(0).(func(context.Context, uint64) uint64); ok {
+		r0 = rf(ctx, userID)
+	} else {
+		r0 = ret.Get(0).(uint64)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, uint64) error); ok {
+		r1 = rf(ctx, userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RotateRefreshToken provides a mock function with given fields: ctx, oldTokenID, next
+func (_m *Repository) RotateRefreshToken(ctx context.Context, oldTokenID uint64, next domain.RefreshToken) error {
+	ret := _m.Called(ctx, oldTokenID, next)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.RefreshToken) error); ok {
+		r0 = rf(ctx, oldTokenID, next)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SaveFailedLogin provides a mock function with given fields: ctx, attempt
+func (_m *Repository) SaveFailedLogin(ctx context.Context, attempt domain.FailedLogin) error {
+	ret := _m.Called(ctx, attempt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, domain.FailedLogin) error); ok {
+		r0 = rf(ctx, attempt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetMerchStock provides a mock function with given fields: ctx, name, stock
+func (_m *Repository) SetMerchStock(ctx context.Context, name string, stock *uint64) error {
+	ret := _m.Called(ctx, name, stock)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, *uint64) error); ok {
+		r0 = rf(ctx, name, stock)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetUserManager provides a mock function with given fields: ctx, userID, managerID
+func (_m *Repository) SetUserManager(ctx context.Context, userID uint64, managerID uint64) error {
+	ret := _m.Called(ctx, userID, managerID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64) error); ok {
+		r0 = rf(ctx, userID, managerID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SetUserRole provides a mock function with given fields: ctx, userID, role
+func (_m *Repository) SetUserRole(ctx context.Context, userID uint64, role domain.Role) error {
+	ret := _m.Called(ctx, userID, role)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, domain.Role) error); ok {
+		r0 = rf(ctx, userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// TransferCoins provides a mock function with given fields: ctx, fromUserID, toUserID, amount, memo, guard
+func (_m *Repository) TransferCoins(ctx context.Context, fromUserID uint64, toUserID uint64, amount uint64, memo string, guard domain.TransferGuard) error {
+	ret := _m.Called(ctx, fromUserID, toUserID, amount, memo, guard)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, uint64, uint64, string, domain.TransferGuard) error); ok {
+		r0 = rf(ctx, fromUserID, toUserID, amount, memo, guard)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateMerch provides a mock function with given fields: ctx, name, req
+func (_m *Repository) UpdateMerch(ctx context.Context, name string, req domain.UpdateMerchRequest) error {
+	ret := _m.Called(ctx, name, req)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, domain.UpdateMerchRequest) error); ok {
+		r0 = rf(ctx, name, req)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePassword provides a mock function with given fields: ctx, userID, passwordHash
+func (_m *Repository) UpdatePassword(ctx context.Context, userID uint64, passwordHash string) error {
+	ret := _m.Called(ctx, userID, passwordHash)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint64, string) error); ok {
+		r0 = rf(ctx, userID, passwordHash)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateScheduledTransfer provides a mock function with given fields: ctx, transfer
+func (_m *Repository) UpdateScheduledTransfer(ctx context.Context, transfer domain.ScheduledTransfer) (domain.ScheduledTransfer, error) {
+	ret := _m.Called(ctx, transfer)
+
+	var r0 domain.ScheduledTransfer
+	if rf, ok := ret.Get(0).(func(context.Context, domain.ScheduledTransfer) domain.ScheduledTransfer); ok {
+		r0 = rf(ctx, transfer)
+	} else {
+		r0 = ret.Get(0).(domain.ScheduledTransfer)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, domain.ScheduledTransfer) error); ok {
+		r1 = rf(ctx, transfer)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 type mockConstructorTestingTNewRepository interface {
 	mock.TestingT
 	Cleanup(func())
@@ -0,0 +1,79 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"merch-shop/internal/domain"
+)
+
+const defaultOrdersLimit = 20
+
+// CreateOrder покупает несколько товаров разом. Повторяющиеся позиции
+// объединяются, цены берутся только из каталога.
+func (u *UseCase) CreateOrder(ctx context.Context, userID uint64, req domain.OrderRequest) (domain.Order, error) {
+	lines := make([]domain.OrderLine, 0, len(req.Items))
+	index := make(map[string]int, len(req.Items))
+
+	for _, line := range req.Items {
+		if i, ok := index[line.Item]; ok {
+			lines[i].Quantity += line.Quantity
+			continue
+		}
+		index[line.Item] = len(lines)
+		lines = append(lines, line)
+	}
+
+	order, err := u.repo.CreateOrder(ctx, userID, lines)
+	if err != nil {
+		return domain.Order{}, fmt.Errorf("repo.CreateOrder: %w", err)
+	}
+
+	return order, nil
+}
+
+// ListOrders отдаёт заказы пользователя от новых к старым вместе с ценами, по которым они оплачены
+func (u *UseCase) ListOrders(ctx context.Context, userID uint64, query domain.OrderQuery) (domain.OrderPage, error) {
+	filter := domain.OrderFilter{Limit: query.Limit}
+	if filter.Limit == 0 {
+		filter.Limit = defaultOrdersLimit
+	}
+
+	if query.Cursor != "" {
+		var cursor idCursor
+		if err := decodeCursor(query.Cursor, &cursor); err != nil || cursor.BeforeID == 0 {
+			return domain.OrderPage{}, ErrInvalidCursor
+		}
+		filter.BeforeID = cursor.BeforeID
+	}
+
+	limit := filter.Limit
+	filter.Limit++
+
+	orders, err := u.repo.ListOrders(ctx, userID, filter)
+	if err != nil {
+		return domain.OrderPage{}, fmt.Errorf("repo.ListOrders: %w", err)
+	}
+
+	page := domain.OrderPage{Orders: orders}
+	if len(orders) > limit {
+		page.Orders = orders[:limit]
+
+		page.NextCursor, err = encodeCursor(idCursor{BeforeID: page.Orders[limit-1].ID})
+		if err != nil {
+			return domain.OrderPage{}, err
+		}
+	}
+
+	return page, nil
+}
+
+// ReturnOrder возвращает товары заказа и зачисляет монеты по цене покупки.
+// Срок возврата берётся из товара, а если он не задан — из конфигурации.
+func (u *UseCase) ReturnOrder(ctx context.Context, userID, orderID uint64, req domain.ReturnRequest) (domain.Refund, error) {
+	refund, err := u.repo.ReturnOrder(ctx, userID, orderID, req.Items, u.cfg.ReturnWindow)
+	if err != nil {
+		return domain.Refund{}, fmt.Errorf("repo.ReturnOrder: %w", err)
+	}
+
+	return refund, nil
+}
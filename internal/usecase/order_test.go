@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUseCase_CreateOrder(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{})
+
+	// Повторяющиеся позиции схлопываются с сохранением порядка
+	mockRepo.On("CreateOrder", ctx, uint64(1), []domain.OrderLine{
+		{Item: "cup", Quantity: 3},
+		{Item: "pen", Quantity: 1},
+	}).Return(domain.Order{ID: 7, Total: 70}, nil).Once()
+
+	order, err := useCase.CreateOrder(ctx, 1, domain.OrderRequest{Items: []domain.OrderLine{
+		{Item: "cup", Quantity: 1},
+		{Item: "pen", Quantity: 1},
+		{Item: "cup", Quantity: 2},
+	}})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), order.ID)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUseCase_ListOrders(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{})
+
+	orders := []domain.Order{{ID: 9}, {ID: 8}, {ID: 5}}
+
+	mockRepo.On("ListOrders", ctx, uint64(1), domain.OrderFilter{Limit: 3}).Return(orders, nil).Once()
+
+	page, err := useCase.ListOrders(ctx, 1, domain.OrderQuery{Limit: 2})
+	assert.NoError(t, err)
+	assert.Equal(t, orders[:2], page.Orders)
+	assert.NotEmpty(t, page.NextCursor)
+
+	mockRepo.On("ListOrders", ctx, uint64(1), mock.MatchedBy(func(filter domain.OrderFilter) bool {
+		return filter.BeforeID == 8
+	})).Return(orders[2:], nil).Once()
+
+	page, err = useCase.ListOrders(ctx, 1, domain.OrderQuery{Limit: 2, Cursor: page.NextCursor})
+	assert.NoError(t, err)
+	assert.Equal(t, orders[2:], page.Orders)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = useCase.ListOrders(ctx, 1, domain.OrderQuery{Cursor: "%%%"})
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUseCase_ReturnOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		items        []string
+		mockRefund   domain.Refund
+		mockRepoErr  error
+		expectRefund domain.Refund
+		expectErr    error
+	}{
+		{
+			name:         "partial return",
+			items:        []string{"pen"},
+			mockRefund:   domain.Refund{OrderID: 7, Amount: 20},
+			expectRefund: domain.Refund{OrderID: 7, Amount: 20},
+		},
+		{
+			name:         "whole order",
+			mockRefund:   domain.Refund{OrderID: 7, Amount: 40},
+			expectRefund: domain.Refund{OrderID: 7, Amount: 40},
+		},
+		{
+			name:        "return window expired",
+			mockRepoErr: ErrReturnWindowExpired,
+			expectErr:   ErrReturnWindowExpired,
+		},
+		{
+			name:        "order of another user",
+			mockRepoErr: ErrOrderNotFound,
+			expectErr:   ErrOrderNotFound,
+		},
+		{
+			name:        "already returned",
+			items:       []string{"pen"},
+			mockRepoErr: ErrNothingToReturn,
+			expectErr:   ErrNothingToReturn,
+		},
+		{
+			name:        "item not owned",
+			mockRepoErr: ErrItemNotOwned,
+			expectErr:   ErrItemNotOwned,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, Config{ReturnWindow: 14 * 24 * time.Hour})
+
+			// Срок возврата из конфигурации передаётся в репозиторий для товаров без своего срока
+			mockRepo.On("ReturnOrder", ctx, uint64(1), uint64(7), tt.items, 14*24*time.Hour).
+				Return(tt.mockRefund, tt.mockRepoErr).Once()
+
+			refund, err := useCase.ReturnOrder(ctx, 1, 7, domain.ReturnRequest{Items: tt.items})
+			if tt.expectErr != nil {
+				assert.ErrorIs(t, err, tt.expectErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.expectRefund, refund)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"merch-shop/internal/domain"
+	"time"
+)
+
+// TransferLimits задаёт лимиты исходящих переводов; нулевое значение отключает лимит
+type TransferLimits struct {
+	MaxAmount      uint64
+	MaxDailyVolume uint64
+	MaxPerHour     int
+	MinAccountAge  time.Duration
+}
+
+// TransferAttempt — перевод, который проверяют политики
+type TransferAttempt struct {
+	From   domain.User
+	Amount uint64
+	Stats  domain.TransferStats
+	Now    time.Time
+}
+
+// TransferPolicy разрешает перевод или возвращает ошибку, обычно *LimitError
+type TransferPolicy interface {
+	Check(attempt TransferAttempt) error
+}
+
+type TransferPolicyFunc func(attempt TransferAttempt) error
+
+func (f TransferPolicyFunc) Check(attempt TransferAttempt) error {
+	return f(attempt)
+}
+
+// TransferPolicies проверяет политики по порядку и возвращает первое нарушение
+type TransferPolicies []TransferPolicy
+
+func (p TransferPolicies) Check(attempt TransferAttempt) error {
+	for _, policy := range p {
+		if err := policy.Check(attempt); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// NewTransferPolicies собирает цепочку из включённых лимитов
+func NewTransferPolicies(limits TransferLimits) TransferPolicies {
+	var policies TransferPolicies
+
+	if limits.MinAccountAge > 0 {
+		policies = append(policies, MinAccountAge(limits.MinAccountAge))
+	}
+	if limits.MaxAmount > 0 {
+		policies = append(policies, MaxTransferAmount(limits.MaxAmount))
+	}
+	if limits.MaxPerHour > 0 {
+		policies = append(policies, MaxTransfersPerHour(limits.MaxPerHour))
+	}
+	if limits.MaxDailyVolume > 0 {
+		policies = append(policies, MaxDailyVolume(limits.MaxDailyVolume))
+	}
+
+	return policies
+}
+
+func MaxTransferAmount(limit uint64) TransferPolicy {
+	return TransferPolicyFunc(func(attempt TransferAttempt) error {
+		if attempt.Amount <= limit {
+			return nil
+		}
+
+		return &LimitError{Limit: LimitMaxAmount, Max: limit, Remaining: limit}
+	})
+}
+
+// MaxDailyVolume ограничивает сумму исходящих переводов за последние 24 часа
+func MaxDailyVolume(limit uint64) TransferPolicy {
+	return TransferPolicyFunc(func(attempt TransferAttempt) error {
+		sent := attempt.Stats.SentLastDay
+		if sent+attempt.Amount <= limit {
+			return nil
+		}
+
+		var remaining uint64
+		if sent < limit {
+			remaining = limit - sent
+		}
+
+		return &LimitError{Limit: LimitDailyVolume, Max: limit, Remaining: remaining}
+	})
+}
+
+// MaxTransfersPerHour ограничивает число исходящих переводов за последний час
+func MaxTransfersPerHour(limit int) TransferPolicy {
+	return TransferPolicyFunc(func(attempt TransferAttempt) error {
+		if attempt.Stats.TransfersLastHour < limit {
+			return nil
+		}
+
+		var retryAfter time.Duration
+		if !attempt.Stats.FirstInHour.IsZero() {
+			retryAfter = attempt.Stats.FirstInHour.Add(time.Hour).Sub(attempt.Now)
+		}
+
+		return &LimitError{Limit: LimitHourlyCount, Max: uint64(limit), RetryAfter: max(retryAfter, 0)}
+	})
+}
+
+// MinAccountAge запрещает переводы с только что созданных учётных записей
+func MinAccountAge(age time.Duration) TransferPolicy {
+	return TransferPolicyFunc(func(attempt TransferAttempt) error {
+		allowedAt := attempt.From.CreatedAt.Add(age)
+		if !attempt.Now.Before(allowedAt) {
+			return nil
+		}
+
+		return &LimitError{Limit: LimitMinAccountAge, RetryAfter: allowedAt.Sub(attempt.Now)}
+	})
+}
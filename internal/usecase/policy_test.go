@@ -0,0 +1,131 @@
+package usecase
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestTransferPolicies(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	veteran := domain.User{ID: 1, CreatedAt: now.Add(-30 * 24 * time.Hour)}
+
+	policies := NewTransferPolicies(TransferLimits{
+		MaxAmount:      500,
+		MaxDailyVolume: 1000,
+		MaxPerHour:     3,
+		MinAccountAge:  24 * time.Hour,
+	})
+
+	for _, tt := range []struct {
+		name     string
+		attempt  TransferAttempt
+		expected *LimitError
+	}{
+		{
+			name: "Within all limits",
+			attempt: TransferAttempt{
+				From:   veteran,
+				Amount: 200,
+				Stats:  domain.TransferStats{SentLastDay: 800, TransfersLastHour: 2},
+				Now:    now,
+			},
+		},
+		{
+			name:     "Single transfer too large",
+			attempt:  TransferAttempt{From: veteran, Amount: 501, Now: now},
+			expected: &LimitError{Limit: LimitMaxAmount, Max: 500, Remaining: 500},
+		},
+		{
+			name: "Daily volume exhausted",
+			attempt: TransferAttempt{
+				From:   veteran,
+				Amount: 300,
+				Stats:  domain.TransferStats{SentLastDay: 850},
+				Now:    now,
+			},
+			expected: &LimitError{Limit: LimitDailyVolume, Max: 1000, Remaining: 150},
+		},
+		{
+			name: "Too many transfers this hour",
+			attempt: TransferAttempt{
+				From:   veteran,
+				Amount: 10,
+				Stats:  domain.TransferStats{TransfersLastHour: 3, FirstInHour: now.Add(-40 * time.Minute)},
+				Now:    now,
+			},
+			expected: &LimitError{Limit: LimitHourlyCount, Max: 3, RetryAfter: 20 * time.Minute},
+		},
+		{
+			name: "Account too new",
+			attempt: TransferAttempt{
+				From:   domain.User{ID: 2, CreatedAt: now.Add(-time.Hour)},
+				Amount: 10,
+				Now:    now,
+			},
+			expected: &LimitError{Limit: LimitMinAccountAge, RetryAfter: 23 * time.Hour},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := policies.Check(tt.attempt)
+			if tt.expected == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, ErrTransferLimit)
+			assert.Equal(t, tt.expected, err)
+		})
+	}
+}
+
+func TestNewTransferPolicies_Disabled(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, NewTransferPolicies(TransferLimits{}))
+}
+
+func TestUseCase_SendCoinWithLimits(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{
+		TransferLimits: TransferLimits{MaxDailyVolume: 100},
+	})
+
+	from := domain.User{ID: 1, Coins: 1000}
+	to := domain.User{ID: 2, Credentials: domain.Credentials{Username: "ivanov"}}
+
+	mockRepo.On("GetUserByID", ctx, from.ID).Return(from, nil).Once()
+	mockRepo.On("GetUserByUsername", ctx, "ivanov").Return(to, nil).Once()
+	mockRepo.On("TransferCoins", ctx, from.ID, to.ID, uint64(50), "", mock.Anything).Return(
+		func(_ context.Context, _, _, _ uint64, _ string, guard domain.TransferGuard) error {
+			// Окна отсчитываются от момента перевода
+			assert.WithinDuration(t, time.Now().Add(-24*time.Hour), guard.DaySince, time.Minute)
+			assert.WithinDuration(t, time.Now().Add(-time.Hour), guard.HourSince, time.Minute)
+
+			return guard.Check(domain.TransferStats{SentLastDay: 80})
+		}).Once()
+
+	_, err := useCase.SendCoin(ctx, from.ID, domain.SendCoinRequest{ToUser: "ivanov", Amount: 50})
+	assert.ErrorIs(t, err, ErrTransferLimit)
+
+	var limitErr *LimitError
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.Equal(t, &LimitError{Limit: LimitDailyVolume, Max: 100, Remaining: 20}, limitErr)
+	}
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,50 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"merch-shop/internal/domain"
+)
+
+// Reconcile сверяет балансы кошельков с главной книгой и историей операций.
+// Если включено, по каждому расхождению открывается аномалия.
+func (u *UseCase) Reconcile(ctx context.Context) (domain.ReconciliationReport, error) {
+	report, err := u.repo.ReconcileBalances(ctx)
+	if err != nil {
+		return domain.ReconciliationReport{}, fmt.Errorf("repo.ReconcileBalances: %w", err)
+	}
+
+	if !u.cfg.ReconcileAnomalies {
+		return report, nil
+	}
+
+	anomalies := make([]domain.Anomaly, 0, len(report.Mismatches)+len(report.UnbalancedEntries))
+	for _, mismatch := range report.Mismatches {
+		anomalies = append(anomalies, domain.Anomaly{
+			Kind:    domain.AnomalyBalanceMismatch,
+			Ref:     fmt.Sprintf("user:%d", mismatch.UserID),
+			Details: mismatch,
+		})
+	}
+
+	for _, entryID := range report.UnbalancedEntries {
+		anomalies = append(anomalies, domain.Anomaly{
+			Kind:    domain.AnomalyUnbalancedEntry,
+			Ref:     fmt.Sprintf("entry:%d", entryID),
+			Details: map[string]uint64{"entryId": entryID},
+		})
+	}
+
+	for _, anomaly := range anomalies {
+		opened, err := u.repo.OpenAnomaly(ctx, anomaly)
+		if err != nil {
+			return report, fmt.Errorf("repo.OpenAnomaly: %w", err)
+		}
+
+		if opened {
+			report.AnomaliesOpened++
+		}
+	}
+
+	return report, nil
+}
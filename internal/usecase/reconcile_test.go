@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseCase_Reconcile(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mismatch := domain.BalanceMismatch{UserID: 7, Username: "bob", Cached: 900, Ledger: 1000, History: 1000}
+	drifted := domain.ReconciliationReport{
+		Wallets:           3,
+		Mismatches:        []domain.BalanceMismatch{mismatch},
+		UnbalancedEntries: []uint64{42},
+	}
+
+	for _, tt := range []struct {
+		name            string
+		anomalies       bool
+		report          domain.ReconciliationReport
+		repoErr         error
+		alreadyOpen     map[string]bool
+		expectAnomalies []domain.Anomaly
+		expectOpened    int
+		expectErr       bool
+	}{
+		{
+			name:      "Consistent",
+			anomalies: true,
+			report:    domain.ReconciliationReport{Wallets: 3},
+		},
+		{
+			name:      "Drift opens anomalies",
+			anomalies: true,
+			report:    drifted,
+			expectAnomalies: []domain.Anomaly{
+				{Kind: domain.AnomalyBalanceMismatch, Ref: "user:7", Details: mismatch},
+				{Kind: domain.AnomalyUnbalancedEntry, Ref: "entry:42", Details: map[string]uint64{"entryId": 42}},
+			},
+			expectOpened: 2,
+		},
+		{
+			name:        "Already open anomaly is not counted",
+			anomalies:   true,
+			report:      drifted,
+			alreadyOpen: map[string]bool{"user:7": true},
+			expectAnomalies: []domain.Anomaly{
+				{Kind: domain.AnomalyBalanceMismatch, Ref: "user:7", Details: mismatch},
+				{Kind: domain.AnomalyUnbalancedEntry, Ref: "entry:42", Details: map[string]uint64{"entryId": 42}},
+			},
+			expectOpened: 1,
+		},
+		{
+			name:   "Anomalies disabled",
+			report: drifted,
+		},
+		{
+			name:      "Repository error",
+			anomalies: true,
+			repoErr:   errors.New("db error"),
+			expectErr: true,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, Config{ReconcileAnomalies: tt.anomalies})
+
+			mockRepo.On("ReconcileBalances", ctx).Return(tt.report, tt.repoErr).Once()
+			for _, anomaly := range tt.expectAnomalies {
+				mockRepo.On("OpenAnomaly", ctx, anomaly).Return(!tt.alreadyOpen[anomaly.Ref], nil).Once()
+			}
+
+			report, err := useCase.Reconcile(ctx)
+			if tt.expectErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.expectOpened, report.AnomaliesOpened)
+				assert.Equal(t, len(tt.report.Mismatches) == 0 && len(tt.report.UnbalancedEntries) == 0, report.Consistent())
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
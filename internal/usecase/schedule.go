@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/idempotency"
+	"time"
+)
+
+const (
+	// scheduleBatchSize ограничивает число переводов, занимаемых за один запрос
+	scheduleBatchSize = 50
+	// scheduleKeyTTL должен пережить аренду: повторный запуск того же срока после сбоя
+	// реплики упрётся в ключ идемпотентности и не переведёт монеты второй раз
+	scheduleKeyTTL     = 24 * time.Hour
+	maxScheduleBackoff = 24 * time.Hour
+)
+
+func (u *UseCase) CreateScheduledTransfer(ctx context.Context, ownerID uint64, req domain.ScheduledTransferRequest) (domain.ScheduledTransfer, error) {
+	transfer, err := u.scheduledTransfer(ctx, ownerID, req)
+	if err != nil {
+		return domain.ScheduledTransfer{}, err
+	}
+
+	transfer, err = u.repo.CreateScheduledTransfer(ctx, transfer)
+	if err != nil {
+		return domain.ScheduledTransfer{}, fmt.Errorf("repo.CreateScheduledTransfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+func (u *UseCase) ListScheduledTransfers(ctx context.Context, ownerID uint64) ([]domain.ScheduledTransfer, error) {
+	transfers, err := u.repo.ListScheduledTransfers(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("repo.ListScheduledTransfers: %w", err)
+	}
+
+	return transfers, nil
+}
+
+// UpdateScheduledTransfer заменяет параметры перевода; расписание начинается заново с StartAt
+func (u *UseCase) UpdateScheduledTransfer(ctx context.Context, ownerID, transferID uint64, req domain.ScheduledTransferRequest) (domain.ScheduledTransfer, error) {
+	transfer, err := u.scheduledTransfer(ctx, ownerID, req)
+	if err != nil {
+		return domain.ScheduledTransfer{}, err
+	}
+	transfer.ID = transferID
+
+	transfer, err = u.repo.UpdateScheduledTransfer(ctx, transfer)
+	if err != nil {
+		return domain.ScheduledTransfer{}, fmt.Errorf("repo.UpdateScheduledTransfer: %w", err)
+	}
+
+	return transfer, nil
+}
+
+func (u *UseCase) DeleteScheduledTransfer(ctx context.Context, ownerID, transferID uint64) error {
+	if err := u.repo.DeleteScheduledTransfer(ctx, ownerID, transferID); err != nil {
+		return fmt.Errorf("repo.DeleteScheduledTransfer: %w", err)
+	}
+
+	return nil
+}
+
+func (u *UseCase) scheduledTransfer(ctx context.Context, ownerID uint64, req domain.ScheduledTransferRequest) (domain.ScheduledTransfer, error) {
+	if req.ToUser == domain.SystemUsername {
+		return domain.ScheduledTransfer{}, ErrSystemAccount
+	}
+
+	toUser, err := u.userByUsername(ctx, req.ToUser)
+	if err != nil {
+		return domain.ScheduledTransfer{}, err
+	}
+
+	if toUser.ID == ownerID {
+		return domain.ScheduledTransfer{}, ErrSendCoin
+	}
+
+	transfer := domain.ScheduledTransfer{
+		OwnerID:   ownerID,
+		ToUserID:  toUser.ID,
+		ToUser:    toUser.Username,
+		Amount:    req.Amount,
+		Memo:      req.Memo,
+		Repeat:    req.Repeat,
+		NextRunAt: req.StartAt.UTC(),
+		Active:    req.Active == nil || *req.Active,
+	}
+	if req.StartAt.IsZero() {
+		transfer.NextRunAt = time.Now().UTC()
+	}
+
+	return transfer, nil
+}
+
+// RunScheduledTransfers выполняет все переводы, срок которых наступил, через SendCoin —
+// с теми же проверками баланса, лимитов и порога одобрения, что и у обычного перевода
+func (u *UseCase) RunScheduledTransfers(ctx context.Context) (executed, failed int, err error) {
+	for {
+		now := time.Now().UTC()
+		// База хранит время с точностью до микросекунды, а по аренде итог сверяется с ней
+		leaseUntil := now.Add(u.cfg.ScheduleLease).Truncate(time.Microsecond)
+
+		transfers, err := u.repo.ClaimDueTransfers(ctx, now, leaseUntil, scheduleBatchSize)
+		if err != nil {
+			return executed, failed, fmt.Errorf("repo.ClaimDueTransfers: %w", err)
+		}
+
+		for _, transfer := range transfers {
+			run, sendErr := u.runScheduledTransfer(ctx, transfer, leaseUntil)
+
+			// Сервис останавливается: срок повторится, когда истечёт аренда
+			if ctx.Err() != nil {
+				return executed, failed, ctx.Err()
+			}
+
+			if err = u.repo.CompleteScheduledRun(ctx, run); err != nil {
+				return executed, failed, fmt.Errorf("repo.CompleteScheduledRun: %w", err)
+			}
+
+			if sendErr != nil {
+				failed++
+			} else {
+				executed++
+			}
+		}
+
+		if len(transfers) < scheduleBatchSize {
+			return executed, failed, nil
+		}
+	}
+}
+
+func (u *UseCase) runScheduledTransfer(ctx context.Context, transfer domain.ScheduledTransfer, leaseUntil time.Time) (domain.ScheduledRun, error) {
+	now := time.Now().UTC()
+
+	// Ключ привязан к сроку по расписанию, а не к попытке
+	ctx = idempotency.WithKey(ctx, domain.IdempotencyKey{
+		Key:         fmt.Sprintf("scheduled-transfer:%d:%d", transfer.ID, transfer.NextRunAt.Unix()),
+		UserID:      transfer.OwnerID,
+		RequestHash: "scheduled-transfer",
+		ExpiresAt:   now.Add(scheduleKeyTTL),
+	})
+
+	_, err := u.SendCoin(ctx, transfer.OwnerID, domain.SendCoinRequest{
+		ToUser: transfer.ToUser,
+		Amount: transfer.Amount,
+		Memo:   transfer.Memo,
+	})
+	// Ключ уже занят — этот срок выполнен предыдущей попыткой
+	if errors.Is(err, ErrIdempotencyConflict) {
+		err = nil
+	}
+
+	run := domain.ScheduledRun{
+		ID:         transfer.ID,
+		LeaseUntil: leaseUntil,
+		RanAt:      now,
+		NextRunAt:  transfer.NextRunAt,
+		Active:     true,
+	}
+
+	if err != nil {
+		run.Failures = transfer.Failures + 1
+		run.LastError = err.Error()
+
+		if run.Failures < u.cfg.ScheduleMaxAttempts {
+			run.RetryAt = now.Add(u.scheduleBackoff(run.Failures))
+			return run, err
+		}
+	}
+
+	next, ok := transfer.NextAfter(now)
+	if !ok {
+		run.Active = false
+		return run, err
+	}
+
+	// Попытки по этому сроку исчерпаны или перевод выполнен — счёт неудач начинается заново
+	run.NextRunAt = next
+	run.Failures = 0
+
+	return run, err
+}
+
+// scheduleBackoff удваивает паузу после каждой неудачной попытки
+func (u *UseCase) scheduleBackoff(failures int) time.Duration {
+	backoff := u.cfg.ScheduleRetryBackoff
+	for i := 1; i < failures && backoff < maxScheduleBackoff; i++ {
+		backoff *= 2
+	}
+
+	return min(backoff, maxScheduleBackoff)
+}
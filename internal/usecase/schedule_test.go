@@ -0,0 +1,188 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/idempotency"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUseCase_RunScheduledTransfers(t *testing.T) {
+	t.Parallel()
+
+	owner := domain.User{ID: 1, Coins: 100, Credentials: domain.Credentials{Username: "lead"}}
+	intern := domain.User{ID: 2, Credentials: domain.Credentials{Username: "intern"}}
+	due := time.Now().UTC().Add(-time.Minute).Truncate(time.Second)
+
+	for _, tt := range []struct {
+		name           string
+		transfer       domain.ScheduledTransfer
+		transferErr    error
+		expectedFailed int
+		checkRun       func(t *testing.T, run domain.ScheduledRun)
+	}{
+		{
+			name:     "Recurring transfer moves to the next week",
+			transfer: domain.ScheduledTransfer{ID: 5, Repeat: domain.RepeatWeekly, Failures: 2},
+			checkRun: func(t *testing.T, run domain.ScheduledRun) {
+				assert.True(t, run.Active)
+				assert.Equal(t, due.AddDate(0, 0, 7), run.NextRunAt)
+				assert.True(t, run.RetryAt.IsZero())
+				assert.Zero(t, run.Failures)
+				assert.Empty(t, run.LastError)
+			},
+		},
+		{
+			name:        "Already executed occurrence is not repeated",
+			transfer:    domain.ScheduledTransfer{ID: 5, Repeat: domain.RepeatOnce},
+			transferErr: ErrIdempotencyConflict,
+			checkRun: func(t *testing.T, run domain.ScheduledRun) {
+				assert.False(t, run.Active)
+				assert.Empty(t, run.LastError)
+			},
+		},
+		{
+			name:           "Failure is retried with backoff",
+			transfer:       domain.ScheduledTransfer{ID: 5, Repeat: domain.RepeatWeekly, Failures: 1},
+			transferErr:    ErrNoCoins,
+			expectedFailed: 1,
+			checkRun: func(t *testing.T, run domain.ScheduledRun) {
+				assert.True(t, run.Active)
+				assert.Equal(t, due, run.NextRunAt)
+				assert.Equal(t, 2, run.Failures)
+				assert.Equal(t, 20*time.Minute, run.RetryAt.Sub(run.RanAt))
+				assert.Contains(t, run.LastError, ErrNoCoins.Error())
+			},
+		},
+		{
+			name:           "Exhausted recurring transfer skips the occurrence",
+			transfer:       domain.ScheduledTransfer{ID: 5, Repeat: domain.RepeatWeekly, Failures: 2},
+			transferErr:    ErrNoCoins,
+			expectedFailed: 1,
+			checkRun: func(t *testing.T, run domain.ScheduledRun) {
+				assert.True(t, run.Active)
+				assert.Equal(t, due.AddDate(0, 0, 7), run.NextRunAt)
+				assert.True(t, run.RetryAt.IsZero())
+				assert.Zero(t, run.Failures)
+				assert.NotEmpty(t, run.LastError)
+			},
+		},
+		{
+			name:           "Exhausted one-off transfer is deactivated",
+			transfer:       domain.ScheduledTransfer{ID: 5, Repeat: domain.RepeatOnce, Failures: 2},
+			transferErr:    ErrNoCoins,
+			expectedFailed: 1,
+			checkRun: func(t *testing.T, run domain.ScheduledRun) {
+				assert.False(t, run.Active)
+				assert.Equal(t, 3, run.Failures)
+			},
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, Config{
+				ScheduleLease:        5 * time.Minute,
+				ScheduleRetryBackoff: 10 * time.Minute,
+				ScheduleMaxAttempts:  3,
+			})
+
+			transfer := tt.transfer
+			transfer.OwnerID = owner.ID
+			transfer.ToUserID = intern.ID
+			transfer.ToUser = intern.Username
+			transfer.Amount = 20
+			transfer.NextRunAt = due
+			transfer.Active = true
+
+			mockRepo.On("ClaimDueTransfers", ctx, mock.Anything, mock.Anything, scheduleBatchSize).
+				Return([]domain.ScheduledTransfer{transfer}, nil).Once()
+			mockRepo.On("GetUserByID", mock.Anything, owner.ID).Return(owner, nil).Once()
+			mockRepo.On("GetUserByUsername", mock.Anything, intern.Username).Return(intern, nil).Once()
+			mockRepo.On("TransferCoins", mock.MatchedBy(func(ctx context.Context) bool {
+				key, ok := idempotency.FromContext(ctx)
+				return ok && key.UserID == owner.ID && key.Key != ""
+			}), owner.ID, intern.ID, uint64(20), "", domain.TransferGuard{}).Return(tt.transferErr).Once()
+
+			var run domain.ScheduledRun
+			mockRepo.On("CompleteScheduledRun", ctx, mock.Anything).Run(func(args mock.Arguments) {
+				run = args.Get(1).(domain.ScheduledRun)
+			}).Return(nil).Once()
+
+			executed, failed, err := useCase.RunScheduledTransfers(ctx)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expectedFailed, failed)
+			assert.Equal(t, 1-tt.expectedFailed, executed)
+
+			assert.Equal(t, transfer.ID, run.ID)
+			tt.checkRun(t, run)
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUseCase_RunScheduledTransfersClaimError(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{ScheduleLease: 5 * time.Minute})
+
+	mockRepo.On("ClaimDueTransfers", ctx, mock.Anything, mock.Anything, scheduleBatchSize).
+		Return(nil, errors.New("connection refused")).Once()
+
+	_, _, err := useCase.RunScheduledTransfers(ctx)
+	assert.Error(t, err)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUseCase_CreateScheduledTransfer(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{})
+
+	startAt := time.Date(2025, 3, 3, 9, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	paused := false
+
+	mockRepo.On("GetUserByUsername", ctx, "intern").Return(domain.User{ID: 2, Credentials: domain.Credentials{Username: "intern"}}, nil).Once()
+	mockRepo.On("CreateScheduledTransfer", ctx, domain.ScheduledTransfer{
+		OwnerID:   1,
+		ToUserID:  2,
+		ToUser:    "intern",
+		Amount:    20,
+		Repeat:    domain.RepeatWeekly,
+		NextRunAt: time.Date(2025, 3, 3, 6, 0, 0, 0, time.UTC),
+		Active:    false,
+	}).Return(domain.ScheduledTransfer{ID: 5}, nil).Once()
+
+	transfer, err := useCase.CreateScheduledTransfer(ctx, 1, domain.ScheduledTransferRequest{
+		ToUser: "intern", Amount: 20, Repeat: domain.RepeatWeekly, StartAt: startAt, Active: &paused,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), transfer.ID)
+
+	mockRepo.On("GetUserByUsername", ctx, "lead").Return(domain.User{ID: 1}, nil).Once()
+
+	_, err = useCase.CreateScheduledTransfer(ctx, 1, domain.ScheduledTransferRequest{
+		ToUser: "lead", Amount: 20, Repeat: domain.RepeatOnce,
+	})
+	assert.ErrorIs(t, err, ErrSendCoin)
+
+	mockRepo.AssertExpectations(t)
+}
@@ -0,0 +1,84 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"merch-shop/internal/domain"
+)
+
+// Logout отзывает текущий access-токен и, если он передан, refresh-токен той же сессии
+func (u *UseCase) Logout(ctx context.Context, session domain.Session, refreshToken string) error {
+	if err := u.repo.RevokeAccessToken(ctx, session); err != nil {
+		return fmt.Errorf("repo.RevokeAccessToken: %w", err)
+	}
+	u.revokedTokens.Set(session.TokenID, true)
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := u.repo.GetRefreshToken(ctx, hashToken(refreshToken))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return fmt.Errorf("repo.GetRefreshToken: %w", err)
+	}
+
+	if stored.UserID != session.UserID {
+		return nil
+	}
+
+	if err = u.repo.RevokeRefreshTokenFamily(ctx, stored.FamilyID); err != nil {
+		return fmt.Errorf("repo.RevokeRefreshTokenFamily: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeUserSessions завершает все сессии пользователя: увеличивает поколение
+// токенов и отзывает все его refresh-токены
+func (u *UseCase) RevokeUserSessions(ctx context.Context, userID uint64) error {
+	generation, err := u.repo.RevokeUserSessions(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("repo.RevokeUserSessions: %w", err)
+	}
+	u.generations.Set(userID, generation)
+
+	return nil
+}
+
+// ValidateSession проверяет, не отозван ли access-токен. Результаты кешируются
+// на SessionCacheTTL, поэтому отзыв на других репликах вступает в силу с этой задержкой.
+func (u *UseCase) ValidateSession(ctx context.Context, session domain.Session) error {
+	generation, ok := u.generations.Get(session.UserID)
+	if !ok {
+		var err error
+		generation, err = u.repo.GetTokenGeneration(ctx, session.UserID)
+		if err != nil {
+			return fmt.Errorf("repo.GetTokenGeneration: %w", err)
+		}
+		u.generations.Set(session.UserID, generation)
+	}
+
+	if session.Generation < generation {
+		return ErrSessionRevoked
+	}
+
+	revoked, ok := u.revokedTokens.Get(session.TokenID)
+	if !ok {
+		var err error
+		revoked, err = u.repo.IsAccessTokenRevoked(ctx, session.TokenID)
+		if err != nil {
+			return fmt.Errorf("repo.IsAccessTokenRevoked: %w", err)
+		}
+		u.revokedTokens.Set(session.TokenID, revoked)
+	}
+
+	if revoked {
+		return ErrSessionRevoked
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"context"
+	"merch-shop/internal/domain"
+	"merch-shop/internal/usecase/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUseCase_ValidateSession(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, tt := range []struct {
+		name          string
+		session       domain.Session
+		generation    uint64
+		revoked       bool
+		expectRevoked bool
+		expectErr     error
+	}{
+		{
+			name:       "Active session",
+			session:    domain.Session{UserID: 1, TokenID: "jti", Generation: 2},
+			generation: 2,
+		},
+		{
+			name:       "Token from previous generation",
+			session:    domain.Session{UserID: 1, TokenID: "jti", Generation: 1},
+			generation: 2,
+			expectErr:  ErrSessionRevoked,
+		},
+		{
+			name:          "Token in denylist",
+			session:       domain.Session{UserID: 1, TokenID: "jti", Generation: 2},
+			generation:    2,
+			revoked:       true,
+			expectRevoked: true,
+			expectErr:     ErrSessionRevoked,
+		},
+	} {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := new(mocks.Repository)
+			useCase := New(nil, nil, mockRepo, nil, Config{SessionCacheTTL: time.Minute})
+
+			mockRepo.On("GetTokenGeneration", ctx, tt.session.UserID).Return(tt.generation, nil).Once()
+			if tt.session.Generation >= tt.generation {
+				mockRepo.On("IsAccessTokenRevoked", ctx, tt.session.TokenID).Return(tt.revoked, nil).Once()
+			}
+
+			// Повторная проверка должна обслуживаться из кеша без обращения к репозиторию
+			for i := 0; i < 2; i++ {
+				err := useCase.ValidateSession(ctx, tt.session)
+				if tt.expectErr != nil {
+					assert.ErrorIs(t, err, tt.expectErr)
+				} else {
+					assert.NoError(t, err)
+				}
+			}
+
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUseCase_Logout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	session := domain.Session{UserID: 1, TokenID: "jti", ExpiresAt: time.Now().Add(time.Minute)}
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{SessionCacheTTL: time.Minute})
+
+	mockRepo.On("RevokeAccessToken", ctx, session).Return(nil).Once()
+	mockRepo.On("GetRefreshToken", ctx, hashToken("refresh")).
+		Return(domain.RefreshToken{ID: 5, UserID: 1, FamilyID: "family"}, nil).Once()
+	mockRepo.On("RevokeRefreshTokenFamily", ctx, "family").Return(nil).Once()
+
+	assert.NoError(t, useCase.Logout(ctx, session, "refresh"))
+
+	// Отозванный токен отклоняется сразу, без ожидания истечения кеша
+	mockRepo.On("GetTokenGeneration", ctx, session.UserID).Return(uint64(0), nil).Once()
+	assert.ErrorIs(t, useCase.ValidateSession(ctx, session), ErrSessionRevoked)
+
+	mockRepo.AssertExpectations(t)
+}
+
+func TestUseCase_RevokeUserSessions(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	session := domain.Session{UserID: 1, TokenID: "jti", Generation: 0}
+
+	mockRepo := new(mocks.Repository)
+	useCase := New(nil, nil, mockRepo, nil, Config{SessionCacheTTL: time.Minute})
+
+	mockRepo.On("RevokeUserSessions", ctx, session.UserID).Return(uint64(1), nil).Once()
+
+	assert.NoError(t, useCase.RevokeUserSessions(ctx, session.UserID))
+	assert.ErrorIs(t, useCase.ValidateSession(ctx, session), ErrSessionRevoked)
+
+	mockRepo.AssertExpectations(t)
+}
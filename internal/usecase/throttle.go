@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"merch-shop/internal/domain"
+	"time"
+)
+
+const (
+	failedLoginInvalidPassword = "invalid_password"
+	failedLoginLocked          = "locked"
+)
+
+func userLoginKey(username string) string {
+	return "user:" + username
+}
+
+func ipLoginKey(clientIP string) string {
+	return "ip:" + clientIP
+}
+
+func loginKeys(username, clientIP string) []string {
+	return []string{userLoginKey(username), ipLoginKey(clientIP)}
+}
+
+// checkLockout возвращает LockedError, если заблокирован пользователь или адрес
+func (u *UseCase) checkLockout(ctx context.Context, username, clientIP string) error {
+	now := time.Now()
+
+	var lockedUntil time.Time
+	for _, key := range loginKeys(username, clientIP) {
+		until, err := u.attempts.LockedUntil(ctx, key)
+		if err != nil {
+			return fmt.Errorf("attempts.LockedUntil: %w", err)
+		}
+
+		if until.After(lockedUntil) {
+			lockedUntil = until
+		}
+	}
+
+	if !lockedUntil.After(now) {
+		return nil
+	}
+
+	u.auditFailedLogin(ctx, username, clientIP, failedLoginLocked)
+
+	return &LockedError{RetryAfter: lockedUntil.Sub(now)}
+}
+
+// registerFailedLogin увеличивает счётчики и после LoginMaxFailures неудач
+// блокирует вход с экспоненциально растущей задержкой
+func (u *UseCase) registerFailedLogin(ctx context.Context, username, clientIP string) error {
+	u.auditFailedLogin(ctx, username, clientIP, failedLoginInvalidPassword)
+
+	for _, key := range loginKeys(username, clientIP) {
+		failures, err := u.attempts.RegisterFailure(ctx, key, u.cfg.LoginFailureWindow)
+		if err != nil {
+			return fmt.Errorf("attempts.RegisterFailure: %w", err)
+		}
+
+		if failures < u.cfg.LoginMaxFailures {
+			continue
+		}
+
+		if err = u.attempts.Lock(ctx, key, time.Now().Add(u.lockoutDuration(failures))); err != nil {
+			return fmt.Errorf("attempts.Lock: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (u *UseCase) lockoutDuration(failures int) time.Duration {
+	lockout := u.cfg.LoginLockoutBase
+	for i := u.cfg.LoginMaxFailures; i < failures && lockout < u.cfg.LoginLockoutMax; i++ {
+		lockout *= 2
+	}
+
+	if lockout > u.cfg.LoginLockoutMax {
+		lockout = u.cfg.LoginLockoutMax
+	}
+
+	return lockout
+}
+
+func (u *UseCase) auditFailedLogin(ctx context.Context, username, clientIP, reason string) {
+	err := u.repo.SaveFailedLogin(ctx, domain.FailedLogin{
+		Username: username,
+		IP:       clientIP,
+		Reason:   reason,
+	})
+	if err != nil {
+		slog.Error("repo.SaveFailedLogin", "error", err)
+	}
+}
@@ -2,34 +2,159 @@ package usecase
 
 import (
 	"context"
+	"merch-shop/internal/cache"
 	"merch-shop/internal/domain"
+	"time"
 )
 
 type UseCase struct {
-	auth Auth
-	repo Repository
+	auth     Auth
+	hasher   PasswordHasher
+	repo     Repository
+	attempts LoginAttempts
+	cfg      Config
+
+	transferPolicies TransferPolicies
+
+	revokedTokens *cache.Cache[string, bool]
+	generations   *cache.Cache[uint64, uint64]
+}
+
+type Config struct {
+	RefreshTokenTTL time.Duration
+	SessionCacheTTL time.Duration
+
+	LoginMaxFailures   int
+	LoginFailureWindow time.Duration
+	LoginLockoutBase   time.Duration
+	LoginLockoutMax    time.Duration
+
+	AutoRegistration bool
+	InviteRequired   bool
+	InviteTTL        time.Duration
+	StartingBalance  uint64
+
+	// ReturnWindow действует для товаров, у которых не задан собственный срок возврата
+	ReturnWindow time.Duration
+
+	// ReconcileAnomalies включает запись найденных сверкой расхождений в таблицу anomalies
+	ReconcileAnomalies bool
+
+	TransferLimits TransferLimits
+
+	// Переводы больше ApprovalThreshold ждут одобрения не дольше ApprovalTTL; 0 — без одобрения
+	ApprovalThreshold uint64
+	ApprovalTTL       time.Duration
+
+	// CoinRequestTTL — сколько запрос монет ждёт ответа плательщика
+	CoinRequestTTL time.Duration
+
+	// Планировщик занимает срочный перевод на ScheduleLease. Неудачный перевод повторяется
+	// с удвоением паузы от ScheduleRetryBackoff; после ScheduleMaxAttempts попыток срок пропускается.
+	ScheduleLease        time.Duration
+	ScheduleRetryBackoff time.Duration
+	ScheduleMaxAttempts  int
+
+	Economy EconomyPolicy
+}
+
+// EconomyPolicy — ежемесячное пособие и сгорание начисленных монет; нулевые значения отключают их
+type EconomyPolicy struct {
+	// AllowanceAmount начисляется раз в месяц пользователям, входившим в систему за AllowanceActiveWithin
+	AllowanceAmount       uint64
+	AllowanceActiveWithin time.Duration
+	// ExpiryMonths — через сколько месяцев сгорает неизрасходованный остаток начисленных монет
+	ExpiryMonths int
 }
 
 //go:generate mockery --name=Auth --output=./mocks --filename=auth.go --structname=Auth
 type Auth interface {
-	NewAccessToken(userID uint64) (string, error)
+	NewAccessToken(user domain.User) (string, error)
+	NewRefreshToken() (string, error)
+}
+
+//go:generate mockery --name=PasswordHasher --output=./mocks --filename=password_hasher.go --structname=PasswordHasher
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password, encoded string) (ok bool, needsRehash bool, err error)
+}
+
+//go:generate mockery --name=LoginAttempts --output=./mocks --filename=login_attempts.go --structname=LoginAttempts
+type LoginAttempts interface {
+	LockedUntil(ctx context.Context, key string) (time.Time, error)
+	RegisterFailure(ctx context.Context, key string, window time.Duration) (int, error)
+	Lock(ctx context.Context, key string, until time.Time) error
+	Reset(ctx context.Context, key string) error
 }
 
 //go:generate mockery --name=Repository --output=./mocks --filename=repository.go --structname=Repository
 type Repository interface {
-	CreateUser(ctx context.Context, creds domain.Credentials) (uint64, error)
+	CreateUser(ctx context.Context, creds domain.Credentials, coins uint64, inviteCode string) (uint64, error)
+	CreateInvite(ctx context.Context, invite domain.Invite) error
 	GetUserByUsername(ctx context.Context, username string) (domain.User, error)
+	UpdatePassword(ctx context.Context, userID uint64, passwordHash string) error
 	GetUserByID(ctx context.Context, userID uint64) (domain.User, error)
+	SetUserRole(ctx context.Context, userID uint64, role domain.Role) error
+	SetUserManager(ctx context.Context, userID, managerID uint64) error
 	GetUserInventory(ctx context.Context, userID uint64) ([]domain.Inventory, error)
-	GetUserTransactions(ctx context.Context, userID uint64) (domain.CoinHistory, error)
-	TransferCoins(ctx context.Context, fromUserID, toUserID uint64, amount uint64) error
+	GetUserTransactions(ctx context.Context, userID uint64, limit int) (domain.CoinHistory, error)
+	ListTransactions(ctx context.Context, userID uint64, filter domain.HistoryFilter) ([]domain.HistoryEntry, error)
+	TransferCoins(ctx context.Context, fromUserID, toUserID uint64, amount uint64, memo string, guard domain.TransferGuard) error
+	BatchTransferCoins(ctx context.Context, fromUserID uint64, lines []domain.SendCoinRequest, guard domain.TransferGuard) error
+	CreatePendingTransfer(ctx context.Context, transfer domain.PendingTransfer, guard domain.TransferGuard) (domain.PendingTransfer, error)
+	ListPendingApprovals(ctx context.Context, approverID uint64, role domain.Role) ([]domain.PendingTransfer, error)
+	DecidePendingTransfer(ctx context.Context, decision domain.TransferDecision) (domain.PendingTransfer, error)
+	ExpirePendingTransfers(ctx context.Context, now time.Time, limit int) (int, error)
+	CreateCoinRequest(ctx context.Context, request domain.CoinRequest) (domain.CoinRequest, error)
+	ListCoinRequests(ctx context.Context, userID uint64, filter domain.CoinRequestFilter) ([]domain.CoinRequest, error)
+	GetCoinRequest(ctx context.Context, requestID uint64) (domain.CoinRequest, error)
+	AcceptCoinRequest(ctx context.Context, requestID, payerID uint64, guard domain.TransferGuard) (domain.CoinRequest, error)
+	DeclineCoinRequest(ctx context.Context, requestID, payerID uint64) (domain.CoinRequest, error)
+	CreateScheduledTransfer(ctx context.Context, transfer domain.ScheduledTransfer) (domain.ScheduledTransfer, error)
+	ListScheduledTransfers(ctx context.Context, ownerID uint64) ([]domain.ScheduledTransfer, error)
+	UpdateScheduledTransfer(ctx context.Context, transfer domain.ScheduledTransfer) (domain.ScheduledTransfer, error)
+	DeleteScheduledTransfer(ctx context.Context, ownerID, transferID uint64) error
+	ClaimDueTransfers(ctx context.Context, now, leaseUntil time.Time, limit int) ([]domain.ScheduledTransfer, error)
+	CompleteScheduledRun(ctx context.Context, run domain.ScheduledRun) error
+	GrantCoins(ctx context.Context, lines []domain.GrantLine, reason string, dryRun bool) error
+	ListCoinLots(ctx context.Context, userID uint64) ([]domain.CoinLot, error)
+	CreditAllowances(ctx context.Context, period, activeSince time.Time, amount uint64, limit int) (int, error)
+	ExpireCoinLots(ctx context.Context, cutoff time.Time, limit int) (int, uint64, error)
 	BuyMerch(ctx context.Context, userID uint64, itemName string, itemPrice uint64) error
 	GetMerchPrice(ctx context.Context, itemName string) (uint64, error)
+	CreateOrder(ctx context.Context, userID uint64, lines []domain.OrderLine) (domain.Order, error)
+	ListOrders(ctx context.Context, userID uint64, filter domain.OrderFilter) ([]domain.Order, error)
+	ReturnOrder(ctx context.Context, userID, orderID uint64, items []string, defaultWindow time.Duration) (domain.Refund, error)
+	CreateMerch(ctx context.Context, item domain.Merch) (uint64, error)
+	UpdateMerch(ctx context.Context, name string, req domain.UpdateMerchRequest) error
+	RetireMerch(ctx context.Context, name string) error
+	RestockMerch(ctx context.Context, name string, quantity uint64) (*uint64, error)
+	SetMerchStock(ctx context.Context, name string, stock *uint64) error
+	ListMerch(ctx context.Context, filter domain.CatalogFilter) ([]domain.CatalogItem, error)
+	CreateRefreshToken(ctx context.Context, token domain.RefreshToken) error
+	GetRefreshToken(ctx context.Context, tokenHash string) (domain.RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, oldTokenID uint64, next domain.RefreshToken) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeAccessToken(ctx context.Context, session domain.Session) error
+	IsAccessTokenRevoked(ctx context.Context, tokenID string) (bool, error)
+	GetTokenGeneration(ctx context.Context, userID uint64) (uint64, error)
+	RevokeUserSessions(ctx context.Context, userID uint64) (uint64, error)
+	SaveFailedLogin(ctx context.Context, attempt domain.FailedLogin) error
+	ReconcileBalances(ctx context.Context) (domain.ReconciliationReport, error)
+	OpenAnomaly(ctx context.Context, anomaly domain.Anomaly) (bool, error)
 }
 
-func New(auth Auth, repo Repository) *UseCase {
+func New(auth Auth, hasher PasswordHasher, repo Repository, attempts LoginAttempts, cfg Config) *UseCase {
 	return &UseCase{
-		auth: auth,
-		repo: repo,
+		auth:     auth,
+		hasher:   hasher,
+		repo:     repo,
+		attempts: attempts,
+		cfg:      cfg,
+
+		transferPolicies: NewTransferPolicies(cfg.TransferLimits),
+
+		revokedTokens: cache.New[string, bool](cfg.SessionCacheTTL),
+		generations:   cache.New[uint64, uint64](cfg.SessionCacheTTL),
 	}
 }